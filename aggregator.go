@@ -0,0 +1,163 @@
+package raygun4go
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// aggregate holds the occurrences of one error collected by an Aggregator.
+type aggregate struct {
+	err   error      // the first occurrence
+	stack StackTrace // the stack of the first occurrence
+	first time.Time  // the time of the first occurrence
+	last  time.Time  // the time of the last occurrence
+	count int        // the number of occurrences
+}
+
+// Aggregator collects occurrences of errors and reports each error once per
+// interval, summarizing its occurrences with WithOccurrenceWindow. Errors are
+// identified by their type and message. An Aggregator is safe for concurrent
+// use. Call Close when done with it.
+type Aggregator struct {
+	client   *Client
+	interval time.Duration
+
+	mu         sync.Mutex
+	aggregates map[string]*aggregate
+
+	stop      chan struct{} // closed by Close to stop the background reporting
+	stopped   chan struct{} // closed once the background reporting stopped
+	closeOnce sync.Once
+}
+
+// NewAggregator returns an Aggregator reporting with the given client once
+// per interval. In the background, it reports the errors whose interval is
+// over even if they do not occur again, until Close is called.
+func NewAggregator(c *Client, interval time.Duration) *Aggregator {
+	return newAggregator(c, interval, interval)
+}
+
+// newAggregator returns an Aggregator checking for errors whose interval is
+// over every tick, replaced in tests.
+func newAggregator(c *Client, interval, tick time.Duration) *Aggregator {
+	a := &Aggregator{
+		client:     c,
+		interval:   interval,
+		aggregates: make(map[string]*aggregate),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	if c == nil || tick <= 0 {
+		close(a.stopped)
+		return a
+	}
+	go a.run(tick)
+	return a
+}
+
+// run reports the errors whose interval is over every tick until Close is
+// called.
+func (a *Aggregator) run(tick time.Duration) {
+	defer close(a.stopped)
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.reportExpired()
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// reportExpired reports the collected occurrences of all errors whose first
+// recorded occurrence is at least one interval ago.
+func (a *Aggregator) reportExpired() {
+	now := a.client.clock()
+	var expired []*aggregate
+	a.mu.Lock()
+	for fingerprint, agg := range a.aggregates {
+		if now.Sub(agg.first) >= a.interval {
+			expired = append(expired, agg)
+			delete(a.aggregates, fingerprint)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, agg := range expired {
+		if err := a.report(agg); err != nil {
+			a.client.errorf("Unable to report %d aggregated occurrences of %s (%s)", agg.count, agg.err.Error(), err.Error())
+		}
+	}
+}
+
+// Add records an occurrence of the given error. If the first recorded
+// occurrence of the error is at least one interval ago, the collected
+// occurrences are reported and a new interval starts with this one.
+func (a *Aggregator) Add(err error) error {
+	if a.client == nil {
+		return ErrNoClient
+	}
+	if err == nil {
+		return nil
+	}
+	now := a.client.clock()
+	fingerprint := fmt.Sprintf("%T: %s", err, err.Error())
+
+	a.mu.Lock()
+	agg, ok := a.aggregates[fingerprint]
+	if ok && now.Sub(agg.first) < a.interval {
+		agg.last = now
+		agg.count++
+		a.mu.Unlock()
+		return nil
+	}
+	a.aggregates[fingerprint] = &aggregate{err: err, stack: currentStack(), first: now, last: now, count: 1}
+	a.mu.Unlock()
+
+	if ok {
+		return a.report(agg)
+	}
+	return nil
+}
+
+// Flush reports the collected occurrences of all errors, regardless of their
+// interval. It returns the first error that occurred while reporting.
+func (a *Aggregator) Flush() error {
+	if a.client == nil {
+		return ErrNoClient
+	}
+
+	a.mu.Lock()
+	aggregates := a.aggregates
+	a.aggregates = make(map[string]*aggregate)
+	a.mu.Unlock()
+
+	var firstErr error
+	for _, agg := range aggregates {
+		if err := a.report(agg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops the background reporting and reports the collected occurrences
+// of all errors like Flush. It returns the first error that occurred while
+// reporting.
+func (a *Aggregator) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.stop)
+	})
+	<-a.stopped
+	return a.Flush()
+}
+
+// report sends a single report summarizing the given aggregate.
+func (a *Aggregator) report(agg *aggregate) error {
+	o := newReportOptions([]ReportOption{WithOccurrenceWindow(agg.first, agg.last, agg.count)})
+	post := a.client.createPostWithOptions(agg.err, agg.stack, o)
+	return a.client.Submit(post)
+}
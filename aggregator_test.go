@@ -0,0 +1,146 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregator(t *testing.T) {
+	Convey("Aggregator", t, func() {
+		server := newRecordingServer()
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return now }
+		a := NewAggregator(c, time.Minute)
+		Reset(func() {
+			a.Close()
+			server.Close()
+		})
+
+		window := func(post PostData) map[string]interface{} {
+			data := post.Details.UserCustomData.(map[string]interface{})
+			return data[OccurrenceWindowKey].(map[string]interface{})
+		}
+
+		Convey("reports once per interval", func() {
+			for i := 0; i < 18; i++ {
+				now = start.Add(time.Duration(i) * 10 * time.Second)
+				So(a.Add(errors.New("Test aggregated error")), ShouldBeNil)
+			}
+			So(a.Add(nil), ShouldBeNil)
+
+			first, _ := server.next()
+			So(first.Details.Error.Message, ShouldEqual, "Test aggregated error")
+			So(first.OccuredOn, ShouldEqual, "2024-01-01T00:00:50Z")
+			So(window(first)["firstOccurredOn"], ShouldEqual, "2024-01-01T00:00:00Z")
+			So(window(first)["lastOccurredOn"], ShouldEqual, "2024-01-01T00:00:50Z")
+			So(window(first)["count"], ShouldEqual, 6)
+
+			second, _ := server.next()
+			So(window(second)["firstOccurredOn"], ShouldEqual, "2024-01-01T00:01:00Z")
+			So(window(second)["count"], ShouldEqual, 6)
+			So(len(server.posts), ShouldEqual, 0)
+
+			Convey("and reports the rest on Flush", func() {
+				So(a.Flush(), ShouldBeNil)
+				third, _ := server.next()
+				So(window(third)["firstOccurredOn"], ShouldEqual, "2024-01-01T00:02:00Z")
+				So(window(third)["lastOccurredOn"], ShouldEqual, "2024-01-01T00:02:50Z")
+				So(window(third)["count"], ShouldEqual, 6)
+			})
+		})
+
+		Convey("aggregates distinct errors separately", func() {
+			a.Add(errors.New("Test first error"))
+			a.Add(errors.New("Test second error"))
+			a.Add(errors.New("Test first error"))
+			So(a.Flush(), ShouldBeNil)
+
+			counts := map[string]interface{}{}
+			for i := 0; i < 2; i++ {
+				post, _ := server.next()
+				counts[post.Details.Error.Message] = window(post)["count"]
+			}
+			So(counts["Test first error"], ShouldEqual, 2)
+			So(counts["Test second error"], ShouldEqual, 1)
+		})
+
+		Convey("reports the rest on Close", func() {
+			a.Add(errors.New("Test aggregated error"))
+			So(a.Close(), ShouldBeNil)
+			post, _ := server.next()
+			So(window(post)["count"], ShouldEqual, 1)
+			So(a.Close(), ShouldBeNil)
+		})
+	})
+
+	Convey("Aggregator in the background", t, func() {
+		server := newRecordingServer()
+
+		var mu sync.Mutex
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		setNow := func(t time.Time) {
+			mu.Lock()
+			defer mu.Unlock()
+			now = t
+		}
+		c, _ := New("app", "key")
+		c.clock = func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		a := newAggregator(c, time.Minute, time.Millisecond)
+		Reset(func() {
+			a.Close()
+			server.Close()
+		})
+
+		Convey("reports an error occurring in the first interval only once it is over", func() {
+			for i := 0; i < 500; i++ {
+				setNow(start.Add(time.Duration(i) * 100 * time.Millisecond))
+				So(a.Add(errors.New("Test aggregated error")), ShouldBeNil)
+			}
+			time.Sleep(20 * time.Millisecond)
+			So(len(server.posts), ShouldEqual, 0)
+
+			setNow(start.Add(time.Minute))
+			post, ok := server.next()
+			So(ok, ShouldBeTrue)
+			data := post.Details.UserCustomData.(map[string]interface{})
+			window := data[OccurrenceWindowKey].(map[string]interface{})
+			So(window["firstOccurredOn"], ShouldEqual, "2024-01-01T00:00:00Z")
+			So(window["lastOccurredOn"], ShouldEqual, "2024-01-01T00:00:49Z")
+			So(window["count"], ShouldEqual, 500)
+
+			So(a.Close(), ShouldBeNil)
+			So(len(server.posts), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestWithOccurrenceWindow(t *testing.T) {
+	Convey("#WithOccurrenceWindow", t, func() {
+		c, _ := New("app", "key")
+		first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		last := first.Add(10 * time.Minute)
+
+		o := newReportOptions([]ReportOption{WithOccurrenceWindow(first, last, 500)})
+		post := c.createPostWithOptions(errors.New("test error"), StackTrace{}, o)
+
+		So(post.OccuredOn, ShouldEqual, "2024-01-01T00:10:00Z")
+		data := post.Details.UserCustomData.(map[string]interface{})
+		So(data[OccurrenceWindowKey], ShouldResemble, OccurrenceWindow{
+			FirstOccurredOn: "2024-01-01T00:00:00Z",
+			LastOccurredOn:  "2024-01-01T00:10:00Z",
+			Count:           500,
+		})
+	})
+}
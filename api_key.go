@@ -0,0 +1,55 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+)
+
+// sharedAPIKey is the API key of a client. It is shared by a client and all
+// its clones, so SetAPIKey rotates the key of all of them.
+type sharedAPIKey struct {
+	mu  sync.RWMutex
+	key string
+}
+
+// newSharedAPIKey returns a holder of the given key.
+func newSharedAPIKey(key string) *sharedAPIKey {
+	return &sharedAPIKey{key: key}
+}
+
+// get returns the current key.
+func (k *sharedAPIKey) get() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.key
+}
+
+// set replaces the key and reports whether it changed.
+func (k *sharedAPIKey) set(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	changed := k.key != key
+	k.key = key
+	return changed
+}
+
+// SetAPIKey replaces the API key of the client and all its clones, e.g. after
+// rotating it. It is safe to call while reports are being submitted: reports
+// posted afterwards, including those already waiting in the asynchronous
+// queue or a batch, are sent with the new key. If the key changes, a client
+// disabled because Raygun rejected the old key is enabled again, see
+// Enabled. Keys chosen by the APIKeySelector or set with WithApplication are
+// not affected.
+func (c *Client) SetAPIKey(key string) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	c.refuseLiveMode("SetAPIKey")
+	if key == "" {
+		return errors.New("apiKey is required")
+	}
+	if c.apiKey.set(key) {
+		c.disabled.set(false)
+	}
+	return nil
+}
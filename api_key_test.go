@@ -0,0 +1,107 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetAPIKey(t *testing.T) {
+	Convey("SetAPIKey", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "old-key")
+		c.Endpoint(server.URL)
+		send := func(client *Client) {
+			So(client.SendError(errors.New("Test SetAPIKey")), ShouldBeNil)
+		}
+		keys := func() []string {
+			var keys []string
+			for _, r := range server.Requests() {
+				keys = append(keys, r.APIKey())
+			}
+			return keys
+		}
+
+		Convey("changes the key of subsequent posts", func() {
+			send(c)
+			So(c.SetAPIKey("new-key"), ShouldBeNil)
+			send(c)
+			So(keys(), ShouldResemble, []string{"old-key", "new-key"})
+		})
+
+		Convey("changes the key of all clones", func() {
+			clone := c.Clone()
+			So(c.SetAPIKey("new-key"), ShouldBeNil)
+			send(clone)
+			So(keys(), ShouldResemble, []string{"new-key"})
+		})
+
+		Convey("applies to queued reports without dropping any", func() {
+			started, proceed := make(chan struct{}), make(chan struct{})
+			var once sync.Once
+			server.OnRequest(func(rayguntest.Request) {
+				once.Do(func() {
+					close(started)
+					<-proceed
+				})
+			})
+			c.Asynchronous(true)
+			for i := 0; i < 3; i++ {
+				send(c)
+			}
+			<-started
+			So(c.SetAPIKey("new-key"), ShouldBeNil)
+			for i := 0; i < 2; i++ {
+				send(c)
+			}
+			close(proceed)
+
+			So(c.Close(), ShouldBeNil)
+			So(keys(), ShouldResemble, []string{"old-key", "new-key", "new-key", "new-key", "new-key"})
+		})
+
+		Convey("is safe while submitting", func() {
+			c.Asynchronous(true)
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					c.SetAPIKey("new-key")
+					c.SetAPIKey("old-key")
+				}
+			}()
+			for i := 0; i < 20; i++ {
+				So(c.SendError(errors.New("Test SetAPIKey")), ShouldBeNil)
+			}
+			wg.Wait()
+			So(c.SetAPIKey("new-key"), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 20)
+		})
+
+		Convey("enables a client disabled for a rejected key", func() {
+			server.Script(rayguntest.Unauthorized)
+			So(c.SendError(errors.New("Test SetAPIKey")), ShouldNotBeNil)
+			So(c.SendError(errors.New("Test SetAPIKey")), ShouldEqual, ErrClientDisabled)
+
+			So(c.SetAPIKey("old-key"), ShouldBeNil)
+			So(c.SendError(errors.New("Test SetAPIKey")), ShouldEqual, ErrClientDisabled)
+
+			So(c.SetAPIKey("new-key"), ShouldBeNil)
+			send(c)
+			So(keys(), ShouldResemble, []string{"old-key", "new-key"})
+		})
+
+		Convey("rejects an empty key", func() {
+			So(c.SetAPIKey(""), ShouldNotBeNil)
+			send(c)
+			So(keys(), ShouldResemble, []string{"old-key"})
+		})
+	})
+}
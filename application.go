@@ -0,0 +1,80 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownApplicationKey is matched by the error a report sent with
+// WithApplication fails with once resumed from disk if the resuming client
+// cannot resolve the API key of its application, see APIKeySelector. Such
+// reports are dropped rather than sent with the key of the client.
+var ErrUnknownApplicationKey = errors.New("API key of the application is unknown")
+
+// hostAppKey is the custom data key the name of the client's application is
+// stored under in reports sent with WithApplication.
+const hostAppKey = "hostApp"
+
+// application is a Raygun application reports are sent to instead of the
+// client's, see WithApplication.
+type application struct {
+	name   string
+	apiKey string
+}
+
+// WithApplication sends the report to the Raygun application with the given
+// name and API key instead of the client's, e.g. for a shared library
+// reporting its own errors on behalf of the programs linking it. The name of
+// the client's application, as passed to New, is stored in the custom data
+// under "hostApp". The key takes precedence over the one chosen by
+// APIKeySelector and is kept by reports waiting in the asynchronous queue, but
+// not written to disk: reports resumed from disk are sent with the key the
+// APIKeySelector of the resuming client returns for their application, or
+// dropped if it returns none. An empty API key leaves the report unchanged.
+func WithApplication(appName, apiKey string) ReportOption {
+	return func(o *reportOptions) {
+		if apiKey != "" {
+			o.application = &application{name: appName, apiKey: apiKey}
+		}
+	}
+}
+
+// APIKeySelector is a chainable option-setting method to set a function
+// choosing the API key each report is sent with, e.g. for a multi-tenant
+// service reporting the errors of each tenant to its own Raygun application.
+// If the function returns an empty string, the API key passed to New is
+// used. Reports sent with WithApplication are only passed to the function once
+// resumed from disk, which does not keep their key, so it can resolve the key
+// of their application, see PostData.Application; for them, an empty string
+// drops the report. Passing nil removes the function.
+func (c *Client) APIKeySelector(fn func(PostData) string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.apiKeySelector = fn
+	return c
+}
+
+// Application returns the name of the Raygun application the report is sent
+// to with WithApplication, or an empty string if it is sent to the client's.
+func (p PostData) Application() string {
+	return p.appName
+}
+
+// reportAPIKey returns the API key the given post is sent with. It fails for
+// reports of another application whose key was not kept, as sending them
+// with the key of the client would file them under the wrong application.
+func (c *Client) reportAPIKey(post PostData) (string, error) {
+	if post.apiKey != "" {
+		return post.apiKey, nil
+	}
+	if c.apiKeySelector != nil {
+		if key := c.apiKeySelector(post); key != "" {
+			return key, nil
+		}
+	}
+	if post.appName != "" {
+		return "", fmt.Errorf("%w %q, resolve it with APIKeySelector", ErrUnknownApplicationKey, post.appName)
+	}
+	return c.apiKey.get(), nil
+}
@@ -0,0 +1,119 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestApplication(t *testing.T) {
+	Convey("WithApplication", t, func() {
+		type received struct {
+			apiKey string
+			post   PostData
+		}
+		posts := make(chan received, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var post PostData
+			json.NewDecoder(r.Body).Decode(&post)
+			posts <- received{apiKey: r.Header.Get("X-ApiKey"), post: post}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		Reset(server.Close)
+
+		c, _ := New("host", "host-key")
+		c.Endpoint(server.URL)
+
+		Convey("sends the report with the key of the application", func() {
+			So(c.SendError(errors.New("Test Application")), ShouldBeNil)
+			So(c.SendError(errors.New("Test Application"), WithApplication("platform", "platform-key")), ShouldBeNil)
+
+			normal, overridden := <-posts, <-posts
+			So(normal.apiKey, ShouldEqual, "host-key")
+			So(normal.post.Details.UserCustomData, ShouldNotContainKey, hostAppKey)
+			So(overridden.apiKey, ShouldEqual, "platform-key")
+			So(overridden.post.Details.UserCustomData, ShouldContainKey, hostAppKey)
+			So(overridden.post.Details.UserCustomData.(map[string]interface{})[hostAppKey], ShouldEqual, "host")
+		})
+
+		Convey("keeps the application in the asynchronous queue", func() {
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("Test Application"), WithApplication("platform", "platform-key")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So((<-posts).apiKey, ShouldEqual, "platform-key")
+		})
+
+		Convey("keeps the application of persisted reports but not its key", func() {
+			post := c.createPostWithOptions(errors.New("Test Application"), StackTrace{}, newReportOptions([]ReportOption{WithApplication("platform", "platform-key")}))
+			enc, _ := encodeStoredReport(storedReport{Post: post})
+			So(string(enc), ShouldNotContainSubstring, "platform-key")
+			stored, err := decodeStoredReport(enc)
+			So(err, ShouldBeNil)
+			So(stored.Post.appName, ShouldEqual, "platform")
+			_, err = c.reportAPIKey(stored.Post)
+			So(errors.Is(err, ErrUnknownApplicationKey), ShouldBeTrue)
+
+			c.APIKeySelector(func(post PostData) string {
+				if post.Application() == "platform" {
+					return "resolved-platform-key"
+				}
+				return ""
+			})
+			key, err := c.reportAPIKey(stored.Post)
+			So(err, ShouldBeNil)
+			So(key, ShouldEqual, "resolved-platform-key")
+		})
+
+		Convey("resumes persisted reports with the resolved key of the application", func() {
+			dir := t.TempDir()
+			post := c.createPostWithOptions(errors.New("Test Application"), StackTrace{}, newReportOptions([]ReportOption{WithApplication("platform", "platform-key")}))
+			So(persistReports(dir, []queuedReport{{client: c, post: post}, {client: c, post: post}}), ShouldBeNil)
+
+			var failed []error
+			resuming, _ := New("host", "host-key")
+			resuming.Endpoint(server.URL).Asynchronous(true).OnSubmissionError(func(_ PostData, err error) {
+				failed = append(failed, err)
+			})
+			So(resuming.Resume(dir), ShouldBeNil)
+			So(resuming.Close(), ShouldBeNil)
+			So(failed, ShouldHaveLength, 2)
+			So(errors.Is(failed[0], ErrUnknownApplicationKey), ShouldBeTrue)
+			So(posts, ShouldBeEmpty)
+
+			So(persistReports(dir, []queuedReport{{client: c, post: post}}), ShouldBeNil)
+			resuming, _ = New("host", "host-key")
+			resuming.Endpoint(server.URL).Asynchronous(true).APIKeySelector(func(post PostData) string {
+				return map[string]string{"platform": "platform-key"}[post.Application()]
+			})
+			So(resuming.Resume(dir), ShouldBeNil)
+			So(resuming.Close(), ShouldBeNil)
+			So((<-posts).apiKey, ShouldEqual, "platform-key")
+		})
+
+		Convey("takes precedence over the APIKeySelector", func() {
+			c.APIKeySelector(func(post PostData) string {
+				if post.Details.Error.Message == "tenant" {
+					return "tenant-key"
+				}
+				return ""
+			})
+			So(c.SendError(errors.New("tenant")), ShouldBeNil)
+			So(c.SendError(errors.New("other")), ShouldBeNil)
+			So(c.SendError(errors.New("tenant"), WithApplication("platform", "platform-key")), ShouldBeNil)
+			So((<-posts).apiKey, ShouldEqual, "tenant-key")
+			So((<-posts).apiKey, ShouldEqual, "host-key")
+			So((<-posts).apiKey, ShouldEqual, "platform-key")
+		})
+
+		Convey("ignores an empty API key", func() {
+			So(c.SendError(errors.New("Test Application"), WithApplication("platform", "")), ShouldBeNil)
+			sent := <-posts
+			So(sent.apiKey, ShouldEqual, "host-key")
+			So(sent.post.Details.UserCustomData, ShouldNotContainKey, hostAppKey)
+		})
+	})
+}
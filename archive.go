@@ -0,0 +1,63 @@
+package raygun4go
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"math"
+)
+
+// Sink receives encoded report payloads, see ArchiveSink.
+type Sink interface {
+	Accept(payload []byte) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(payload []byte) error
+
+// Accept calls f(payload).
+func (f SinkFunc) Accept(payload []byte) error {
+	return f(payload)
+}
+
+// archive sends a sample of the submitted payloads to a sink.
+type archive struct {
+	sink     Sink
+	fraction float64
+}
+
+// ArchiveSink is a chainable option-setting method to pass a sample of the
+// exact payloads sent to Raygun to the given sink as well, e.g. for auditing.
+// The sample is the given fraction, between 0 and 1, of errors as identified
+// for Deduplicate: the selection is derived from a hash of the error, so all
+// reports of an error are either archived or not, as long as the fraction is
+// unchanged. Payloads are archived right before they are sent, whether or not
+// sending succeeds; errors of the sink are only logged. A nil sink disables
+// archiving.
+func (c *Client) ArchiveSink(s Sink, fraction float64) *Client {
+	if c == nil {
+		return nil
+	}
+	if s == nil {
+		c.archive = nil
+		return c
+	}
+	c.archive = &archive{sink: s, fraction: math.Max(0, math.Min(1, fraction))}
+	return c
+}
+
+// selects reports whether the given post belongs to the sample.
+func (a *archive) selects(post PostData) bool {
+	h := sha1.Sum([]byte(dedupFingerprint(post)))
+	return float64(binary.BigEndian.Uint64(h[:8])) < a.fraction*math.MaxUint64
+}
+
+// offerToArchive passes the given payload of the given post to the sink if the post
+// belongs to the sample.
+func (c *Client) offerToArchive(post PostData, payload []byte) {
+	if c.archive == nil || !c.archive.selects(post) {
+		return
+	}
+	if err := c.archive.sink.Accept(payload); err != nil {
+		c.errorf("Unable to archive payload: %s", err.Error())
+	}
+}
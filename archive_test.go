@@ -0,0 +1,88 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestArchiveSink(t *testing.T) {
+	Convey("#ArchiveSink", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		var archived [][]byte
+		sink := SinkFunc(func(payload []byte) error {
+			archived = append(archived, payload)
+			return nil
+		})
+		postFor := func(i int) PostData {
+			return c.createPost(fmt.Errorf("Test error %d", i), StackTrace{})
+		}
+
+		Convey("selects roughly the configured fraction of errors", func() {
+			c.ArchiveSink(sink, 0.1)
+			selected := 0
+			for i := 0; i < 10000; i++ {
+				if c.archive.selects(postFor(i)) {
+					selected++
+				}
+			}
+			So(selected, ShouldBeBetween, 900, 1100)
+		})
+
+		Convey("selects each error always or never", func() {
+			c.ArchiveSink(sink, 0.5)
+			for i := 0; i < 100; i++ {
+				first := c.archive.selects(postFor(i))
+				for j := 0; j < 3; j++ {
+					So(c.archive.selects(postFor(i)), ShouldEqual, first)
+				}
+			}
+		})
+
+		Convey("passes the exact payloads sent", func() {
+			c.ArchiveSink(sink, 1)
+			So(c.SendError(errors.New("Test ArchiveSink")), ShouldBeNil)
+			sent, _ := server.next()
+			So(len(archived), ShouldEqual, 1)
+
+			var post PostData
+			So(json.Unmarshal(archived[0], &post), ShouldBeNil)
+			So(post, ShouldResemble, sent)
+		})
+
+		Convey("archives the sampled errors only", func() {
+			c.ArchiveSink(sink, 0.3)
+			expected := 0
+			for i := 0; i < 50; i++ {
+				post := postFor(i)
+				if c.archive.selects(post) {
+					expected++
+				}
+				So(c.Submit(post), ShouldBeNil)
+				server.next()
+			}
+			So(len(archived), ShouldEqual, expected)
+			So(expected, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("keeps sending if the sink fails", func() {
+			c.ArchiveSink(SinkFunc(func([]byte) error { return errors.New("Test sink") }), 1)
+			So(c.SendError(errors.New("Test ArchiveSink")), ShouldBeNil)
+			_, ok := server.next()
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("is disabled by a nil sink", func() {
+			c.ArchiveSink(sink, 1).ArchiveSink(nil, 1)
+			c.SendError(errors.New("Test ArchiveSink"))
+			server.next()
+			So(archived, ShouldBeEmpty)
+		})
+	})
+}
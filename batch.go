@@ -0,0 +1,229 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// reportBatch collects the reports submitted in batching mode and delivers
+// them together from a background loop. It is shared by a client and all
+// clones made after Batch was set.
+type reportBatch struct {
+	maxSize  int
+	interval time.Duration
+
+	mu       sync.Mutex
+	reports  []queuedReport
+	closed   bool
+	inFlight int // the reports taken by the running flush and not finished yet
+
+	start   sync.Once
+	full    chan struct{} // signals the loop that maxSize reports are waiting
+	stop    chan struct{} // closed to stop the loop
+	stopped chan struct{} // closed once the loop has stopped
+	sending sync.Mutex    // serializes deliveries
+}
+
+// newReportBatch returns an empty batch. Its loop is started with the first
+// report.
+func newReportBatch(maxSize int, interval time.Duration) *reportBatch {
+	if maxSize > defaultQueueSize {
+		maxSize = defaultQueueSize
+	}
+	return &reportBatch{
+		maxSize:  maxSize,
+		interval: interval,
+		full:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Batch is a chainable option-setting method to buffer reports and deliver
+// them every flushInterval, or once maxSize are buffered, see Flush and Close.
+// A maxSize or flushInterval of 0 or less disables batching, its default.
+func (c *Client) Batch(maxSize int, flushInterval time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	var undelivered []queuedReport
+	if c.batch != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.closeTimeout)
+		undelivered = c.batch.close(ctx)
+		cancel()
+	}
+	c.batch = nil
+	if maxSize > 0 && flushInterval > 0 {
+		c.batch = newReportBatch(maxSize, flushInterval)
+	}
+
+	// Reports the old batch could not deliver in time move to the new one.
+	for i, r := range undelivered {
+		err := ErrClientClosed // batching is disabled, like after Close
+		if c.batch != nil {
+			err = c.batch.add(r)
+		}
+		if err != nil {
+			dropReports(err, undelivered[i:]...)
+			break
+		}
+	}
+	return c
+}
+
+// add buffers the given report and signals the loop once the buffer is full.
+func (b *reportBatch) add(r queuedReport) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClientClosed
+	}
+	if len(b.reports) >= defaultQueueSize {
+		return ErrQueueFull
+	}
+
+	b.start.Do(func() { go b.run() })
+
+	b.reports = append(b.reports, r)
+	if len(b.reports) >= b.maxSize {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run flushes the batch every interval and whenever it is full, until the
+// batch is closed.
+func (b *reportBatch) run() {
+	defer close(b.stopped)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.full:
+		case <-b.stop:
+			return
+		}
+		b.flush(context.Background())
+	}
+}
+
+// flush delivers the buffered reports in order until ctx is done. Reports
+// rejected because Raygun is rate limiting the client are put back with the
+// ones not attempted yet. It returns the reports not delivered because ctx
+// was done and an error wrapping the first failed delivery, if any.
+func (b *reportBatch) flush(ctx context.Context) ([]queuedReport, error) {
+	b.sending.Lock()
+	defer b.sending.Unlock()
+
+	b.mu.Lock()
+	reports := b.reports
+	b.reports = nil
+	b.inFlight = len(reports)
+	b.mu.Unlock()
+
+	var first error
+	failed := 0
+	for i, r := range reports {
+		if ctx.Err() != nil {
+			b.settle(len(reports) - i)
+			return reports[i:], batchError(failed, first)
+		}
+		r.attempts++
+		err := r.client.submitTimed(ctx, r.post)
+		if err == nil {
+			r.client.recordResult(r.post, nil)
+			r.result.resolve(nil)
+			b.settle(1)
+			continue
+		}
+		var limited *RateLimitError
+		if errors.As(err, &limited) && !r.client.spilled(err) {
+			b.mu.Lock()
+			b.requeueLocked(reports[i:])
+			b.inFlight -= len(reports) - i
+			b.mu.Unlock()
+			if first == nil {
+				first = err
+			}
+			return nil, batchError(failed+len(reports)-i, first)
+		}
+		r.client.recordResult(r.post, err)
+		r.result.resolve(err)
+		b.settle(1)
+		if err != ErrClientDisabled {
+			r.client.errorf("Unable to send %s\n%s", r.post.Summary(), err.Error())
+		}
+		failed++
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, batchError(failed, first)
+}
+
+// requeue puts the given reports back in front of the buffered ones.
+func (b *reportBatch) requeue(reports []queuedReport) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requeueLocked(reports)
+}
+
+// requeueLocked implements requeue. The caller holds b.mu.
+func (b *reportBatch) requeueLocked(reports []queuedReport) {
+	b.reports = append(append([]queuedReport(nil), reports...), b.reports...)
+}
+
+// settle marks the given number of reports taken by flush as finished.
+func (b *reportBatch) settle(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight -= n
+}
+
+// counts returns the numbers of buffered reports and of reports being
+// delivered.
+func (b *reportBatch) counts() (pending, inFlight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.reports), b.inFlight
+}
+
+// batchError returns the error flush returns for the given number of
+// undelivered reports, or nil if there are none.
+func batchError(failed int, first error) error {
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("Unable to deliver %d batched reports (%w)", failed, first)
+}
+
+// close stops accepting reports and the loop, delivers the buffered reports
+// until ctx is done and returns the ones that were not attempted, including
+// those kept because Raygun is rate limiting the client.
+func (b *reportBatch) close(ctx context.Context) []queuedReport {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	b.start.Do(func() { close(b.stopped) })
+	<-b.stopped
+
+	undelivered, _ := b.flush(ctx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	undelivered = append(undelivered, b.reports...)
+	b.reports = nil
+	return undelivered
+}
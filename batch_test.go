@@ -0,0 +1,166 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBatch(t *testing.T) {
+	Convey("Batch", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		Reset(func() { c.Close() })
+		send := func(n int) {
+			for i := 0; i < n; i++ {
+				So(c.SendError(errors.New("Test Batch")), ShouldBeNil)
+			}
+		}
+
+		Convey("flushes once the buffer is full", func() {
+			c.Batch(3, time.Hour)
+			send(2)
+			_, ok := server.WaitForRequests(1, 50*time.Millisecond)
+			So(ok, ShouldBeFalse)
+
+			send(1)
+			_, ok = server.WaitForRequests(3, 5*time.Second)
+			So(ok, ShouldBeTrue)
+			So(c.Close(), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 3)
+		})
+
+		Convey("flushes every interval", func() {
+			c.Batch(100, 20*time.Millisecond)
+			send(2)
+			_, ok := server.WaitForRequests(2, 5*time.Second)
+			So(ok, ShouldBeTrue)
+
+			send(1)
+			_, ok = server.WaitForRequests(3, 5*time.Second)
+			So(ok, ShouldBeTrue)
+			So(c.Close(), ShouldBeNil)
+		})
+
+		Convey("delivers all reports on Close", func() {
+			c.Batch(100, time.Hour)
+			send(5)
+			So(c.Clone().SendError(errors.New("Test Batch")), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 0)
+
+			So(c.Close(), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 6)
+			So(c.SendError(errors.New("Test Batch")), ShouldEqual, ErrClientClosed)
+		})
+
+		Convey("delivers all reports on Flush", func() {
+			c.Batch(100, time.Hour)
+			send(4)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 4)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 4)
+		})
+
+		Convey("returns failed deliveries from Flush", func() {
+			c.Batch(100, time.Hour)
+			server.Script(rayguntest.BadRequest("invalid"))
+			send(2)
+			err := c.Flush(context.Background())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "Unable to deliver 1 batched reports")
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(server.Count(), ShouldEqual, 2)
+		})
+
+		Convey("keeps reports while rate limited", func() {
+			now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			c.clock = func() time.Time { return now }
+			c.Batch(100, time.Hour)
+			server.Script(rayguntest.TooManyRequests("60"))
+			send(3)
+			So(errors.Is(c.Flush(context.Background()), ErrRateLimited), ShouldBeTrue)
+			So(server.Count(), ShouldEqual, 1)
+
+			now = now.Add(time.Minute)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 4)
+		})
+
+		Convey("persists undelivered reports on Close", func() {
+			dir, _ := os.MkdirTemp("", "raygun4go")
+			Reset(func() { os.RemoveAll(dir) })
+			c.Batch(100, time.Hour).PersistQueueOnClose(dir)
+			server.Script(rayguntest.TooManyRequests("60"))
+			send(2)
+			So(c.Close(), ShouldBeNil)
+
+			files, err := storedReportFiles(dir)
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 2)
+		})
+
+		Convey("gives up on a hung endpoint when reconfigured", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			c.closeTimeout = 20 * time.Millisecond
+			var dropped []PostData
+			c.OnDrop(func(post PostData) { dropped = append(dropped, post) })
+			c.Batch(10, time.Hour)
+			send(3)
+
+			start := time.Now()
+			c.Batch(10, time.Hour)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+
+			Convey("moving the undelivered reports to the new batch", func() {
+				So(c.Flush(context.Background()), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 3)
+				So(dropped, ShouldBeEmpty)
+			})
+
+			Convey("dropping them if batching is disabled", func() {
+				server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+				c.Batch(0, 0)
+				So(dropped, ShouldHaveLength, 1)
+				So(c.Stats().Dropped, ShouldEqual, 1)
+			})
+		})
+
+		Convey("delivers with the configuration of the submitting client", func() {
+			transport := NewRecordingTransport()
+			c.Batch(100, time.Hour)
+			So(c.Clone().Transport(transport).SendError(errors.New("Test Batch")), ShouldBeNil)
+			send(1)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(transport.Posts(), ShouldHaveLength, 1)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("sends test reports immediately", func() {
+			c.Batch(100, time.Hour)
+			_, err := c.SendTestReport(context.Background())
+			So(err, ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("is disabled by default and with a size of 0", func() {
+			send(1)
+			So(server.Count(), ShouldEqual, 1)
+			c.Batch(10, time.Hour).Batch(0, time.Hour)
+			send(1)
+			So(server.Count(), ShouldEqual, 2)
+			So(c.Flush(context.Background()), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package raygun4go
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxBreadcrumbs is the number of breadcrumbs a client keeps unless
+// configured otherwise.
+const defaultMaxBreadcrumbs = 32
+
+// BreadcrumbLevel is the severity of a breadcrumb.
+type BreadcrumbLevel int
+
+// The levels a breadcrumb can have, in the encoding Raygun expects.
+const (
+	BreadcrumbDebug BreadcrumbLevel = iota
+	BreadcrumbInfo
+	BreadcrumbWarning
+	BreadcrumbError
+)
+
+// Breadcrumb is an event that happened before an error and helps to
+// understand how it came about. Breadcrumbs recorded on a client are sent with
+// its next reports.
+type Breadcrumb struct {
+	Timestamp  int64                  `json:"timeStamp"`            // the time of the event in milliseconds since the epoch, set when recorded if zero
+	Level      BreadcrumbLevel        `json:"level"`                // the severity of the event
+	Category   string                 `json:"category,omitempty"`   // the kind of event, e.g. "http"
+	Message    string                 `json:"message"`              // what happened
+	CustomData map[string]interface{} `json:"customData,omitempty"` // further details on the event
+}
+
+// breadcrumbTrail holds the most recent breadcrumbs of a client.
+type breadcrumbTrail struct {
+	mu     sync.Mutex
+	max    int
+	crumbs []Breadcrumb
+	tags   []string // tags added to the following reports because of recorded events
+}
+
+// newBreadcrumbTrail returns an empty trail keeping up to max breadcrumbs.
+func newBreadcrumbTrail(max int) *breadcrumbTrail {
+	return &breadcrumbTrail{max: max}
+}
+
+// clone returns an independent copy of the trail.
+func (t *breadcrumbTrail) clone() *breadcrumbTrail {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &breadcrumbTrail{max: t.max, crumbs: append([]Breadcrumb(nil), t.crumbs...), tags: copyStrings(t.tags)}
+}
+
+// record adds the given breadcrumb, dropping the oldest ones beyond the
+// maximum.
+func (t *breadcrumbTrail) record(b Breadcrumb) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.crumbs = append(t.crumbs, b)
+	if len(t.crumbs) > t.max {
+		t.crumbs = append([]Breadcrumb(nil), t.crumbs[len(t.crumbs)-t.max:]...)
+	}
+}
+
+// setMax changes the maximum number of breadcrumbs kept.
+func (t *breadcrumbTrail) setMax(max int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.max = max
+	if len(t.crumbs) > max {
+		t.crumbs = append([]Breadcrumb(nil), t.crumbs[len(t.crumbs)-max:]...)
+	}
+}
+
+// tag adds the given tag to the following reports.
+func (t *breadcrumbTrail) tag(tag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, existing := range t.tags {
+		if existing == tag {
+			return
+		}
+	}
+	t.tags = append(t.tags, tag)
+}
+
+// clear removes all breadcrumbs and the tags added with them.
+func (t *breadcrumbTrail) clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.crumbs = nil
+	t.tags = nil
+}
+
+// tagSnapshot returns a copy of the tags added with tag.
+func (t *breadcrumbTrail) tagSnapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return copyStrings(t.tags)
+}
+
+// snapshot returns a copy of the breadcrumbs, or nil if there are none.
+func (t *breadcrumbTrail) snapshot() []Breadcrumb {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.crumbs) == 0 {
+		return nil
+	}
+	return append([]Breadcrumb(nil), t.crumbs...)
+}
+
+// RecordBreadcrumb is a chainable method adding a breadcrumb that is sent
+// with the following reports of the client. Only the most recent breadcrumbs
+// are kept, see MaxBreadcrumbs. Clones start out with a copy of the
+// breadcrumbs of their client and record their own from then on.
+func (c *Client) RecordBreadcrumb(b Breadcrumb) *Client {
+	if c == nil {
+		return nil
+	}
+	if b.Timestamp == 0 {
+		b.Timestamp = c.clock().UnixNano() / int64(time.Millisecond)
+	}
+	c.breadcrumbs.record(b)
+	return c
+}
+
+// MaxBreadcrumbs is a chainable option-setting method to set the number of
+// breadcrumbs kept, 32 by default. Non-positive values restore the default.
+func (c *Client) MaxBreadcrumbs(max int) *Client {
+	if c == nil {
+		return nil
+	}
+	if max <= 0 {
+		max = defaultMaxBreadcrumbs
+	}
+	c.breadcrumbs.setMax(max)
+	return c
+}
+
+// ClearBreadcrumbs is a chainable method removing all recorded breadcrumbs,
+// including the tags added because of them, e.g. by DatabaseBreadcrumb.
+func (c *Client) ClearBreadcrumbs() *Client {
+	if c == nil {
+		return nil
+	}
+	c.breadcrumbs.clear()
+	return c
+}
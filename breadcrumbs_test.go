@@ -0,0 +1,69 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	Convey("Breadcrumbs", t, func() {
+		c, _ := New("app", "key")
+		now := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)
+		c.clock = func() time.Time { return now }
+		post := func(c *Client) PostData {
+			return c.createPost(errors.New("Test Breadcrumbs"), StackTrace{})
+		}
+
+		Convey("are left out of the payload if there are none", func() {
+			data, _ := json.Marshal(post(c))
+			So(string(data), ShouldNotContainSubstring, "breadcrumbs")
+		})
+
+		Convey("#RecordBreadcrumb adds breadcrumbs to the following reports", func() {
+			c.RecordBreadcrumb(Breadcrumb{Message: "first"})
+			c.RecordBreadcrumb(Breadcrumb{Message: "second", Level: BreadcrumbWarning, Timestamp: 42})
+
+			So(post(c).Details.Breadcrumbs, ShouldResemble, []Breadcrumb{
+				{Timestamp: now.UnixNano() / int64(time.Millisecond), Message: "first"},
+				{Timestamp: 42, Level: BreadcrumbWarning, Message: "second"},
+			})
+
+			data, _ := json.Marshal(post(c))
+			So(string(data), ShouldContainSubstring, `"breadcrumbs":[{"timeStamp":1704164645678,"level":0,"message":"first"}`)
+		})
+
+		Convey("#MaxBreadcrumbs keeps the most recent breadcrumbs", func() {
+			c.MaxBreadcrumbs(2)
+			for i := 0; i < 5; i++ {
+				c.RecordBreadcrumb(Breadcrumb{Message: fmt.Sprint(i), Timestamp: 1})
+			}
+			So(post(c).Details.Breadcrumbs, ShouldResemble, []Breadcrumb{
+				{Timestamp: 1, Message: "3"},
+				{Timestamp: 1, Message: "4"},
+			})
+		})
+
+		Convey("#ClearBreadcrumbs removes all breadcrumbs", func() {
+			c.RecordBreadcrumb(Breadcrumb{Message: "first"})
+			c.ClearBreadcrumbs()
+			So(post(c).Details.Breadcrumbs, ShouldBeNil)
+		})
+
+		Convey("clones copy the breadcrumbs and record their own", func() {
+			c.RecordBreadcrumb(Breadcrumb{Message: "parent", Timestamp: 1})
+			clone := c.Clone()
+			clone.RecordBreadcrumb(Breadcrumb{Message: "clone", Timestamp: 1})
+
+			So(post(c).Details.Breadcrumbs, ShouldResemble, []Breadcrumb{{Timestamp: 1, Message: "parent"}})
+			So(post(clone).Details.Breadcrumbs, ShouldResemble, []Breadcrumb{
+				{Timestamp: 1, Message: "parent"},
+				{Timestamp: 1, Message: "clone"},
+			})
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package raygun4go
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxBufferedPayloads is the number of payloads kept by BufferOnly until they
+// are drained. Older payloads are dropped once it is reached.
+const maxBufferedPayloads = 100
+
+// platformBuffersOnly is true on platforms without a usable network stack,
+// where clients buffer reports by default, see BufferOnly.
+var platformBuffersOnly = runtime.GOOS == "js" || runtime.GOOS == "wasip1"
+
+// payloadBuffer holds encoded reports until they are drained, see BufferOnly.
+type payloadBuffer struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+// add appends the given payload, dropping the oldest one if the buffer is
+// full. It reports whether a payload was dropped.
+func (b *payloadBuffer) add(payload []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dropped := len(b.payloads) == maxBufferedPayloads
+	if dropped {
+		b.payloads = b.payloads[1:]
+	}
+	b.payloads = append(b.payloads, payload)
+	return dropped
+}
+
+// drain returns and removes all payloads.
+func (b *payloadBuffer) drain() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	payloads := b.payloads
+	b.payloads = nil
+	return payloads
+}
+
+// BufferOnly is a chainable option-setting method to keep reports in memory
+// instead of sending them, for environments without a usable network stack
+// such as WebAssembly. The host environment drains the encoded payloads with
+// DrainPayloads and ships them through its own channel. Up to 100 payloads
+// are kept, older ones are dropped. No goroutines are started for buffered
+// reports, whether or not the client is asynchronous.
+//
+// Clients buffer by default when compiled for GOOS=js or GOOS=wasip1.
+func (c *Client) BufferOnly(b bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.bufferOnly = b
+	return c
+}
+
+// DrainPayloads returns the JSON payloads buffered by BufferOnly, oldest
+// first, and removes them from the buffer, which is shared with all clones.
+// Each payload is to be POSTed to the Raygun API as is, with the API key in
+// the X-ApiKey header.
+func (c *Client) DrainPayloads() [][]byte {
+	if c == nil {
+		return nil
+	}
+	return c.buffer.drain()
+}
+
+// bufferPost encodes the given post and adds it to the buffer.
+func (c *Client) bufferPost(post PostData) error {
+	payload, err := c.encodePost(post)
+	if err != nil {
+		return err
+	}
+	if c.buffer.add(payload) {
+		c.debugf("Dropped the oldest buffered payload")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBufferOnly(t *testing.T) {
+	Convey("#BufferOnly", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.BufferOnly(true).Tags([]string{"edge"})
+
+		Convey("buffers reports instead of sending them", func() {
+			So(c.SendError(errors.New("Test BufferOnly")), ShouldBeNil)
+			So(c.CreateError("Test BufferOnly again"), ShouldBeNil)
+			So(len(server.posts), ShouldEqual, 0)
+
+			payloads := c.DrainPayloads()
+			So(payloads, ShouldHaveLength, 2)
+			var post PostData
+			So(json.Unmarshal(payloads[0], &post), ShouldBeNil)
+			So(post.Details.Error.Message, ShouldEqual, "Test BufferOnly")
+			So(post.Details.Tags, ShouldResemble, []string{"edge"})
+			So(json.Unmarshal(payloads[1], &post), ShouldBeNil)
+			So(post.Details.Error.Message, ShouldEqual, "Test BufferOnly again")
+		})
+
+		Convey("empties the buffer when drained", func() {
+			c.SendError(errors.New("Test BufferOnly"))
+			c.DrainPayloads()
+			So(c.DrainPayloads(), ShouldBeEmpty)
+		})
+
+		Convey("buffers panics and asynchronous reports", func() {
+			c.Asynchronous(true)
+			func() {
+				defer c.HandleError()
+				panic("Test BufferOnly")
+			}()
+			c.SendError(errors.New("Test BufferOnly"))
+			So(c.DrainPayloads(), ShouldHaveLength, 2)
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("shares the buffer with clones", func() {
+			c.Clone().SendError(errors.New("Test BufferOnly"))
+			So(c.DrainPayloads(), ShouldHaveLength, 1)
+		})
+
+		Convey("drops the oldest payloads beyond the cap", func() {
+			for i := 0; i < 105; i++ {
+				c.SendError(fmt.Errorf("Test BufferOnly %d", i))
+			}
+			payloads := c.DrainPayloads()
+			So(payloads, ShouldHaveLength, 100)
+			var post PostData
+			json.Unmarshal(payloads[0], &post)
+			So(post.Details.Error.Message, ShouldEqual, "Test BufferOnly 5")
+		})
+
+		Convey("sends again once disabled", func() {
+			c.BufferOnly(false)
+			So(c.SendError(errors.New("Test BufferOnly")), ShouldBeNil)
+			_, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(c.DrainPayloads(), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by submissions made while the circuit breaker
+// is open after repeated failures, see CircuitBreaker.
+var ErrCircuitOpen = errors.New("Raygun submissions are suspended after repeated failures")
+
+// circuitBreaker suspends submissions after repeated failures. It is shared
+// by a client and the clones made after setting it.
+type circuitBreaker struct {
+	threshold int           // the number of consecutive failures opening the circuit
+	cooldown  time.Duration // the time the circuit stays open
+
+	mu        sync.Mutex
+	failures  int       // the number of consecutive failures so far
+	openUntil time.Time // the end of the cooldown, once the circuit opened
+	probing   bool      // if true, the probe of the half-open circuit is in flight
+}
+
+// CircuitBreaker is a chainable option-setting method to fail submissions
+// with ErrCircuitOpen for cooldown after threshold consecutive failures. A
+// non-positive threshold disables it, which is the default.
+func (c *Client) CircuitBreaker(threshold int, cooldown time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	c.circuit = nil
+	if threshold > 0 {
+		c.circuit = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+	return c
+}
+
+// allow reports whether a submission may be made at the given time. In the
+// half-open state it allows a single probe until its result is recorded.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.threshold:
+		return true
+	case now.Before(b.openUntil) || b.probing:
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record records the result of a submission allowed at the given time and
+// reports whether it opened the circuit. A canceled submission tells nothing
+// about Raygun, so it only frees the probe and keeps the state.
+func (b *circuitBreaker) record(err error, now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if circuitCanceled(err) {
+		return false
+	}
+	if !circuitFailure(err) {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// circuitFailure reports whether a submission failing with err counts
+// towards opening the circuit, i.e. Raygun could not be reached or failed.
+// Rejected reports and canceled requests do not.
+func circuitFailure(err error) bool {
+	var submitErr *SubmitError
+	if !errors.As(err, &submitErr) {
+		return false
+	}
+	if submitErr.StatusCode != 0 {
+		return submitErr.StatusCode >= 500
+	}
+	return !submitErr.Canceled
+}
+
+// circuitCanceled reports whether a submission failing with err was
+// canceled before Raygun answered.
+func circuitCanceled(err error) bool {
+	var submitErr *SubmitError
+	if errors.As(err, &submitErr) && submitErr.StatusCode == 0 {
+		return submitErr.Canceled
+	}
+	return isCanceled(err)
+}
+
+// circuitOpen handles the given post submitted while the circuit is open:
+// it persists the post if a directory is set by PersistQueueOnClose and
+// returns ErrCircuitOpen.
+func (c *Client) circuitOpen(post PostData) error {
+	if c.queueDir != "" && c.offline == nil {
+		err := persistReports(c.queueDir, []queuedReport{{client: c, post: post}})
+		if err != nil {
+			c.errorf("Unable to persist %s while the circuit is open (%s)", post.Summary(), err.Error())
+		}
+	}
+	return ErrCircuitOpen
+}
@@ -0,0 +1,145 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	Convey("CircuitBreaker", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return now }
+		c.Endpoint(server.URL).CircuitBreaker(3, time.Minute)
+
+		send := func() error {
+			return c.SendError(errors.New("Test CircuitBreaker"))
+		}
+		failing := rayguntest.Status(http.StatusServiceUnavailable)
+
+		Convey("opens after consecutive failures", func() {
+			server.Script(failing, rayguntest.Status(http.StatusBadGateway), failing)
+			for i := 0; i < 3; i++ {
+				So(send(), ShouldNotEqual, ErrCircuitOpen)
+			}
+			So(send(), ShouldEqual, ErrCircuitOpen)
+			So(send(), ShouldEqual, ErrCircuitOpen)
+			So(server.Count(), ShouldEqual, 3)
+
+			Convey("and is shared with clones", func() {
+				So(c.Clone().SendError(errors.New("Test CircuitBreaker")), ShouldEqual, ErrCircuitOpen)
+			})
+
+			Convey("closes after a successful probe", func() {
+				now = now.Add(time.Minute)
+				So(send(), ShouldBeNil)
+				So(send(), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 5)
+			})
+
+			Convey("opens again after a failed probe", func() {
+				server.Script(failing)
+				now = now.Add(time.Minute)
+				So(send(), ShouldNotBeNil)
+				So(server.Count(), ShouldEqual, 4)
+				So(send(), ShouldEqual, ErrCircuitOpen)
+
+				now = now.Add(59 * time.Second)
+				So(send(), ShouldEqual, ErrCircuitOpen)
+				now = now.Add(time.Second)
+				So(send(), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 5)
+			})
+
+			Convey("persists reports if a directory is set", func() {
+				dir := t.TempDir()
+				c.PersistQueueOnClose(dir)
+				So(send(), ShouldEqual, ErrCircuitOpen)
+				files, err := storedReportFiles(dir)
+				So(err, ShouldBeNil)
+				So(files, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("follows a flapping endpoint", func() {
+			server.Script(failing, failing, rayguntest.Accepted, failing, failing, rayguntest.Accepted)
+			for i := 0; i < 6; i++ {
+				So(send(), ShouldNotEqual, ErrCircuitOpen)
+			}
+			So(server.Count(), ShouldEqual, 6)
+		})
+
+		Convey("ignores rejected reports", func() {
+			server.Fallback(rayguntest.BadRequest("invalid report"))
+			for i := 0; i < 5; i++ {
+				So(send(), ShouldNotEqual, ErrCircuitOpen)
+			}
+			So(server.Count(), ShouldEqual, 5)
+		})
+
+		Convey("counts timeouts", func() {
+			server.Fallback(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			c.Timeout(10 * time.Millisecond)
+			for i := 0; i < 3; i++ {
+				So(send(), ShouldNotBeNil)
+			}
+			So(send(), ShouldEqual, ErrCircuitOpen)
+		})
+
+		Convey("is disabled by a non-positive threshold", func() {
+			c.CircuitBreaker(0, time.Minute)
+			server.Fallback(failing)
+			for i := 0; i < 5; i++ {
+				So(send(), ShouldNotEqual, ErrCircuitOpen)
+			}
+			So(server.Count(), ShouldEqual, 5)
+		})
+	})
+
+	Convey("circuitBreaker", t, func() {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		b := &circuitBreaker{threshold: 1, cooldown: time.Minute}
+		failure := &SubmitError{StatusCode: http.StatusBadGateway}
+
+		Convey("allows a single probe when half-open", func() {
+			So(b.allow(now), ShouldBeTrue)
+			So(b.record(failure, now), ShouldBeTrue)
+			So(b.allow(now), ShouldBeFalse)
+
+			now = now.Add(time.Minute)
+			So(b.allow(now), ShouldBeTrue)
+			So(b.allow(now), ShouldBeFalse)
+			So(b.record(nil, now), ShouldBeFalse)
+			So(b.allow(now), ShouldBeTrue)
+			So(b.allow(now), ShouldBeTrue)
+		})
+
+		Convey("does not count canceled requests", func() {
+			So(b.record(&SubmitError{Err: context.Canceled, Canceled: true}, now), ShouldBeFalse)
+			So(b.allow(now), ShouldBeTrue)
+		})
+
+		Convey("keeps the circuit open after a canceled probe", func() {
+			So(b.record(failure, now), ShouldBeTrue)
+
+			now = now.Add(time.Minute)
+			So(b.allow(now), ShouldBeTrue)
+			So(b.record(&SubmitError{Err: context.Canceled, Canceled: true}, now), ShouldBeFalse)
+			So(b.allow(now), ShouldBeTrue)
+			So(b.allow(now), ShouldBeFalse)
+
+			So(b.record(context.Canceled, now), ShouldBeFalse)
+			So(b.allow(now), ShouldBeTrue)
+			So(b.allow(now), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,147 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateLimitedTag tags the summary reports of the reports suppressed by
+// RateLimit.
+const rateLimitedTag = "raygun4go-rate-limited"
+
+// suppressedCountKey is the custom data key of the number of reports a
+// summary report of RateLimit stands for.
+const suppressedCountKey = "raygun4go.suppressedCount"
+
+// ErrRateLimitExceeded is returned for reports submitted while the budget set
+// by RateLimit is exhausted. They are not sent.
+var ErrRateLimitExceeded = errors.New("raygun4go rate limit exceeded")
+
+// rateLimiter is a token bucket holding up to perMinute tokens, refilled at
+// perMinute tokens a minute. It counts the reports it suppressed during a
+// minute for a summary report. It is shared by a client and all clones made
+// after RateLimit was set.
+type rateLimiter struct {
+	mu         sync.Mutex
+	perMinute  int
+	tokens     float64
+	last       time.Time // the time tokens was last refilled
+	suppressed int       // the reports suppressed since windowEnd was set
+	windowEnd  time.Time // the end of the minute suppressed counts for
+}
+
+// RateLimit is a chainable option-setting method to send at most
+// maxPerMinute reports a minute, allowing bursts of that size. Reports beyond
+// it return ErrRateLimitExceeded and are dropped, or kept by OfflineStorage
+// if set; a summary report tagged "raygun4go-rate-limited" then tells how
+// many, with the next report sent after the minute. A maxPerMinute of 0 or
+// less disables it, its default.
+func (c *Client) RateLimit(maxPerMinute int) *Client {
+	if c == nil {
+		return nil
+	}
+	c.limiter = nil
+	if maxPerMinute > 0 {
+		c.limiter = &rateLimiter{perMinute: maxPerMinute, tokens: float64(maxPerMinute)}
+	}
+	return c
+}
+
+// allow reports whether a report may be sent at the given time. It also
+// returns the number of reports suppressed during the minute before, once
+// that minute has passed, and 0 otherwise.
+func (l *rateLimiter) allow(now time.Time) (ok bool, suppressed int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	if l.suppressed > 0 && !now.Before(l.windowEnd) {
+		suppressed, l.suppressed = l.suppressed, 0
+	}
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, suppressed
+	}
+	if l.suppressed == 0 {
+		l.windowEnd = now.Add(time.Minute)
+	}
+	l.suppressed++
+	return false, suppressed
+}
+
+// take reports whether a stored report may be sent at the given time, like
+// allow, without counting it as suppressed otherwise. A nil limiter allows
+// all reports.
+func (l *rateLimiter) take(now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// refill adds the tokens accrued since the last refill. The caller holds
+// l.mu.
+func (l *rateLimiter) refill(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Minutes() * float64(l.perMinute)
+		if max := float64(l.perMinute); l.tokens > max {
+			l.tokens = max
+		}
+	}
+	if l.last.IsZero() || now.After(l.last) {
+		l.last = now
+	}
+}
+
+// throttle applies the rate limit to the given admitted post and returns
+// ErrRateLimitExceeded if it must not be sent. Suppressed posts are kept by
+// the offline storage if set, and dropped otherwise.
+func (c *Client) throttle(post PostData) error {
+	if c.limiter == nil {
+		return nil
+	}
+	ok, suppressed := c.limiter.allow(c.clock())
+	if suppressed > 0 {
+		c.sendRateLimitSummary(suppressed)
+	}
+	if ok {
+		return nil
+	}
+
+	c.stats.submit()
+	r := queuedReport{client: c, post: post}
+	if c.offline != nil {
+		c.stats.record(ErrRateLimitExceeded)
+		c.metrics.IncFailed(failureReason(ErrRateLimitExceeded))
+		if err := c.offline.store([]queuedReport{r}); err != nil {
+			c.errorf("Unable to store %s for later delivery (%s)", post.Summary(), err.Error())
+		}
+		return ErrRateLimitExceeded
+	}
+	dropReports(ErrRateLimitExceeded, r)
+	return ErrRateLimitExceeded
+}
+
+// sendRateLimitSummary sends a report stating how many reports were
+// suppressed by the rate limit. It is not rate limited itself.
+func (c *Client) sendRateLimitSummary(suppressed int) {
+	var opts reportOptions
+	opts.addCustomData(suppressedCountKey, suppressed)
+	opts.tags = []string{rateLimitedTag}
+	err := fmt.Errorf("%s: %d reports suppressed by the rate limit", rateLimitedTag, suppressed)
+	summary := c.createPostWithOptions(err, StackTrace{}, opts)
+	if err := c.dispatch(context.Background(), summary, true); err != nil {
+		c.errorf("Unable to send %s\n%s", summary.Summary(), err.Error())
+	}
+}
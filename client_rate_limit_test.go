@@ -0,0 +1,124 @@
+package raygun4go
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	Convey("#RateLimit", t, func() {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		transport := NewRecordingTransport()
+		var dropped []PostData
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return now }
+		c.Transport(transport).RateLimit(3).OnDrop(func(post PostData) {
+			dropped = append(dropped, post)
+		})
+		send := func(n int) (errs int) {
+			for i := 0; i < n; i++ {
+				if err := c.SendError(errors.New("Test RateLimit")); err != nil {
+					So(err, ShouldEqual, ErrRateLimitExceeded)
+					errs++
+				}
+			}
+			return errs
+		}
+
+		Convey("drops reports beyond the budget and counts them", func() {
+			So(send(5), ShouldEqual, 2)
+			So(transport.Posts(), ShouldHaveLength, 3)
+			So(dropped, ShouldHaveLength, 2)
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 5, Succeeded: 3, Dropped: 2})
+		})
+
+		Convey("refills the budget over time", func() {
+			So(send(3), ShouldEqual, 0)
+			So(send(1), ShouldEqual, 1)
+			now = now.Add(20 * time.Second)
+			So(send(2), ShouldEqual, 1)
+			now = now.Add(time.Hour)
+			So(send(4), ShouldEqual, 1)
+		})
+
+		Convey("sends a summary of the suppressed reports after a minute", func() {
+			So(send(13), ShouldEqual, 10)
+			now = now.Add(30 * time.Second)
+			So(send(1), ShouldEqual, 0)
+			So(transport.Posts(), ShouldHaveLength, 4)
+
+			now = now.Add(30 * time.Second)
+			So(send(1), ShouldEqual, 0)
+			posts := transport.Posts()
+			So(posts, ShouldHaveLength, 6)
+			summary := posts[4]
+			So(summary.Details.Tags, ShouldContain, rateLimitedTag)
+			So(summary.Details.UserCustomData.(map[string]interface{})[suppressedCountKey], ShouldEqual, 10)
+			So(posts[5].Details.Tags, ShouldNotContain, rateLimitedTag)
+
+			now = now.Add(time.Hour)
+			So(send(1), ShouldEqual, 0)
+			So(transport.Posts(), ShouldHaveLength, 7)
+		})
+
+		Convey("is disabled by a non-positive maximum", func() {
+			c.RateLimit(0)
+			So(send(10), ShouldEqual, 0)
+		})
+
+		Convey("is shared with clones", func() {
+			So(c.Clone().limiter, ShouldEqual, c.limiter)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.RateLimit(1), ShouldBeNil)
+		})
+	})
+
+	Convey("#RateLimit with OfflineStorage", t, func() {
+		var mu sync.Mutex
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		server := rayguntest.New()
+		dir, _ := os.MkdirTemp("", "raygun4go")
+		c, _ := New("app", "key")
+		c.clock = func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		c.Endpoint(server.URL).RateLimit(1)
+		c.offline = newOfflineStore(c, dir, 10, 10*time.Millisecond)
+		Reset(func() {
+			c.Close()
+			server.Close()
+			os.RemoveAll(dir)
+		})
+		stored := func() int {
+			files, _ := storedReportFiles(dir)
+			return len(files)
+		}
+
+		Convey("keeps suppressed reports and delivers them within the budget", func() {
+			So(c.SendError(errors.New("Test RateLimit")), ShouldBeNil)
+			So(c.SendError(errors.New("Test RateLimit")), ShouldEqual, ErrRateLimitExceeded)
+			So(stored(), ShouldEqual, 1)
+			time.Sleep(50 * time.Millisecond)
+			So(stored(), ShouldEqual, 1)
+			So(server.Count(), ShouldEqual, 1)
+
+			mu.Lock()
+			now = now.Add(time.Minute)
+			mu.Unlock()
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			So(server.Count(), ShouldEqual, 2)
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 2, Succeeded: 1, Failed: 1})
+		})
+	})
+}
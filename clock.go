@@ -0,0 +1,65 @@
+package raygun4go
+
+import (
+	"sync"
+	"time"
+)
+
+// maxClockSkew is the furthest occurredOn may be from the wall clock. Beyond
+// it, the wall clock is assumed to have been stepped and the skew is reported.
+const maxClockSkew = time.Minute
+
+// clockSkewKey is the custom data key a detected clock skew is stored under.
+const clockSkewKey = "clockSkew"
+
+// occurrenceClock computes the occurredOn of reports from the wall clock at
+// the first report plus the monotonic time elapsed since, so steps of the
+// wall clock do not make reports jump in time. It is shared by a client and
+// all its clones.
+type occurrenceClock struct {
+	elapsed func() time.Duration // the monotonic time since the clock was created, replaced in tests
+
+	mu         sync.Mutex
+	anchored   bool
+	anchorWall time.Time     // the wall clock at the first report
+	anchorMono time.Duration // the monotonic time at the first report
+	last       time.Time     // the occurredOn of the latest report
+}
+
+// newOccurrenceClock returns a clock that anchors itself with the first
+// report.
+func newOccurrenceClock() *occurrenceClock {
+	start := time.Now()
+	return &occurrenceClock{elapsed: func() time.Duration { return time.Since(start) }}
+}
+
+// now returns the occurredOn of a report made at the given wall clock time,
+// in UTC. It never returns a time before one returned earlier and stays
+// within maxClockSkew of the wall clock. If the wall clock deviates further
+// from the monotonic time, the deviation is returned as skew.
+func (o *occurrenceClock) now(wall time.Time) (occurredOn time.Time, skew time.Duration) {
+	mono := o.elapsed()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.anchored {
+		o.anchored = true
+		o.anchorWall = wall
+		o.anchorMono = mono
+	}
+
+	occurredOn = o.anchorWall.Add(mono - o.anchorMono)
+	if d := wall.Sub(occurredOn); d > maxClockSkew || d < -maxClockSkew {
+		skew = d
+		if d > 0 {
+			occurredOn = wall.Add(-maxClockSkew)
+		} else {
+			occurredOn = wall.Add(maxClockSkew)
+		}
+	}
+	if occurredOn.Before(o.last) {
+		occurredOn = o.last
+	}
+	o.last = occurredOn
+	return occurredOn.UTC(), skew
+}
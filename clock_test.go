@@ -0,0 +1,63 @@
+package raygun4go
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOccurrenceClock(t *testing.T) {
+	Convey("occurredOn", t, func() {
+		start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+		wall, mono := start, time.Duration(0)
+
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return wall }
+		c.occurrence.elapsed = func() time.Duration { return mono }
+
+		report := func(d time.Duration) PostData {
+			wall, mono = wall.Add(d), mono+d
+			return c.createPost(errors.New("Test occurredOn"), StackTrace{})
+		}
+
+		Convey("is the wall clock in UTC", func() {
+			So(report(0).OccuredOn, ShouldEqual, "2024-01-01T11:00:00Z")
+			So(report(5*time.Second).OccuredOn, ShouldEqual, "2024-01-01T11:00:05Z")
+			So(withoutReportID(report(0)), ShouldBeNil)
+		})
+
+		Convey("stays monotonic if the wall clock steps backwards", func() {
+			var occurredOn []string
+			occurredOn = append(occurredOn, report(0).OccuredOn, report(time.Second).OccuredOn)
+
+			wall = wall.Add(-10 * time.Minute)
+			stepped := report(time.Second)
+			occurredOn = append(occurredOn, stepped.OccuredOn)
+			for i := 0; i < 5; i++ {
+				occurredOn = append(occurredOn, report(30*time.Second).OccuredOn)
+			}
+
+			for i := 1; i < len(occurredOn); i++ {
+				So(occurredOn[i], ShouldBeGreaterThanOrEqualTo, occurredOn[i-1])
+			}
+			So(withoutReportID(stepped), ShouldResemble, map[string]interface{}{"clockSkew": "-10m0s"})
+			So(occurredOn[len(occurredOn)-1], ShouldEqual, "2024-01-01T11:00:01Z")
+		})
+
+		Convey("stays close to the wall clock if it steps forwards", func() {
+			report(0)
+			wall = wall.Add(time.Hour)
+			stepped := report(time.Second)
+			So(stepped.OccuredOn, ShouldEqual, "2024-01-01T11:59:01Z")
+			So(withoutReportID(stepped), ShouldResemble, map[string]interface{}{"clockSkew": "1h0m0s"})
+		})
+
+		Convey("is shared by clones", func() {
+			report(time.Minute)
+			wall = wall.Add(-10 * time.Minute)
+			So(c.Clone().createPost(errors.New("Test occurredOn"), StackTrace{}).OccuredOn, ShouldEqual, "2024-01-01T11:01:00Z")
+		})
+	})
+}
@@ -0,0 +1,102 @@
+package raygun4go
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// maxCopyDepth is the depth of nested maps and slices copyCustomData copies,
+// deeper ones are shared, e.g. those of a map containing itself.
+const maxCopyDepth = 32
+
+// CustomDataCopier is implemented by custom data that copies itself when a
+// client holding it is cloned, e.g. because it contains maps that Clone
+// cannot copy by reflection. CopyCustomData returns the copy.
+type CustomDataCopier interface {
+	CopyCustomData() interface{}
+}
+
+// copyCustomData returns a copy of the given custom data for a clone of the
+// client holding it. Values implementing CustomDataCopier copy themselves;
+// maps, slices and arrays are copied recursively. Everything else, like
+// pointers and structs, is shared, as are the maps they contain.
+func copyCustomData(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(data), 0).Interface()
+}
+
+// copyValue implements copyCustomData for the given value at the given depth.
+func copyValue(v reflect.Value, depth int) reflect.Value {
+	if depth > maxCopyDepth {
+		return v
+	}
+	if v.Kind() != reflect.Interface && !(v.Kind() == reflect.Ptr && v.IsNil()) && v.CanInterface() {
+		if copier, ok := v.Interface().(CustomDataCopier); ok {
+			if c := reflect.ValueOf(copier.CopyCustomData()); c.IsValid() && c.Type().AssignableTo(v.Type()) {
+				return c
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem(), depth+1))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value(), depth+1))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), depth+1))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), depth+1))
+		}
+		return c
+	}
+	return v
+}
+
+// cloneRequest returns a shallow copy of the given request with its URL and
+// header copied, so that changes to them do not affect the reports of a
+// clone. The body and the parsed form are shared; the form is copied under
+// the given guard of the request, which may be nil, and parsed through it by
+// the reports of the clone.
+func cloneRequest(r *http.Request, guard *requestForm) *http.Request {
+	if r == nil {
+		return nil
+	}
+	if guard != nil {
+		guard.mu.Lock()
+	}
+	clone := *r
+	if guard != nil {
+		guard.mu.Unlock()
+	}
+	if r.URL != nil {
+		u := *r.URL
+		clone.URL = &u
+	}
+	clone.Header = r.Header.Clone()
+	return &clone
+}
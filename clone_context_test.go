@@ -0,0 +1,90 @@
+package raygun4go
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// copyingData copies itself with a marker, see CustomDataCopier.
+type copyingData struct {
+	values map[string]string
+}
+
+// CopyCustomData returns a copy of the data.
+func (d *copyingData) CopyCustomData() interface{} {
+	return &copyingData{values: map[string]string{"copied": "true"}}
+}
+
+func TestCloneContext(t *testing.T) {
+	Convey("#Clone", t, func() {
+		c, _ := New("app", "key")
+
+		Convey("copies the tags", func() {
+			tags := make([]string, 1, 4)
+			tags[0] = "a"
+			c.Tags(tags)
+			clone := c.Clone()
+
+			tags[0] = "changed"
+			_ = append(tags, "appended")
+			So(clone.context.Tags, ShouldResemble, []string{"a"})
+			So(clone.context.Tags[:cap(clone.context.Tags)], ShouldResemble, []string{"a"})
+		})
+
+		Convey("copies maps and slices in the custom data", func() {
+			data := map[string]string{"k": "v"}
+			c.CustomData(data)
+			clone := c.Clone()
+			data["k"] = "changed"
+			data["new"] = "entry"
+			So(clone.context.CustomData, ShouldResemble, map[string]string{"k": "v"})
+
+			nested := map[string]interface{}{"list": []interface{}{"a", map[string]int{"n": 1}}}
+			c.CustomData(nested)
+			clone = c.Clone()
+			nested["list"].([]interface{})[0] = "changed"
+			nested["list"].([]interface{})[1].(map[string]int)["n"] = 2
+			So(clone.context.CustomData, ShouldResemble, map[string]interface{}{"list": []interface{}{"a", map[string]int{"n": 1}}})
+		})
+
+		Convey("keeps other custom data", func() {
+			c.CustomData("foo")
+			So(c.Clone().context.CustomData, ShouldEqual, "foo")
+
+			data := &struct{ Name string }{"foo"}
+			c.CustomData(data)
+			So(c.Clone().context.CustomData, ShouldEqual, data)
+		})
+
+		Convey("lets custom data copy itself", func() {
+			c.CustomData(map[string]interface{}{"data": &copyingData{}})
+			clone := c.Clone()
+			copied := clone.context.CustomData.(map[string]interface{})["data"].(*copyingData)
+			So(copied.values, ShouldResemble, map[string]string{"copied": "true"})
+		})
+
+		Convey("copies cyclic custom data up to a depth", func() {
+			data := map[string]interface{}{}
+			data["self"] = data
+			c.CustomData(data)
+			cloned := c.Clone().context.CustomData.(map[string]interface{})
+			So(len(cloned), ShouldEqual, 1)
+		})
+
+		Convey("copies the URL and the header of the request", func() {
+			r, _ := http.NewRequest("GET", "https://example.com/a", nil)
+			r.Header.Set("X-Test", "1")
+			c.Request(r)
+			clone := c.Clone()
+
+			r.URL.Path = "/changed"
+			r.Header.Set("X-Test", "changed")
+			So(clone.context.Request, ShouldNotEqual, r)
+			So(clone.context.Request.URL.String(), ShouldEqual, "https://example.com/a")
+			So(clone.context.Request.Header.Get("X-Test"), ShouldEqual, "1")
+			So(clone.context.Request.Context(), ShouldEqual, r.Context())
+		})
+	})
+}
@@ -0,0 +1,43 @@
+package raygun4go
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// Compression is a chainable option-setting method to gzip the JSON of
+// reports before posting them, which shrinks reports with large custom data
+// or long stack traces considerably. If compressing a report fails, it is
+// sent uncompressed. Silent mode, the archive sink and BufferOnly still see
+// the uncompressed JSON. Compression is off by default.
+func (c *Client) Compression(b bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.compression = b
+	return c
+}
+
+// compress returns the given JSON gzipped if Compression is set, and whether
+// it is.
+func (c *Client) compress(json []byte) ([]byte, bool) {
+	if !c.compression {
+		return json, false
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(json); err != nil {
+		return c.uncompressed(json, err)
+	}
+	if err := zw.Close(); err != nil {
+		return c.uncompressed(json, err)
+	}
+	return buf.Bytes(), true
+}
+
+// uncompressed returns the given JSON to be sent uncompressed because
+// compressing it failed with err.
+func (c *Client) uncompressed(json []byte, err error) ([]byte, bool) {
+	c.errorf("Unable to compress report, sending it uncompressed (%s)", err.Error())
+	return json, false
+}
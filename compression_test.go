@@ -0,0 +1,52 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompression(t *testing.T) {
+	Convey("Compression", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).CustomData(map[string]interface{}{"blob": strings.Repeat("data ", 1000)})
+		post := c.createPost(errors.New("Test Compression"), StackTrace{})
+		enc, _ := json.Marshal(post)
+		var original PostData
+		json.Unmarshal(enc, &original)
+
+		Convey("gzips reports", func() {
+			c.Compression(true)
+			So(c.Submit(post), ShouldBeNil)
+			request := server.Requests()[0]
+			So(request.Header.Get("Content-Encoding"), ShouldEqual, "gzip")
+			So(request.Gzipped, ShouldBeTrue)
+
+			var received PostData
+			So(request.Decode(&received), ShouldBeNil)
+			So(received, ShouldResemble, original)
+		})
+
+		Convey("shrinks large reports", func() {
+			c.Compression(true)
+			compressed, gzipped := c.compress(enc)
+			So(gzipped, ShouldBeTrue)
+			So(len(compressed), ShouldBeLessThan, len(enc)/10)
+		})
+
+		Convey("is off by default", func() {
+			So(c.Submit(post), ShouldBeNil)
+			request := server.Requests()[0]
+			So(request.Header.Get("Content-Encoding"), ShouldEqual, "")
+			So(request.Gzipped, ShouldBeFalse)
+			So(string(request.Body), ShouldEqual, string(enc))
+		})
+	})
+}
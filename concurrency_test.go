@@ -0,0 +1,90 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConcurrentUse(t *testing.T) {
+	Convey("A shared client", t, func() {
+		transport := NewRecordingTransport()
+		c, _ := New("app", "key")
+		c.Transport(transport)
+
+		Convey("is safe to configure while reports are sent", func() {
+			const n = 20
+			errs := make(chan error, n)
+			var wg sync.WaitGroup
+			wg.Add(4 * n)
+			for i := 0; i < n; i++ {
+				go func(i int) {
+					defer wg.Done()
+					c.Tags([]string{fmt.Sprint("tag", i)}).AddTags("added").User(fmt.Sprint("user", i)).Version(fmt.Sprint(i))
+					c.CustomData(map[string]interface{}{"i": i}).SetCustomDataKey("key", i)
+					c.RequestRef(httptest.NewRequest("GET", "/", nil))
+				}(i)
+				go func() {
+					defer wg.Done()
+					c.ClearTags().ClearUser().ClearCustomData().ResetContext()
+				}()
+				go func(i int) {
+					defer wg.Done()
+					errs <- c.SendError(fmt.Errorf("report %d", i))
+				}(i)
+				go func() {
+					defer wg.Done()
+					clone := c.Clone().Repanic(false)
+					clone.AddTags("clone")
+					func() {
+						defer clone.HandleError()
+						panic(errors.New("Test concurrency"))
+					}()
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				So(err, ShouldBeNil)
+			}
+			So(transport.Posts(), ShouldHaveLength, 2*n)
+		})
+
+		Convey("is not stalled by the slow upload of another request", func() {
+			body, upload := io.Pipe()
+			slow := httptest.NewRequest("POST", "/slow", body)
+			slow.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			slowClient := c.Clone().Request(slow)
+			parsing := make(chan error, 1)
+			go func() {
+				parsing <- slowClient.SendError(errors.New("slow"))
+			}()
+			time.Sleep(10 * time.Millisecond)
+
+			done := make(chan error, 1)
+			go func() {
+				fast := httptest.NewRequest("POST", "/fast", strings.NewReader("a=1"))
+				fast.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				done <- c.Clone().Request(fast).Clone().SendError(errors.New("fast"))
+			}()
+			select {
+			case err := <-done:
+				So(err, ShouldBeNil)
+			case <-time.After(5 * time.Second):
+				So("the report of the fast request waited for the slow upload", ShouldBeEmpty)
+			}
+
+			upload.Write([]byte("b=2"))
+			upload.Close()
+			So(<-parsing, ShouldBeNil)
+			So(transport.Posts()[1].Details.Request.Form, ShouldResemble, map[string]string{"b": "2"})
+		})
+	})
+}
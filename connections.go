@@ -0,0 +1,84 @@
+package raygun4go
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// The connection settings used for the fields of ConnectionOptions left at
+// zero. They are those of http.DefaultTransport.
+const (
+	defaultMaxIdleConns    = 100
+	defaultIdleConnTimeout = 90 * time.Second
+	defaultDialTimeout     = 30 * time.Second
+	defaultKeepAlive       = 30 * time.Second
+)
+
+// ConnectionOptions tunes the connections reports are posted over, see
+// Connections. Fields left at zero use the defaults of http.DefaultTransport.
+type ConnectionOptions struct {
+	// MaxIdleConns is the maximum number of idle connections to Raygun
+	// kept for reuse, 100 by default. As all reports go to the same host,
+	// it also is the maximum per host, which http.Transport otherwise limits
+	// to 2.
+	MaxIdleConns int
+
+	// IdleConnTimeout is the time an idle connection is kept before it is
+	// closed, 90 seconds by default.
+	IdleConnTimeout time.Duration
+
+	// DisableKeepAlives makes every report use a new connection.
+	DisableKeepAlives bool
+
+	// DialTimeout is the maximum time establishing a connection may take,
+	// 30 seconds by default. The Timeout of the client still limits the
+	// whole submission.
+	DialTimeout time.Duration
+}
+
+// withDefaults returns the options with the fields left at zero set to
+// their defaults.
+func (o ConnectionOptions) withDefaults() ConnectionOptions {
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = defaultMaxIdleConns
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = defaultDialTimeout
+	}
+	return o
+}
+
+// Connections is a chainable option-setting method to tune the connections
+// reports are posted over, e.g. to keep more idle connections when many
+// errors are reported concurrently. The settings apply to a transport built
+// once for the client, which also uses the settings of Proxy and TLSConfig,
+// and is shared with its clones. An HTTP client set with HTTPClient or a
+// relay socket take precedence.
+func (c *Client) Connections(opts ConnectionOptions) *Client {
+	if c == nil {
+		return nil
+	}
+	opts = opts.withDefaults()
+	c.connections = &opts
+	c.updateTransport()
+	return c
+}
+
+// applyConnectionOptions sets up the given transport with the connection
+// options of the client, if any.
+func (c *Client) applyConnectionOptions(transport *http.Transport) {
+	if c.connections == nil {
+		return
+	}
+	opts := c.connections
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConns
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	dialer := &net.Dialer{Timeout: opts.DialTimeout, KeepAlive: defaultKeepAlive}
+	transport.DialContext = dialer.DialContext
+}
@@ -0,0 +1,103 @@
+package raygun4go
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConnections(t *testing.T) {
+	Convey("Connections", t, func() {
+		var connections int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&connections, 1)
+			}
+		}
+		server.Start()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		transport := func() *http.Transport {
+			return c.postClient().Transport.(*http.Transport)
+		}
+		load := func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						c.SendError(errors.New("Test Connections"))
+					}
+				}()
+			}
+			wg.Wait()
+		}
+
+		Convey("are reflected by the transport", func() {
+			c.Connections(ConnectionOptions{
+				MaxIdleConns:      20,
+				IdleConnTimeout:   time.Minute,
+				DisableKeepAlives: true,
+				DialTimeout:       5 * time.Second,
+			})
+			So(transport().MaxIdleConns, ShouldEqual, 20)
+			So(transport().MaxIdleConnsPerHost, ShouldEqual, 20)
+			So(transport().IdleConnTimeout, ShouldEqual, time.Minute)
+			So(transport().DisableKeepAlives, ShouldBeTrue)
+			So(transport().DialContext, ShouldNotBeNil)
+			So(c.connections.DialTimeout, ShouldEqual, 5*time.Second)
+		})
+
+		Convey("default to the settings of http.DefaultTransport", func() {
+			c.Connections(ConnectionOptions{})
+			So(transport().MaxIdleConns, ShouldEqual, 100)
+			So(transport().MaxIdleConnsPerHost, ShouldEqual, 100)
+			So(transport().IdleConnTimeout, ShouldEqual, 90*time.Second)
+			So(transport().DisableKeepAlives, ShouldBeFalse)
+			So(*c.connections, ShouldResemble, ConnectionOptions{
+				MaxIdleConns:    100,
+				IdleConnTimeout: 90 * time.Second,
+				DialTimeout:     30 * time.Second,
+			})
+		})
+
+		Convey("build the transport once", func() {
+			c.Connections(ConnectionOptions{MaxIdleConns: 8})
+			So(c.postClient(), ShouldEqual, c.postClient())
+			So(c.Clone().postClient(), ShouldEqual, c.postClient())
+		})
+
+		Convey("are combined with the TLS configuration", func() {
+			config := &tls.Config{ServerName: "raygun"}
+			c.Connections(ConnectionOptions{MaxIdleConns: 8}).TLSConfig(config)
+			So(transport().MaxIdleConns, ShouldEqual, 8)
+			So(transport().TLSClientConfig.ServerName, ShouldEqual, "raygun")
+		})
+
+		Convey("reuse connections under load", func() {
+			c.Connections(ConnectionOptions{MaxIdleConns: 8})
+			load()
+			So(atomic.LoadInt32(&connections), ShouldBeLessThanOrEqualTo, 16)
+		})
+
+		Convey("open a connection per report without keep-alives", func() {
+			c.Connections(ConnectionOptions{DisableKeepAlives: true})
+			load()
+			So(atomic.LoadInt32(&connections), ShouldEqual, 80)
+		})
+	})
+}
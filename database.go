@@ -0,0 +1,130 @@
+package raygun4go
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// databaseTag is the tag DatabaseBreadcrumb adds to reports following a
+// failed query.
+const databaseTag = "database"
+
+// placeholderList matches lists of placeholders like "(?, ?, ?)".
+var placeholderList = regexp.MustCompile(`\( ?\?(?: ?, ?\?)* ?\)`)
+
+// DatabaseBreadcrumb records a breadcrumb for a database query on the client
+// found in ctx, e.g. the scoped client of the middleware. It does nothing if
+// there is none. The query is recorded as scrubbed by ScrubSQL. If err is not
+// nil, the breadcrumb is recorded at BreadcrumbError level and the following
+// reports of the client are tagged "database".
+func DatabaseBreadcrumb(ctx context.Context, query string, d time.Duration, err error) {
+	c := FromContext(ctx)
+	if c == nil {
+		return
+	}
+
+	b := Breadcrumb{
+		Level:      BreadcrumbInfo,
+		Category:   "database",
+		Message:    ScrubSQL(query),
+		CustomData: map[string]interface{}{"durationMs": d.Milliseconds()},
+	}
+	if err != nil {
+		b.Level = BreadcrumbError
+		b.CustomData["error"] = c.redaction.redactValue(err.Error())
+		c.breadcrumbs.tag(databaseTag)
+	}
+	c.RecordBreadcrumb(b)
+}
+
+// ScrubSQL replaces the literals in the given query with "?", so it contains
+// no data and equal statements scrub to equal strings. Single quoted strings
+// and numbers are replaced, comments are removed, whitespace is collapsed and
+// lists of placeholders such as "IN (?, ?)" are shortened to "(?)".
+//
+// ScrubSQL is not a SQL parser. Identifiers in double quotes or backticks are
+// kept as they are, but other dialect specific literals such as dollar quoted
+// strings in PostgreSQL or double quoted strings in MySQL are not recognized.
+func ScrubSQL(query string) string {
+	var b strings.Builder
+	runes := []rune(query)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			space = true
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case r == '\'':
+			i = skipQuoted(runes, i, '\'')
+			b.WriteByte('?')
+		case r == '"' || r == '`':
+			end := skipQuoted(runes, i, r)
+			b.WriteString(string(runes[i : end+1]))
+			i = end
+		case isDigit(r) && (i == 0 || !isIdentifierRune(runes[i-1])):
+			for i+1 < len(runes) && (isIdentifierRune(runes[i+1]) || runes[i+1] == '.' ||
+				((runes[i+1] == '+' || runes[i+1] == '-') && (runes[i] == 'e' || runes[i] == 'E'))) {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return placeholderList.ReplaceAllString(b.String(), "(?)")
+}
+
+// skipQuoted returns the index of the quote closing the quoted section
+// starting at runes[start]. Doubled quotes and backslash escapes are part of
+// the section. It returns the last index if the section is not closed.
+func skipQuoted(runes []rune, start int, quote rune) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(runes) - 1
+}
+
+// isDigit reports whether r is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isIdentifierRune reports whether r may be part of an identifier or a
+// placeholder such as $1.
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '$' || isDigit(r) || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 127
+}
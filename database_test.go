@@ -0,0 +1,86 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrubSQL(t *testing.T) {
+	Convey("ScrubSQL", t, func() {
+		Convey("replaces string and numeric literals", func() {
+			So(ScrubSQL("SELECT * FROM users WHERE email = 'jane@example.com' AND age > 42"),
+				ShouldEqual, "SELECT * FROM users WHERE email = ? AND age > ?")
+			So(ScrubSQL("INSERT INTO orders (id, total, note) VALUES (17, 12.50, 'it''s \\'urgent\\'')"),
+				ShouldEqual, "INSERT INTO orders (id, total, note) VALUES (?)")
+			So(ScrubSQL("SELECT 1.5e-3, 0x1F, -7"), ShouldEqual, "SELECT ?, ?, -?")
+		})
+
+		Convey("keeps identifiers and placeholders", func() {
+			So(ScrubSQL(`SELECT t1.col2, "Table 3"."x 4", `+"`y'5`"+` FROM t1 WHERE id = $1 AND name = ?`),
+				ShouldEqual, `SELECT t1.col2, "Table 3"."x 4", `+"`y'5`"+` FROM t1 WHERE id = $1 AND name = ?`)
+		})
+
+		Convey("collapses lists of placeholders", func() {
+			So(ScrubSQL("SELECT * FROM users WHERE id IN (1, 2, 3)"), ShouldEqual, ScrubSQL("SELECT * FROM users WHERE id IN (4,5)"))
+			So(ScrubSQL("SELECT * FROM users WHERE id IN (1, 2, 3)"), ShouldEqual, "SELECT * FROM users WHERE id IN (?)")
+		})
+
+		Convey("removes comments and collapses whitespace", func() {
+			So(ScrubSQL("SELECT a -- the 'a' column\n\tFROM  b /* with 5 rows */ WHERE c = 1"),
+				ShouldEqual, "SELECT a FROM b WHERE c = ?")
+		})
+
+		Convey("copes with unterminated literals", func() {
+			So(ScrubSQL("SELECT 'abc"), ShouldEqual, "SELECT ?")
+			So(ScrubSQL(`SELECT "abc`), ShouldEqual, `SELECT "abc`)
+			So(ScrubSQL("SELECT a /* b"), ShouldEqual, "SELECT a")
+		})
+	})
+}
+
+func TestDatabaseBreadcrumb(t *testing.T) {
+	Convey("DatabaseBreadcrumb", t, func() {
+		c, _ := New("app", "key")
+		c.Tags([]string{"api"})
+		ctx := NewContext(context.Background(), c)
+		post := func() PostData {
+			return c.createPost(errors.New("Test DatabaseBreadcrumb"), StackTrace{})
+		}
+
+		Convey("records the scrubbed query", func() {
+			DatabaseBreadcrumb(ctx, "SELECT * FROM users WHERE id = 42", 3*time.Millisecond, nil)
+
+			crumbs := post().Details.Breadcrumbs
+			So(len(crumbs), ShouldEqual, 1)
+			So(crumbs[0].Level, ShouldEqual, BreadcrumbInfo)
+			So(crumbs[0].Category, ShouldEqual, "database")
+			So(crumbs[0].Message, ShouldEqual, "SELECT * FROM users WHERE id = ?")
+			So(crumbs[0].CustomData, ShouldResemble, map[string]interface{}{"durationMs": int64(3)})
+			So(post().Details.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("tags the following reports after errors", func() {
+			DatabaseBreadcrumb(ctx, "UPDATE users SET name = 'x'", time.Millisecond, errors.New("deadlock detected"))
+			DatabaseBreadcrumb(ctx, "UPDATE users SET name = 'y'", time.Millisecond, errors.New("deadlock detected"))
+
+			crumbs := post().Details.Breadcrumbs
+			So(crumbs[0].Level, ShouldEqual, BreadcrumbError)
+			So(crumbs[0].CustomData["error"], ShouldEqual, "deadlock detected")
+			So(post().Details.Tags, ShouldResemble, []string{"api", "database"})
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+
+			c.ClearBreadcrumbs()
+			So(post().Details.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("does nothing without a client in the context", func() {
+			So(func() {
+				DatabaseBreadcrumb(context.Background(), "SELECT 1", time.Millisecond, errors.New("failed"))
+			}, ShouldNotPanic)
+		})
+	})
+}
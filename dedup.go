@@ -0,0 +1,205 @@
+package raygun4go
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dedupFingerprintFrames is the number of top stack frames that are part of
+// the fingerprint used for deduplication.
+const dedupFingerprintFrames = 5
+
+// dedupMaxUsers is the maximum number of distinct users tracked per
+// fingerprint. Beyond it, the reported count is a lower bound.
+const dedupMaxUsers = 1000
+
+// dedupEntry holds the bookkeeping for one fingerprint.
+type dedupEntry struct {
+	expires    time.Time           // the end of the current window
+	suppressed int                 // the number of occurrences suppressed in the window
+	users      map[string]struct{} // the distinct identified users of the suppressed occurrences
+	last       PostData            // the last suppressed occurrence, sent as summary on expiry
+}
+
+// summarize adds the number of suppressed occurrences and their distinct
+// users to the custom data of the given post.
+func (e *dedupEntry) summarize(post *PostData) {
+	post.Details.UserCustomData = mergeCustomData(post.Details.UserCustomData, map[string]interface{}{
+		suppressedCountKey: e.suppressed,
+		"distinctUsers":    len(e.users),
+	})
+}
+
+// deduplicator suppresses reports with identical fingerprints within a time
+// window. It is shared by a client and all its clones.
+type deduplicator struct {
+	mu          sync.Mutex
+	window      time.Duration
+	now         func() time.Time
+	fingerprint func(PostData) string // identifies the error of a post, see DedupFingerprint
+	entries     map[string]*dedupEntry
+}
+
+// newDeduplicator returns a deduplicator using the given window.
+func newDeduplicator(window time.Duration) *deduplicator {
+	return &deduplicator{
+		window:      window,
+		now:         time.Now,
+		fingerprint: dedupFingerprint,
+		entries:     make(map[string]*dedupEntry),
+	}
+}
+
+// Deduplicate is a chainable option-setting method to suppress repeated
+// reports of an error within the given window. The next report of it sent
+// afterwards carries the number suppressed, see DedupFingerprint. Flush and
+// Close send the numbers of the windows not reported yet with the last
+// suppressed occurrence. A non-positive window disables it, which is the
+// default.
+func (c *Client) Deduplicate(window time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	c.dedup = nil
+	if window > 0 {
+		c.dedup = newDeduplicator(window)
+		c.dedup.now = c.clock
+		if c.dedupKey != nil {
+			c.dedup.fingerprint = c.dedupKey
+		}
+	}
+	return c
+}
+
+// DedupFingerprint is a chainable option-setting method to set the function
+// identifying the error of a report for Deduplicate. By default, errors are
+// identified by their message, top 5 stack frames and grouping key. Passing
+// nil restores the default.
+func (c *Client) DedupFingerprint(fn func(PostData) string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.dedupKey = fn
+	if fn == nil {
+		fn = dedupFingerprint
+	}
+	c.dedup.setFingerprint(fn)
+	return c
+}
+
+// setFingerprint sets the function identifying the error of a post.
+func (d *deduplicator) setFingerprint(fn func(PostData) string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fingerprint = fn
+}
+
+// admit reports whether the given post should be sent. Posts that are sent
+// after occurrences of the same error were suppressed carry the number of
+// suppressed occurrences and their distinct users in their custom data. It
+// also returns a summary of the windows of other errors that expired with
+// suppressed occurrences, which have to be sent as well: the last suppressed
+// occurrence of each, carrying the same numbers.
+func (d *deduplicator) admit(post *PostData) (bool, []PostData) {
+	user := post.Details.User.Identifier
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fingerprint := d.fingerprint(*post)
+	now := d.now()
+	entry, ok := d.entries[fingerprint]
+	if ok && now.Before(entry.expires) {
+		entry.suppressed++
+		if user != "" && len(entry.users) < dedupMaxUsers {
+			entry.users[user] = struct{}{}
+		}
+		entry.last = *post
+		return false, nil
+	}
+
+	if ok && entry.suppressed > 0 {
+		entry.summarize(post)
+	}
+
+	delete(d.entries, fingerprint)
+	summaries := d.prune(now)
+	d.entries[fingerprint] = &dedupEntry{
+		expires: now.Add(d.window),
+		users:   make(map[string]struct{}),
+	}
+	return true, summaries
+}
+
+// prune removes all entries whose window has expired and returns the
+// summaries of the ones that suppressed occurrences.
+func (d *deduplicator) prune(now time.Time) []PostData {
+	var summaries []PostData
+	for fingerprint, entry := range d.entries {
+		if now.Before(entry.expires) {
+			continue
+		}
+		if entry.suppressed > 0 {
+			summary := entry.last
+			entry.summarize(&summary)
+			summaries = append(summaries, summary)
+		}
+		delete(d.entries, fingerprint)
+	}
+	return summaries
+}
+
+// flush returns the summaries of all windows with suppressed occurrences not
+// reported yet, expired or not, and resets their counts, so that a burst
+// followed by silence is not lost. Expired windows are removed.
+func (d *deduplicator) flush() []PostData {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summaries := d.prune(d.now())
+	for _, entry := range d.entries {
+		if entry.suppressed == 0 {
+			continue
+		}
+		summary := entry.last
+		entry.summarize(&summary)
+		summaries = append(summaries, summary)
+		entry.suppressed, entry.users, entry.last = 0, make(map[string]struct{}), PostData{}
+	}
+	return summaries
+}
+
+// sendDedupSummaries sends the given summaries of suppressed occurrences.
+func (c *Client) sendDedupSummaries(ctx context.Context, summaries []PostData) {
+	for _, summary := range summaries {
+		if err := c.dispatch(ctx, summary, true); err != nil {
+			c.errorf("Unable to send %s\n%s", summary.Summary(), err.Error())
+		}
+	}
+}
+
+// dedupFingerprint identifies a post by its error message, top stack frames
+// and grouping key.
+func dedupFingerprint(post PostData) string {
+	h := sha1.New()
+	fmt.Fprintln(h, post.Details.Error.Message)
+	for i, frame := range post.Details.Error.StackTrace {
+		if i == dedupFingerprintFrames {
+			break
+		}
+		fmt.Fprintln(h, frame.PackageName, trimArguments(frame.MethodName), frame.FileName, frame.LineNumber)
+	}
+	if post.Details.GroupingKey != nil {
+		fmt.Fprintln(h, *post.Details.GroupingKey)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,217 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeduplicator(t *testing.T) {
+	Convey("Deduplicator", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		c, _ := New("app", "key")
+		c.dedup = newDeduplicator(time.Minute)
+		c.dedup.now = func() time.Time { return now }
+
+		send := func(user, message string) {
+			c.User(user)
+			So(c.Submit(c.createPost(errors.New(message), StackTrace{})), ShouldBeNil)
+		}
+
+		Convey("suppresses repeated errors within the window", func() {
+			send("a", "Test dedup")
+			send("a", "Test dedup")
+			send("b", "Test dedup")
+			send("c", "Test dedup")
+			send("c", "Test dedup")
+			send("a", "Test other error")
+
+			first, _ := server.next()
+			So(first.Details.Error.Message, ShouldEqual, "Test dedup")
+			second, _ := server.next()
+			So(second.Details.Error.Message, ShouldEqual, "Test other error")
+			So(len(server.posts), ShouldEqual, 0)
+
+			Convey("and reports their count and distinct users after it", func() {
+				now = now.Add(time.Minute)
+				send("d", "Test dedup")
+
+				post, ok := server.next()
+				So(ok, ShouldBeTrue)
+				data := post.Details.UserCustomData.(map[string]interface{})
+				So(data[suppressedCountKey], ShouldEqual, 4)
+				So(data["distinctUsers"], ShouldEqual, 3)
+			})
+		})
+
+		Convey("does not count anonymous users", func() {
+			send("", "Test dedup")
+			send("", "Test dedup")
+			send("", "Test dedup")
+			server.next()
+
+			now = now.Add(time.Minute)
+			send("", "Test dedup")
+			post, _ := server.next()
+			data := post.Details.UserCustomData.(map[string]interface{})
+			So(data[suppressedCountKey], ShouldEqual, 2)
+			So(data["distinctUsers"], ShouldEqual, 0)
+		})
+
+		Convey("sends the summary of expired windows and forgets them", func() {
+			send("a", "Test dedup")
+			send("b", "Test dedup")
+			send("c", "Test dedup")
+			server.next()
+
+			now = now.Add(time.Minute)
+			send("a", "Test other error")
+			summary, _ := server.next()
+			So(summary.Details.Error.Message, ShouldEqual, "Test dedup")
+			data := summary.Details.UserCustomData.(map[string]interface{})
+			So(data[suppressedCountKey], ShouldEqual, 2)
+			So(data["distinctUsers"], ShouldEqual, 2)
+			other, _ := server.next()
+			So(other.Details.Error.Message, ShouldEqual, "Test other error")
+			So(c.dedup.entries, ShouldHaveLength, 1)
+		})
+
+		Convey("sends the summaries of a burst on Close", func() {
+			send("a", "Test dedup")
+			send("b", "Test dedup")
+			send("c", "Test dedup")
+			send("a", "Test other error")
+			server.next()
+			server.next()
+
+			So(c.Close(), ShouldBeNil)
+			summary, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(summary.Details.Error.Message, ShouldEqual, "Test dedup")
+			data := summary.Details.UserCustomData.(map[string]interface{})
+			So(data[suppressedCountKey], ShouldEqual, 2)
+			So(data["distinctUsers"], ShouldEqual, 2)
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("sends the summaries of a burst on Flush and keeps the window", func() {
+			send("a", "Test dedup")
+			send("b", "Test dedup")
+			server.next()
+
+			So(c.Flush(context.Background()), ShouldBeNil)
+			summary, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(summary.Details.UserCustomData.(map[string]interface{})[suppressedCountKey], ShouldEqual, 1)
+
+			send("c", "Test dedup")
+			So(len(server.posts), ShouldEqual, 0)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			summary, _ = server.next()
+			So(summary.Details.UserCustomData.(map[string]interface{})[suppressedCountKey], ShouldEqual, 1)
+
+			now = now.Add(time.Minute)
+			send("d", "Test dedup")
+			post, _ := server.next()
+			So(post.Details.UserCustomData, ShouldNotContainKey, suppressedCountKey)
+		})
+
+		Convey("is shared with clones", func() {
+			send("a", "Test dedup")
+			So(c.Clone().Submit(c.createPost(errors.New("Test dedup"), StackTrace{})), ShouldBeNil)
+
+			server.next()
+			So(len(server.posts), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestDeduplicate(t *testing.T) {
+	Convey("#Deduplicate", t, func() {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		transport := NewRecordingTransport()
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return now }
+		c.Transport(transport).Deduplicate(time.Minute)
+		send := func(message string, st StackTrace) {
+			So(c.Submit(c.createPost(errors.New(message), st)), ShouldBeNil)
+		}
+		suppressed := func(post PostData) interface{} {
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			return data[suppressedCountKey]
+		}
+		frame := func(line int) StackTrace {
+			return StackTrace{{LineNumber: line, PackageName: "main", FileName: "main.go", MethodName: "main"}}
+		}
+
+		Convey("suppresses repeats within the window", func() {
+			for i := 0; i < 5; i++ {
+				send("Test Deduplicate", frame(1))
+			}
+			So(transport.Posts(), ShouldHaveLength, 1)
+			So(suppressed(transport.Posts()[0]), ShouldBeNil)
+
+			Convey("and counts them in the next report after it", func() {
+				now = now.Add(59 * time.Second)
+				send("Test Deduplicate", frame(1))
+				So(transport.Posts(), ShouldHaveLength, 1)
+
+				now = now.Add(time.Second)
+				send("Test Deduplicate", frame(1))
+				So(transport.Posts(), ShouldHaveLength, 2)
+				So(suppressed(transport.Posts()[1]), ShouldEqual, 5)
+
+				send("Test Deduplicate", frame(1))
+				So(transport.Posts(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("tells errors apart by message, stack and grouping key", func() {
+			send("Test Deduplicate", frame(1))
+			send("Test Deduplicate", frame(2))
+			send("Test other error", frame(1))
+			post := c.createPost(errors.New("Test Deduplicate"), frame(1))
+			key := "grouped"
+			post.Details.GroupingKey = &key
+			So(c.Submit(post), ShouldBeNil)
+			So(transport.Posts(), ShouldHaveLength, 4)
+		})
+
+		Convey("uses the fingerprint function set with DedupFingerprint", func() {
+			c.DedupFingerprint(func(post PostData) string { return "all the same" })
+			send("Test Deduplicate", frame(1))
+			send("Test other error", frame(2))
+			So(transport.Posts(), ShouldHaveLength, 1)
+
+			c.DedupFingerprint(nil)
+			send("Test other error", frame(2))
+			So(transport.Posts(), ShouldHaveLength, 2)
+		})
+
+		Convey("keeps the fingerprint function when the window changes", func() {
+			c.DedupFingerprint(func(post PostData) string { return "all the same" }).Deduplicate(time.Hour)
+			send("Test Deduplicate", frame(1))
+			send("Test other error", frame(2))
+			So(transport.Posts(), ShouldHaveLength, 1)
+		})
+
+		Convey("is disabled by a non-positive window", func() {
+			c.Deduplicate(0)
+			send("Test Deduplicate", frame(1))
+			send("Test Deduplicate", frame(1))
+			So(transport.Posts(), ShouldHaveLength, 2)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.Deduplicate(time.Minute), ShouldBeNil)
+			So(nilClient.DedupFingerprint(nil), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package raygun4go
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// DeterministicEncoding is a chainable option-setting method to make the
+// encoded reports of a silent client byte-identical for identical input, e.g.
+// for golden-file tests, see Silent. encoding/json already sorts map keys and
+// the tags mapped from request headers are sorted as well; this also makes
+// the reports occur at 2000-01-01T00:00:00Z and derives their id from their
+// error and stack trace, which otherwise differ for every report. It has no
+// effect on clients sending to Raygun and is off by default.
+func (c *Client) DeterministicEncoding(deterministic bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.deterministic = deterministic
+	return c
+}
+
+// deterministicReports reports whether the reports of the client get a fixed
+// occurredOn and an id derived from their error, see DeterministicEncoding.
+func (c *Client) deterministicReports() bool {
+	return c.deterministic && c.silent
+}
+
+// deterministicReportID returns an id derived from the error and stack trace
+// of the given post.
+func deterministicReportID(post PostData) string {
+	data, _ := json.Marshal(post.Details.Error)
+	return uuid.NewSHA1(uuid.NameSpaceOID, data).String()
+}
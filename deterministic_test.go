@@ -0,0 +1,71 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeterministicEncoding(t *testing.T) {
+	Convey("#DeterministicEncoding", t, func() {
+		c, _ := New("app", "key")
+		c.Silent(true)
+		r := httptest.NewRequest("GET", "/orders", nil)
+		mapping := map[string]string{}
+		customData := map[string]interface{}{}
+		for i := 0; i < 25; i++ {
+			header := fmt.Sprintf("X-Header-%d", i)
+			r.Header.Set(header, "value")
+			mapping[header] = fmt.Sprintf("tag%d", i)
+			customData[fmt.Sprintf("key%d", i)] = i
+		}
+		c.Request(r).CustomData(customData).HeaderTagMapping(mapping)
+		encode := func(message string) string {
+			post := c.createPost(errors.New(message), StackTrace{})
+			enc, _ := json.Marshal(post)
+			return string(enc)
+		}
+
+		Convey("encodes identical reports to identical bytes", func() {
+			c.DeterministicEncoding(true)
+			first := encode("Test deterministic encoding")
+			for i := 0; i < 10; i++ {
+				So(encode("Test deterministic encoding"), ShouldEqual, first)
+			}
+		})
+
+		Convey("fixes the time and derives the id from the error", func() {
+			c.DeterministicEncoding(true)
+			post := c.createPost(errors.New("Test deterministic encoding"), StackTrace{})
+			So(post.OccuredOn, ShouldEqual, "2000-01-01T00:00:00Z")
+			So(post.ReportID(), ShouldEqual, c.createPost(errors.New("Test deterministic encoding"), StackTrace{}).ReportID())
+			So(post.ReportID(), ShouldNotEqual, c.createPost(errors.New("Test other error"), StackTrace{}).ReportID())
+		})
+
+		Convey("applies to silent clients only", func() {
+			c.DeterministicEncoding(true).Silent(false)
+			post := c.createPost(errors.New("Test deterministic encoding"), StackTrace{})
+			So(post.OccuredOn, ShouldNotEqual, "2000-01-01T00:00:00Z")
+		})
+
+		Convey("is not needed to sort the tags mapped from headers", func() {
+			tags := c.createPost(errors.New("Test deterministic encoding"), StackTrace{}).Details.Tags
+			So(sort.StringsAreSorted(tags), ShouldBeTrue)
+			So(tags, ShouldHaveLength, 25)
+		})
+
+		Convey("is kept by clones", func() {
+			So(c.DeterministicEncoding(true).Clone().deterministic, ShouldBeTrue)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.DeterministicEncoding(true), ShouldBeNil)
+		})
+	})
+}
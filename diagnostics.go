@@ -0,0 +1,203 @@
+package raygun4go
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// The phases of a submission, as reported in SubmissionPhases.Failed.
+const (
+	PhaseDNS      = "dns"      // resolving the Raygun host name
+	PhaseConnect  = "connect"  // establishing the TCP connection
+	PhaseTLS      = "tls"      // the TLS handshake
+	PhaseRequest  = "request"  // writing the request
+	PhaseResponse = "response" // waiting for the response
+)
+
+// SubmissionPhases holds the durations of the connection phases of a
+// submission and the phase it failed in. Phases that did not happen, e.g.
+// because a connection was reused, have a zero duration.
+type SubmissionPhases struct {
+	DNS     time.Duration // the time spent resolving the host name
+	Connect time.Duration // the time spent establishing the connection
+	TLS     time.Duration // the time spent on the TLS handshake
+	Failed  string        // the phase the submission failed in, one of the Phase constants
+}
+
+// String returns a compact representation for logging.
+func (p SubmissionPhases) String() string {
+	return fmt.Sprintf("dns=%s connect=%s tls=%s failed=%s", p.DNS, p.Connect, p.TLS, p.Failed)
+}
+
+// SubmitError is returned if a report could not be delivered to Raygun
+// because the request failed or Raygun answered with an unexpected status.
+// Use IsInvalidAPIKey and IsRateLimited to tell common causes apart.
+type SubmitError struct {
+	Err    error             // the underlying error, nil if Raygun answered
+	Phases *SubmissionPhases // the connection phases, only set with diagnostics enabled
+	Socket string            // the relay socket the request was sent through, see RelaySocket
+
+	Timeout  bool // if true, the request timed out, e.g. because of the HTTP client timeout or a context deadline
+	Canceled bool // if true, the request was canceled, e.g. because its context was
+
+	StatusCode int    // the status Raygun answered with, 0 if the request failed
+	Body       string // the start of the response body, often explaining the status
+}
+
+// Error returns the message of the failed request or the unexpected answer.
+func (e *SubmitError) Error() string {
+	switch {
+	case e.StatusCode != 0:
+		return fmt.Sprintf("Unexpected answer from Raygun %d", e.StatusCode)
+	case e.Socket != "":
+		return fmt.Sprintf("Failed to request through %s (%s)", e.Socket, e.Err.Error())
+	default:
+		return fmt.Sprintf("Failed to request (%s)", e.Err.Error())
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *SubmitError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrSubmission, or the category of the answer
+// of Raygun: ErrInvalidAPIKey for 401 and 403, ErrPayloadTooLarge for 413.
+func (e *SubmitError) Is(target error) bool {
+	switch target {
+	case ErrSubmission:
+		return true
+	case ErrInvalidAPIKey:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// IsInvalidAPIKey reports whether err is or wraps a *SubmitError for a
+// submission Raygun rejected because of an unknown or missing API key, i.e.
+// errors.Is(err, ErrInvalidAPIKey).
+func IsInvalidAPIKey(err error) bool {
+	return errors.Is(err, ErrInvalidAPIKey)
+}
+
+// IsRateLimited reports whether err is or wraps a *RateLimitError, i.e. the
+// submission was rejected or held back because Raygun is rate limiting the
+// client.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// phaseTracer records the connection phases of a single submission.
+type phaseTracer struct {
+	mu       sync.Mutex
+	started  map[string]time.Time
+	finished map[string]bool
+	order    []string
+	failed   string
+	phases   SubmissionPhases
+}
+
+// newPhaseTracer returns an empty phaseTracer.
+func newPhaseTracer() *phaseTracer {
+	return &phaseTracer{started: make(map[string]time.Time), finished: make(map[string]bool)}
+}
+
+// withTrace returns a context that reports the connection phases to the
+// tracer.
+func (t *phaseTracer) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.start(PhaseDNS) },
+		DNSDone:  func(info httptrace.DNSDoneInfo) { t.done(PhaseDNS, info.Err) },
+		ConnectStart: func(string, string) {
+			t.start(PhaseConnect)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.done(PhaseConnect, err)
+		},
+		TLSHandshakeStart: func() { t.start(PhaseTLS) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.done(PhaseTLS, err)
+		},
+		GotConn: func(httptrace.GotConnInfo) { t.start(PhaseRequest) },
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			t.done(PhaseRequest, info.Err)
+			if info.Err == nil {
+				t.start(PhaseResponse)
+			}
+		},
+		GotFirstResponseByte: func() { t.done(PhaseResponse, nil) },
+	})
+}
+
+// start records the start of the given phase.
+func (t *phaseTracer) start(phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.started[phase]; !ok {
+		t.order = append(t.order, phase)
+	}
+	t.started[phase] = time.Now()
+}
+
+// done records the end of the given phase and whether it failed.
+func (t *phaseTracer) done(phase string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d := time.Since(t.started[phase])
+	switch phase {
+	case PhaseDNS:
+		t.phases.DNS = d
+	case PhaseConnect:
+		t.phases.Connect = d
+	case PhaseTLS:
+		t.phases.TLS = d
+	}
+
+	if err != nil {
+		if t.failed == "" {
+			t.failed = phase
+		}
+		return
+	}
+	t.finished[phase] = true
+}
+
+// result returns the recorded phases. If the submission failed without a
+// phase reporting an error, the last phase that did not finish is blamed.
+func (t *phaseTracer) result() *SubmissionPhases {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phases := t.phases
+	phases.Failed = t.failed
+	if phases.Failed == "" {
+		for i := len(t.order) - 1; i >= 0; i-- {
+			if !t.finished[t.order[i]] {
+				phases.Failed = t.order[i]
+				break
+			}
+		}
+	}
+	return &phases
+}
+
+// Diagnostics is a chainable option-setting method to record the connection
+// phases of submissions. If a submission fails, the returned SubmitError then
+// carries the durations of the phases and the phase that failed. Diagnostics
+// are also recorded when LogToStdOut is set.
+func (c *Client) Diagnostics(d bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.diagnostics = d
+	return c
+}
@@ -0,0 +1,145 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiagnostics(t *testing.T) {
+	Convey("Diagnostics", t, func() {
+		defaultEndpoint := raygunEndpoint
+		Reset(func() { raygunEndpoint = defaultEndpoint })
+
+		c, _ := New("app", "key")
+		c.Diagnostics(true)
+
+		submit := func() *SubmitError {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			err := c.submitCoreWithContext(ctx, PostData{})
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			return submitErr
+		}
+
+		Convey("identifies a failed connect", func() {
+			l, _ := net.Listen("tcp", "127.0.0.1:0")
+			raygunEndpoint = "http://" + l.Addr().String()
+			l.Close()
+
+			err := submit()
+			So(err.Phases, ShouldNotBeNil)
+			So(err.Phases.Failed, ShouldEqual, PhaseConnect)
+			So(err.Error(), ShouldStartWith, "Failed to request (")
+		})
+
+		Convey("identifies a failed TLS handshake", func() {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+			raygunEndpoint = server.URL
+
+			err := submit()
+			So(err.Phases, ShouldNotBeNil)
+			So(err.Phases.Failed, ShouldEqual, PhaseTLS)
+			So(err.Phases.Connect, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("identifies a failed name resolution", func() {
+			raygunEndpoint = "http://raygun4go.invalid"
+
+			err := submit()
+			So(err.Phases, ShouldNotBeNil)
+			So(err.Phases.Failed, ShouldEqual, PhaseDNS)
+		})
+
+		Convey("records nothing when disabled", func() {
+			l, _ := net.Listen("tcp", "127.0.0.1:0")
+			raygunEndpoint = "http://" + l.Addr().String()
+			l.Close()
+			c.Diagnostics(false)
+
+			err := submit()
+			So(err.Phases, ShouldBeNil)
+		})
+	})
+}
+
+func TestSubmitError(t *testing.T) {
+	Convey("SubmitError", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		submit := func() error {
+			return c.submitCoreWithContext(context.Background(), c.createPost(errors.New("Test SubmitError"), StackTrace{}))
+		}
+
+		Convey("holds the status and body of unexpected answers", func() {
+			server.Script(rayguntest.BadRequest(" Invalid payload: missing details \n"))
+			err := submit()
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(submitErr.Body, ShouldEqual, "Invalid payload: missing details")
+			So(submitErr.Err, ShouldBeNil)
+			So(err.Error(), ShouldEqual, "Unexpected answer from Raygun 400")
+			So(IsInvalidAPIKey(err), ShouldBeFalse)
+			So(IsRateLimited(err), ShouldBeFalse)
+		})
+
+		Convey("truncates long bodies", func() {
+			server.Script(rayguntest.BadRequest(strings.Repeat("x", 4*maxErrorBody)))
+			var submitErr *SubmitError
+			So(errors.As(submit(), &submitErr), ShouldBeTrue)
+			So(submitErr.Body, ShouldHaveLength, maxErrorBody)
+		})
+
+		Convey("identifies an invalid API key", func() {
+			server.Script(rayguntest.Unauthorized)
+			So(IsInvalidAPIKey(submit()), ShouldBeTrue)
+
+			c.Enabled(true).apiKey.set("")
+			So(IsInvalidAPIKey(submit()), ShouldBeTrue)
+		})
+
+		Convey("identifies rate limiting", func() {
+			server.Script(rayguntest.TooManyRequests("30"))
+			err := submit()
+			So(IsRateLimited(err), ShouldBeTrue)
+			So(IsInvalidAPIKey(err), ShouldBeFalse)
+			So(IsRateLimited(submit()), ShouldBeTrue)
+		})
+
+		Convey("identifies failed requests", func() {
+			server.Script(rayguntest.ConnectionReset)
+			err := submit()
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, 0)
+			So(submitErr.Err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "Failed to request (")
+			So(IsInvalidAPIKey(err), ShouldBeFalse)
+			So(IsRateLimited(err), ShouldBeFalse)
+		})
+
+		Convey("predicates handle other errors", func() {
+			So(IsInvalidAPIKey(nil), ShouldBeFalse)
+			So(IsRateLimited(errors.New("Test SubmitError")), ShouldBeFalse)
+			So(IsRateLimited(fmt.Errorf("wrapped: %w", &RateLimitError{RetryAfter: time.Second})), ShouldBeTrue)
+		})
+	})
+}
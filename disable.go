@@ -0,0 +1,88 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrClientDisabled is returned by submissions of a client that disabled
+// itself because Raygun rejected its API key, see Enabled.
+var ErrClientDisabled = errors.New("raygun4go client is disabled")
+
+// disabledFlag records whether a client is disabled. It is shared by a client
+// and all its clones, as they share the API key.
+type disabledFlag struct {
+	disabled int32 // 1 once Raygun rejected the API key
+	off      int32 // 1 while switched off by Enabled(false)
+}
+
+// get reports whether the client is disabled, for either reason.
+func (f *disabledFlag) get() bool {
+	return atomic.LoadInt32(&f.disabled) == 1 || f.isOff()
+}
+
+// set disables or enables the client because of its API key and reports
+// whether it was disabled for it before.
+func (f *disabledFlag) set(disabled bool) bool {
+	return atomic.SwapInt32(&f.disabled, flagValue(disabled)) == 1
+}
+
+// isOff reports whether the client was switched off by Enabled(false).
+func (f *disabledFlag) isOff() bool {
+	return atomic.LoadInt32(&f.off) == 1
+}
+
+// switchOff switches the client off or on, see Enabled.
+func (f *disabledFlag) switchOff(off bool) {
+	atomic.StoreInt32(&f.off, flagValue(off))
+}
+
+// flagValue returns the value of a flag stored as an int32.
+func flagValue(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Enabled is a chainable option-setting method to switch the client off or
+// on, e.g. from a feature flag while reports are being sent. Reports of a
+// client switched off by Enabled(false) are discarded before they are even
+// created: the sending methods return nil, HandleError still recovers panics
+// and only logs them if LogToStdOut is set, and queued reports are dropped
+// with ErrClientDisabled. Enabled(true) resumes the delivery.
+//
+// A client also disables itself when Raygun answers a submission with 401 or
+// 403, i.e. rejects its API key, so a wrong key does not cost a doomed
+// request per error. Its submissions then fail with ErrClientDisabled
+// without contacting Raygun until Enabled(true) is called, e.g. after the key
+// was fixed. Reports sent with another key chosen by the APIKeySelector do
+// not disable the client. The state is shared with all clones of the client.
+func (c *Client) Enabled(enabled bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.disabled.switchOff(!enabled)
+	if enabled {
+		c.disabled.set(false)
+	}
+	return c
+}
+
+// switchedOff reports whether the client was switched off by Enabled(false),
+// so reports are discarded before they are created.
+func (c *Client) switchedOff() bool {
+	return c.disabled.isOff()
+}
+
+// disableOnInvalidKey disables the client if the given error of a submission
+// sent with the given API key means Raygun rejected the key of the client,
+// and logs it the first time.
+func (c *Client) disableOnInvalidKey(err error, apiKey string) {
+	if !IsInvalidAPIKey(err) || apiKey != c.apiKey.get() {
+		return
+	}
+	if !c.disabled.set(true) {
+		c.errorf("Raygun rejected the API key (%s), disabling the client until Enabled(true) is called", err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEnabled(t *testing.T) {
+	Convey("Enabled", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("is disabled after Raygun rejects the API key", func() {
+			server.Script(rayguntest.Status(http.StatusForbidden))
+			So(IsInvalidAPIKey(c.SendError(errors.New("Test Enabled"))), ShouldBeTrue)
+			for i := 0; i < 10; i++ {
+				So(c.SendError(errors.New("Test Enabled")), ShouldEqual, ErrClientDisabled)
+			}
+			So(server.Count(), ShouldEqual, 1)
+
+			Convey("and enabled again by Enabled(true)", func() {
+				c.Enabled(true)
+				So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 2)
+			})
+
+			Convey("for all clones", func() {
+				So(c.Clone().SendError(errors.New("Test Enabled")), ShouldEqual, ErrClientDisabled)
+				So(server.Count(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("is disabled after a 401 as well", func() {
+			server.Script(rayguntest.Unauthorized)
+			c.SendError(errors.New("Test Enabled"))
+			So(c.SendError(errors.New("Test Enabled")), ShouldEqual, ErrClientDisabled)
+		})
+
+		Convey("stays enabled after other failures", func() {
+			server.Script(rayguntest.BadRequest("invalid report"), rayguntest.Status(http.StatusInternalServerError))
+			So(c.SendError(errors.New("Test Enabled")), ShouldNotBeNil)
+			So(c.SendError(errors.New("Test Enabled")), ShouldNotBeNil)
+			So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 3)
+		})
+
+		Convey("stays enabled if a key chosen by the APIKeySelector is rejected", func() {
+			c.APIKeySelector(func(PostData) string { return "other" })
+			server.Script(rayguntest.Unauthorized)
+			So(IsInvalidAPIKey(c.SendError(errors.New("Test Enabled"))), ShouldBeTrue)
+			So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+		})
+
+		Convey("can be switched off", func() {
+			c.Enabled(false)
+			So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+			So(c.CreateError("Test Enabled"), ShouldBeNil)
+			So(c.CreateErrorWithStackTrace("Test Enabled", StackTrace{}), ShouldBeNil)
+			So(c.Submit(PostData{}), ShouldBeNil)
+			So(c.SubmitBytes([]byte("{}")), ShouldBeNil)
+			So(<-c.SubmitAsync(PostData{}), ShouldBeNil)
+			So(c.Clone().SendError(errors.New("Test Enabled")), ShouldBeNil)
+			func() {
+				defer c.HandleError()
+				panic("Test Enabled")
+			}()
+			So(server.Count(), ShouldEqual, 0)
+			So(c.Stats(), ShouldResemble, Stats{})
+
+			Convey("and on again by Enabled(true)", func() {
+				c.Enabled(true)
+				So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("does not create reports while switched off", func() {
+			created := 0
+			c.CustomGroupingKeyFunction(func(error, PostData) string {
+				created++
+				return ""
+			})
+			c.Enabled(false)
+			So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+			So(created, ShouldEqual, 0)
+		})
+
+		Convey("drops queued reports while disabled", func() {
+			c.Asynchronous(true).Enabled(false)
+			So(c.SendError(errors.New("Test Enabled")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			_, received := server.WaitForRequests(1, 50*time.Millisecond)
+			So(received, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package raygun4go
+
+import "time"
+
+// drainPolicy controls how the offline storage delivers its reports, see
+// DrainPolicy.
+type drainPolicy struct {
+	interval  time.Duration                   // the pause between batches
+	batchSize int                             // the reports delivered per batch, all if 0
+	backoff   func(attempt int) time.Duration // the delay after a failed drain, the default if nil
+}
+
+// DrainPolicy is a chainable option-setting method to control how the
+// offline storage delivers its reports once Raygun is reachable: at most
+// batchSize reports at a time, all of them if batchSize is 0 or less, with a
+// pause of interval between batches. After a drain failed, the storage waits
+// backoff(attempt) before the next one, where attempt counts the consecutive
+// failures from 0; nil restores the default of ExponentialBackoff(10s, 10m).
+// A new policy takes effect with the next batch. It has no effect without
+// OfflineStorage, and is overridden by the probe interval of
+// OfflineDetection while the client is offline.
+//
+//	// Drain slowly on metered connections.
+//	raygun.DrainPolicy(time.Minute, 10, raygun4go.ExponentialBackoff(time.Minute, time.Hour))
+func (c *Client) DrainPolicy(interval time.Duration, batchSize int, backoff func(attempt int) time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	if batchSize < 0 {
+		batchSize = 0
+	}
+	c.drain = drainPolicy{interval: interval, batchSize: batchSize, backoff: backoff}
+	c.offline.setDrainPolicy(c.drain)
+	return c
+}
+
+// ExponentialBackoff returns a backoff function for DrainPolicy that doubles
+// the delay after every failed attempt, starting at base and capped at maxDelay.
+// Each delay is randomized between half and all of it, so that clients
+// failing together do not retry together.
+func ExponentialBackoff(base, maxDelay time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := backoff(base, attempt)
+		if delay > maxDelay || delay <= 0 {
+			delay = maxDelay
+		}
+		return delay
+	}
+}
+
+// setDrainPolicy replaces the drain policy of the store and wakes its loop,
+// so that a pause of the old policy does not delay the new one.
+func (s *offlineStore) setDrainPolicy(policy drainPolicy) {
+	if s == nil {
+		return
+	}
+	s.stateMu.Lock()
+	s.policy = policy
+	s.stateMu.Unlock()
+
+	select {
+	case s.reschedule <- struct{}{}:
+	default:
+	}
+}
+
+// drainPolicy returns the drain policy of the store, with the default
+// backoff if it has none.
+func (s *offlineStore) drainPolicy() drainPolicy {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	policy := s.policy
+	if policy.backoff == nil {
+		policy.backoff = ExponentialBackoff(s.retryDelay, maxOfflineRetryDelay)
+	}
+	return policy
+}
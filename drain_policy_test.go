@@ -0,0 +1,115 @@
+package raygun4go
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	Convey("ExponentialBackoff", t, func() {
+		backoff := ExponentialBackoff(time.Second, time.Minute)
+
+		Convey("doubles the delay with jitter", func() {
+			for attempt, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second} {
+				for i := 0; i < 10; i++ {
+					d := backoff(attempt)
+					So(d, ShouldBeBetweenOrEqual, delay/2, delay)
+				}
+			}
+		})
+
+		Convey("caps the delay", func() {
+			So(backoff(7), ShouldEqual, time.Minute)
+			So(backoff(100), ShouldEqual, time.Minute)
+		})
+	})
+}
+
+func TestDrainPolicy(t *testing.T) {
+	Convey("#DrainPolicy", t, func() {
+		server := rayguntest.New()
+		dir, _ := os.MkdirTemp("", "raygun4go")
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Retries(0, 0)
+		Reset(func() {
+			c.Close()
+			server.Close()
+			os.RemoveAll(dir)
+		})
+
+		stored := func() int {
+			files, _ := storedReportFiles(dir)
+			return len(files)
+		}
+		persist := func(n int) {
+			var reports []queuedReport
+			for i := 0; i < n; i++ {
+				reports = append(reports, queuedReport{post: c.createPost(fmt.Errorf("Test DrainPolicy %d", i), StackTrace{})})
+			}
+			So(persistReports(dir, reports), ShouldBeNil)
+		}
+
+		Convey("backs off with the given function after failed drains", func() {
+			var mu sync.Mutex
+			var attempts []int
+			recorded := func() []int {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]int(nil), attempts...)
+			}
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			persist(1)
+			c.DrainPolicy(0, 0, func(attempt int) time.Duration {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts = append(attempts, attempt)
+				return time.Millisecond
+			})
+			c.offline = newOfflineStore(c, dir, 10, time.Hour)
+
+			So(eventually(func() bool { return len(recorded()) >= 3 }), ShouldBeTrue)
+			So(recorded()[:3], ShouldResemble, []int{0, 1, 2})
+
+			server.Fallback(rayguntest.Accepted)
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+
+			mu.Lock()
+			attempts = nil
+			mu.Unlock()
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			persist(1)
+			c.offline.store(nil)
+			So(eventually(func() bool { return len(recorded()) >= 1 }), ShouldBeTrue)
+			So(recorded()[0], ShouldEqual, 0)
+		})
+
+		Convey("delivers the reports in batches", func() {
+			persist(5)
+			c.DrainPolicy(time.Hour, 2, nil)
+			c.offline = newOfflineStore(c, dir, 10, time.Hour)
+
+			So(eventually(func() bool { return server.Count() == 2 }), ShouldBeTrue)
+			time.Sleep(50 * time.Millisecond)
+			So(server.Count(), ShouldEqual, 2)
+			So(stored(), ShouldEqual, 3)
+
+			Convey("applying a new policy with the next batch", func() {
+				c.DrainPolicy(0, 2, nil)
+				So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+				So(server.Count(), ShouldEqual, 5)
+			})
+		})
+
+		Convey("is copied by Clone", func() {
+			c.DrainPolicy(time.Minute, 3, nil)
+			So(c.Clone().drain, ShouldResemble, drainPolicy{interval: time.Minute, batchSize: 3})
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// The endpoints of the Raygun API, see Endpoint and Region.
+const (
+	// EndpointDefault is the global endpoint of the Raygun API.
+	EndpointDefault = "https://api.raygun.com"
+
+	// EndpointEU is the endpoint of the Raygun API in the EU, for
+	// applications whose data has to stay in the EU.
+	EndpointEU = "https://api.eu.raygun.com"
+)
+
+// regionEndpoints maps the region names accepted by Region to their
+// endpoints.
+var regionEndpoints = map[string]string{
+	"default": EndpointDefault,
+	"eu":      EndpointEU,
+}
+
+// ErrUnknownRegion is matched by the error RegionEndpoint returns for region
+// names it does not know, see errors.Is.
+var ErrUnknownRegion = errors.New("unknown Raygun region")
+
+// RegionEndpoint returns the endpoint of the Raygun API in the region with
+// the given name, "default" or "eu", ignoring case. Use it to validate region
+// names read from configuration when the program starts:
+//
+//	endpoint, err := raygun4go.RegionEndpoint(os.Getenv("RAYGUN_REGION"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	raygun.Endpoint(endpoint)
+func RegionEndpoint(name string) (string, error) {
+	endpoint, ok := regionEndpoints[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownRegion, name)
+	}
+	return endpoint, nil
+}
+
+// Region is a chainable option-setting method to post reports to the Raygun
+// API in the region with the given name, as accepted by RegionEndpoint. An
+// unknown name keeps the current endpoint and is logged and reported in
+// Strict mode; use SetRegion to get the error instead.
+func (c *Client) Region(name string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.refuseLiveMode("Region")
+	c.ignoreEndpointError(c.SetRegion(name))
+	return c
+}
+
+// SetRegion makes the client post reports to the Raygun API in the region
+// with the given name, as accepted by RegionEndpoint. It returns an error
+// matching ErrUnknownRegion for unknown names, keeping the current endpoint.
+// SetRegion replaces an endpoint set with Endpoint and vice versa.
+func (c *Client) SetRegion(name string) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	c.refuseLiveMode("SetRegion")
+	endpoint, err := RegionEndpoint(name)
+	if err != nil {
+		return err
+	}
+	c.endpoint = endpoint
+	c.endpointErr = nil
+	return nil
+}
+
+// Endpoint is a chainable option-setting method to set the URL of the Raygun
+// API reports are posted to, e.g. a proxy collector, instead of
+// EndpointDefault. An invalid URL keeps the current endpoint and is logged
+// and reported in Strict mode; use SetEndpoint to get the error instead.
+func (c *Client) Endpoint(endpoint string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.refuseLiveMode("Endpoint")
+	c.ignoreEndpointError(c.SetEndpoint(endpoint))
+	return c
+}
+
+// SetEndpoint sets the URL of the Raygun API reports are posted to. Trailing
+// slashes are removed. It returns an error for URLs that are not absolute
+// http or https URLs, keeping the current endpoint.
+func (c *Client) SetEndpoint(endpoint string) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	c.refuseLiveMode("SetEndpoint")
+	endpoint = strings.TrimRight(endpoint, "/")
+	if err := validateEndpoint(endpoint); err != nil {
+		return err
+	}
+	c.endpoint = endpoint
+	c.endpointErr = nil
+	return nil
+}
+
+// ignoreEndpointError logs the given error of Endpoint or Region, if any, and
+// keeps it to be reported in strict mode, see Strict.
+func (c *Client) ignoreEndpointError(err error) {
+	if err == nil {
+		return
+	}
+	err = fmt.Errorf("Ignored endpoint (%w)", err)
+	c.output().Errorf("%s", err.Error())
+	c.endpointErr = err
+}
+
+// validateEndpoint returns an error if the given endpoint is not an absolute
+// http or https URL.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("Unable to parse endpoint %q (%w)", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Endpoint %q is not an http or https URL", endpoint)
+	}
+	return nil
+}
+
+// apiEndpoint returns the URL of the Raygun API the client posts to.
+func (c *Client) apiEndpoint() string {
+	if c.endpoint != "" {
+		return c.endpoint
+	}
+	return raygunEndpoint
+}
@@ -0,0 +1,139 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEndpoint(t *testing.T) {
+	Convey("Endpoint", t, func() {
+		paths := make(chan string, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths <- r.URL.Path
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		Reset(server.Close)
+		next := func() string {
+			select {
+			case p := <-paths:
+				return p
+			case <-time.After(5 * time.Second):
+				return ""
+			}
+		}
+
+		c, _ := New("app", "key")
+
+		Convey("defaults to the Raygun API", func() {
+			So(c.apiEndpoint(), ShouldEqual, "https://api.raygun.com")
+		})
+
+		Convey("sets where reports are posted to", func() {
+			c.Endpoint(server.URL + "/collector/")
+			So(c.apiEndpoint(), ShouldEqual, server.URL+"/collector")
+			So(c.SendError(errors.New("Test Endpoint")), ShouldBeNil)
+			So(next(), ShouldEqual, "/collector/entries")
+		})
+
+		Convey("is preserved by Clone", func() {
+			c.Endpoint(server.URL)
+			So(c.Clone().SendError(errors.New("Test Endpoint")), ShouldBeNil)
+			So(next(), ShouldEqual, "/entries")
+		})
+
+		Convey("is per client", func() {
+			c.Endpoint(server.URL)
+			other, _ := New("app", "key")
+			So(other.apiEndpoint(), ShouldEqual, "https://api.raygun.com")
+		})
+
+		Convey("ignores invalid URLs", func() {
+			c.Endpoint(server.URL)
+			for _, endpoint := range []string{"", "api.raygun.com", "ftp://api.raygun.com", "http://%zz", "/entries"} {
+				c.Endpoint(endpoint)
+				So(c.apiEndpoint(), ShouldEqual, server.URL)
+			}
+
+			Convey("and reports them in strict mode", func() {
+				var degraded *DegradationError
+				So(errors.As(c.Strict(true).SendError(errors.New("Test Endpoint")), &degraded), ShouldBeTrue)
+				So(degraded.Err, ShouldBeNil)
+				So(degraded.Error(), ShouldContainSubstring, `Endpoint "/entries" is not an http or https URL`)
+
+				c.Endpoint(server.URL)
+				So(c.SendError(errors.New("Test Endpoint")), ShouldBeNil)
+			})
+		})
+
+		Convey("#SetEndpoint returns invalid URLs", func() {
+			So(c.SetEndpoint(server.URL+"/"), ShouldBeNil)
+			So(c.apiEndpoint(), ShouldEqual, server.URL)
+			So(c.SetEndpoint("api.raygun.com"), ShouldNotBeNil)
+			So(c.apiEndpoint(), ShouldEqual, server.URL)
+		})
+	})
+}
+
+func TestRegion(t *testing.T) {
+	Convey("Region", t, func() {
+		tests := []struct {
+			region string
+			url    string
+		}{
+			{"default", "https://api.raygun.com/entries"},
+			{"eu", "https://api.eu.raygun.com/entries"},
+			{"EU", "https://api.eu.raygun.com/entries"},
+			{" Default ", "https://api.raygun.com/entries"},
+		}
+		for _, test := range tests {
+			transport := &recordingTransport{}
+			c, _ := New("app", "key")
+			c.HTTPClient(&http.Client{Transport: transport}).Region(test.region)
+			So(c.SendError(errors.New("Test Region")), ShouldBeNil)
+			So(transport.requests[0].URL.String(), ShouldEqual, test.url)
+
+			So(c.Clone().apiEndpoint(), ShouldEqual, c.apiEndpoint())
+		}
+
+		Convey("validates region names", func() {
+			endpoint, err := RegionEndpoint("eu")
+			So(err, ShouldBeNil)
+			So(endpoint, ShouldEqual, EndpointEU)
+
+			_, err = RegionEndpoint("mars")
+			So(errors.Is(err, ErrUnknownRegion), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, `unknown Raygun region "mars"`)
+		})
+
+		Convey("ignores unknown regions", func() {
+			c, _ := New("app", "key")
+			c.Region("eu").Region("mars").Region("EU ")
+			So(c.apiEndpoint(), ShouldEqual, EndpointEU)
+			c.Region("eu-west")
+			So(c.apiEndpoint(), ShouldEqual, EndpointEU)
+			So(errors.Is(c.endpointErr, ErrUnknownRegion), ShouldBeTrue)
+		})
+
+		Convey("#SetRegion returns unknown regions", func() {
+			c, _ := New("app", "key")
+			So(c.SetRegion("EU "), ShouldBeNil)
+			So(c.apiEndpoint(), ShouldEqual, EndpointEU)
+			err := c.SetRegion("mars")
+			So(errors.Is(err, ErrUnknownRegion), ShouldBeTrue)
+			So(c.apiEndpoint(), ShouldEqual, EndpointEU)
+		})
+
+		Convey("replaces the endpoint and vice versa", func() {
+			c, _ := New("app", "key")
+			c.Endpoint("http://collector").Region("eu")
+			So(c.apiEndpoint(), ShouldEqual, EndpointEU)
+			c.Endpoint("http://collector")
+			So(c.apiEndpoint(), ShouldEqual, "http://collector")
+		})
+	})
+}
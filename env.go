@@ -0,0 +1,80 @@
+package raygun4go
+
+import (
+	"os"
+	"strings"
+)
+
+// envCustomDataKey is the custom data key the included environment variables
+// are stored under.
+const envCustomDataKey = "env"
+
+// IncludeEnvVars is a chainable option-setting method to add the given
+// environment variables to the custom data of every report, under "env". The
+// variables are read once, when IncludeEnvVars is called; use
+// IncludeDynamicEnvVars for variables that change while the program runs.
+//
+// Only variables listed by name are included, names containing wildcards are
+// ignored. Unset variables are left out and the redaction patterns are
+// applied to all values.
+func (c *Client) IncludeEnvVars(names ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	envVars := make(map[string]string, len(c.envVars)+len(names))
+	for k, v := range c.envVars {
+		envVars[k] = v
+	}
+	for _, name := range c.validEnvVarNames(names) {
+		if v, ok := os.LookupEnv(name); ok {
+			envVars[name] = v
+		} else {
+			delete(envVars, name)
+		}
+	}
+	c.envVars = envVars
+	return c
+}
+
+// IncludeDynamicEnvVars is a chainable option-setting method like
+// IncludeEnvVars, except that the given variables are read again for every
+// report.
+func (c *Client) IncludeDynamicEnvVars(names ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.dynamicEnvVars = append(copyStrings(c.dynamicEnvVars), c.validEnvVarNames(names)...)
+	return c
+}
+
+// validEnvVarNames returns the given names without those containing
+// wildcards, which are never expanded so no secrets are included by accident.
+func (c *Client) validEnvVarNames(names []string) []string {
+	valid := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, "*?[") {
+			c.errorf("Ignoring environment variable name %q", name)
+			continue
+		}
+		valid = append(valid, name)
+	}
+	return valid
+}
+
+// envCustomData returns the included environment variables with the
+// redaction patterns applied, or nil if there are none.
+func (c *Client) envCustomData() map[string]string {
+	env := make(map[string]string, len(c.envVars)+len(c.dynamicEnvVars))
+	for k, v := range c.envVars {
+		env[k] = c.redaction.redactValue(v)
+	}
+	for _, name := range c.dynamicEnvVars {
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = c.redaction.redactValue(v)
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
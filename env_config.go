@@ -0,0 +1,98 @@
+package raygun4go
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The environment variables read by NewFromEnv.
+const (
+	envAPIKey   = "RAYGUN_API_KEY"
+	envAppName  = "RAYGUN_APP_NAME"
+	envVersion  = "RAYGUN_VERSION"
+	envTags     = "RAYGUN_TAGS"
+	envAsync    = "RAYGUN_ASYNC"
+	envEndpoint = "RAYGUN_ENDPOINT"
+	envDisabled = "RAYGUN_DISABLED"
+)
+
+// NewFromEnv creates a Client like New, configured by environment variables:
+//
+//	RAYGUN_API_KEY   the API key, required
+//	RAYGUN_APP_NAME  the name of the application, required
+//	RAYGUN_VERSION   the version of the application, see Version
+//	RAYGUN_TAGS      comma-separated tags of all reports, see Tags
+//	RAYGUN_ASYNC     a boolean enabling asynchronous mode, see Asynchronous
+//	RAYGUN_ENDPOINT  the URL of the Raygun API, see Endpoint
+//	RAYGUN_DISABLED  a boolean switching the client off, see Enabled
+//
+// Empty variables are treated as unset. NewFromEnv returns an error listing
+// the required variables that are missing, or describing the first invalid
+// value. The given options are applied after the variables, so they take
+// precedence, as do the chainable option-setting methods called later.
+func NewFromEnv(opts ...Option) (*Client, error) {
+	var missing []string
+	for _, name := range []string{envAPIKey, envAppName} {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("Missing environment variables %s", strings.Join(missing, ", "))
+	}
+
+	var envOpts []Option
+	if v := os.Getenv(envVersion); v != "" {
+		envOpts = append(envOpts, WithVersion(v))
+	}
+	if v := os.Getenv(envTags); v != "" {
+		envOpts = append(envOpts, WithDefaultTags(splitEnvList(v)...))
+	}
+	async, err := envBool(envAsync)
+	if err != nil {
+		return nil, err
+	}
+	if async {
+		envOpts = append(envOpts, WithAsynchronous(true))
+	}
+	if v := os.Getenv(envEndpoint); v != "" {
+		envOpts = append(envOpts, WithEndpoint(v))
+	}
+	disabled, err := envBool(envDisabled)
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		envOpts = append(envOpts, WithEnabled(false))
+	}
+
+	return New(os.Getenv(envAppName), os.Getenv(envAPIKey), append(envOpts, opts...)...)
+}
+
+// envBool returns the boolean value of the environment variable of the given
+// name, false if it is unset or empty.
+func envBool(name string) (bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return false, fmt.Errorf("Environment variable %s=%q is not a boolean", name, v)
+	}
+	return b, nil
+}
+
+// splitEnvList returns the non-empty elements of the given comma-separated
+// list, trimmed.
+func splitEnvList(list string) []string {
+	var elements []string
+	for _, e := range strings.Split(list, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
@@ -0,0 +1,116 @@
+package raygun4go
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	Convey("NewFromEnv", t, func() {
+		// setenv sets the given variables and clears the other ones read by
+		// NewFromEnv.
+		setenv := func(vars map[string]string) {
+			for _, name := range []string{envAPIKey, envAppName, envVersion, envTags, envAsync, envEndpoint, envDisabled} {
+				t.Setenv(name, vars[name])
+			}
+		}
+		required := func(vars map[string]string) map[string]string {
+			vars[envAPIKey], vars[envAppName] = "key", "app"
+			return vars
+		}
+
+		Convey("configures the client", func() {
+			setenv(required(map[string]string{
+				envVersion:  "1.2.3",
+				envTags:     "a, b,,c ",
+				envAsync:    "true",
+				envEndpoint: "https://collector.example.com",
+			}))
+			c, err := NewFromEnv()
+			So(err, ShouldBeNil)
+			defer c.Close()
+
+			So(c.appName, ShouldEqual, "app")
+			So(c.apiKey.get(), ShouldEqual, "key")
+			So(c.context.Version, ShouldEqual, "1.2.3")
+			So(c.context.Tags, ShouldResemble, []string{"a", "b", "c"})
+			So(c.asynchronous, ShouldBeTrue)
+			So(c.endpoint, ShouldEqual, "https://collector.example.com")
+			So(c.switchedOff(), ShouldBeFalse)
+		})
+
+		Convey("keeps the defaults for unset variables", func() {
+			setenv(required(map[string]string{}))
+			c, err := NewFromEnv()
+			So(err, ShouldBeNil)
+			defer c.Close()
+
+			So(c.context.Version, ShouldEqual, "")
+			So(c.context.Tags, ShouldBeNil)
+			So(c.asynchronous, ShouldBeFalse)
+			So(c.endpoint, ShouldEqual, "")
+			So(c.switchedOff(), ShouldBeFalse)
+		})
+
+		Convey("switches the client off", func() {
+			for _, v := range []string{"true", "1", "TRUE", " t "} {
+				setenv(required(map[string]string{envDisabled: v}))
+				c, err := NewFromEnv()
+				So(err, ShouldBeNil)
+				So(c.switchedOff(), ShouldBeTrue)
+				c.Close()
+			}
+			setenv(required(map[string]string{envDisabled: "false"}))
+			c, err := NewFromEnv()
+			So(err, ShouldBeNil)
+			So(c.switchedOff(), ShouldBeFalse)
+			c.Close()
+		})
+
+		Convey("lists the missing required variables", func() {
+			setenv(map[string]string{envVersion: "1.2.3"})
+			_, err := NewFromEnv()
+			So(err.Error(), ShouldEqual, "Missing environment variables RAYGUN_API_KEY, RAYGUN_APP_NAME")
+
+			setenv(map[string]string{envAPIKey: "key"})
+			_, err = NewFromEnv()
+			So(err.Error(), ShouldEqual, "Missing environment variables RAYGUN_APP_NAME")
+		})
+
+		Convey("rejects malformed booleans", func() {
+			setenv(required(map[string]string{envAsync: "yes"}))
+			_, err := NewFromEnv()
+			So(err.Error(), ShouldEqual, `Environment variable RAYGUN_ASYNC="yes" is not a boolean`)
+
+			setenv(required(map[string]string{envDisabled: "off"}))
+			_, err = NewFromEnv()
+			So(err.Error(), ShouldEqual, `Environment variable RAYGUN_DISABLED="off" is not a boolean`)
+		})
+
+		Convey("rejects an invalid endpoint", func() {
+			setenv(required(map[string]string{envEndpoint: "collector"}))
+			_, err := NewFromEnv()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("lets options and setters override the variables", func() {
+			setenv(required(map[string]string{envVersion: "1.2.3", envAsync: "true", envDisabled: "true"}))
+			c, err := NewFromEnv(WithVersion("2.0.0"))
+			So(err, ShouldBeNil)
+			defer c.Close()
+			So(c.context.Version, ShouldEqual, "2.0.0")
+
+			c.Asynchronous(false).Enabled(true)
+			So(c.asynchronous, ShouldBeFalse)
+			So(c.switchedOff(), ShouldBeFalse)
+		})
+
+		Convey("returns the errors of the options", func() {
+			setenv(required(map[string]string{}))
+			_, err := NewFromEnv(WithTimeout(0))
+			So(errors.Is(err, ErrInvalidOption), ShouldBeTrue)
+		})
+	})
+}
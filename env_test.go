@@ -0,0 +1,82 @@
+package raygun4go
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIncludeEnvVars(t *testing.T) {
+	Convey("IncludeEnvVars", t, func() {
+		setenv := func(name, value string) {
+			old, ok := os.LookupEnv(name)
+			os.Setenv(name, value)
+			Reset(func() {
+				if ok {
+					os.Setenv(name, old)
+				} else {
+					os.Unsetenv(name)
+				}
+			})
+		}
+		setenv("RAYGUN_TEST_REGION", "eu-west")
+		setenv("RAYGUN_TEST_CLUSTER", "blue")
+		setenv("RAYGUN_TEST_SECRET", "hunter2")
+
+		c, _ := New("app", "key")
+		env := func() interface{} {
+			post := c.createPost(errors.New("Test IncludeEnvVars"), StackTrace{})
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			return data["env"]
+		}
+
+		Convey("adds nothing by default", func() {
+			So(env(), ShouldBeNil)
+		})
+
+		Convey("adds the listed variables only", func() {
+			c.IncludeEnvVars("RAYGUN_TEST_REGION", "RAYGUN_TEST_CLUSTER", "RAYGUN_TEST_UNSET")
+			So(env(), ShouldResemble, map[string]string{
+				"RAYGUN_TEST_REGION":  "eu-west",
+				"RAYGUN_TEST_CLUSTER": "blue",
+			})
+		})
+
+		Convey("ignores wildcards", func() {
+			c.IncludeEnvVars("RAYGUN_TEST_*", "*")
+			So(env(), ShouldBeNil)
+		})
+
+		Convey("reads the variables once", func() {
+			c.IncludeEnvVars("RAYGUN_TEST_REGION")
+			os.Setenv("RAYGUN_TEST_REGION", "us-east")
+			So(env(), ShouldResemble, map[string]string{"RAYGUN_TEST_REGION": "eu-west"})
+		})
+
+		Convey("#IncludeDynamicEnvVars reads the variables for every report", func() {
+			c.IncludeDynamicEnvVars("RAYGUN_TEST_REGION")
+			So(env(), ShouldResemble, map[string]string{"RAYGUN_TEST_REGION": "eu-west"})
+			os.Setenv("RAYGUN_TEST_REGION", "us-east")
+			So(env(), ShouldResemble, map[string]string{"RAYGUN_TEST_REGION": "us-east"})
+			os.Unsetenv("RAYGUN_TEST_REGION")
+			So(env(), ShouldBeNil)
+		})
+
+		Convey("applies the redaction patterns", func() {
+			setenv("RAYGUN_TEST_TOKEN", "Bearer abc.def")
+			c.IncludeEnvVars("RAYGUN_TEST_TOKEN")
+			So(env(), ShouldResemble, map[string]string{"RAYGUN_TEST_TOKEN": "[REDACTED]"})
+		})
+
+		Convey("keeps user custom data", func() {
+			c.CustomData(map[string]interface{}{"foo": "bar"}).IncludeEnvVars("RAYGUN_TEST_CLUSTER")
+			post := c.createPost(errors.New("Test IncludeEnvVars"), StackTrace{})
+			So(withoutReportID(post), ShouldResemble, map[string]interface{}{
+				"foo": "bar",
+				"env": map[string]string{"RAYGUN_TEST_CLUSTER": "blue"},
+			})
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The categories of failures, matched by the errors returned by the client
+// with errors.Is. The errors also wrap their underlying cause, if any, so
+// errors.As finds e.g. a *json.UnsupportedTypeError or a *url.Error.
+var (
+	// ErrMarshalFailure matches errors converting a report or other data to
+	// JSON.
+	ErrMarshalFailure = errors.New("unable to convert to JSON")
+
+	// ErrRequestBuild matches errors creating the request submitting a
+	// report, e.g. because of an invalid endpoint.
+	ErrRequestBuild = errors.New("unable to create request")
+
+	// ErrSubmission matches all *SubmitError, i.e. failed requests and
+	// unexpected answers of Raygun.
+	ErrSubmission = errors.New("unable to submit report")
+
+	// ErrInvalidAPIKey matches the *SubmitError of a submission Raygun
+	// rejected because of an unknown or missing API key, see IsInvalidAPIKey.
+	ErrInvalidAPIKey = errors.New("Raygun rejected the API key")
+
+	// ErrPayloadTooLarge matches the *SubmitError of a submission Raygun
+	// rejected because the report exceeds its size limit.
+	ErrPayloadTooLarge = errors.New("Raygun rejected the report as too large")
+)
+
+// kindError is an error of the category given by a sentinel error, wrapping
+// its underlying cause.
+type kindError struct {
+	kind  error  // the sentinel the error matches
+	msg   string // the message of the error
+	cause error  // the underlying error
+}
+
+// newKindError returns an error matching kind and wrapping cause, with the
+// message formatted from the given format and arguments.
+func newKindError(kind, cause error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...), cause: cause}
+}
+
+// Error returns the message of the error.
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+// Is reports whether target is the category of the error.
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap returns the underlying cause.
+func (e *kindError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,108 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrors(t *testing.T) {
+	Convey("Errors", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		send := func() error {
+			return c.SendError(errors.New("Test Errors"))
+		}
+
+		Convey("match ErrMarshalFailure and wrap the JSON error", func() {
+			post := c.createPost(errors.New("Test Errors"), StackTrace{})
+			post.Details.UserCustomData = map[string]interface{}{"ch": make(chan int)}
+			err := c.Submit(post)
+			So(errors.Is(err, ErrMarshalFailure), ShouldBeTrue)
+			var jsonErr *json.UnsupportedTypeError
+			So(errors.As(err, &jsonErr), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "Unable to convert to JSON (")
+		})
+
+		Convey("match ErrRequestBuild and wrap the URL error", func() {
+			c.endpoint = "http://[::1"
+			err := send()
+			So(errors.Is(err, ErrRequestBuild), ShouldBeTrue)
+			var urlErr *url.Error
+			So(errors.As(err, &urlErr), ShouldBeTrue)
+			So(errors.Is(err, ErrSubmission), ShouldBeFalse)
+		})
+
+		Convey("match ErrSubmission for failed requests", func() {
+			server.Script(rayguntest.ConnectionReset, rayguntest.ConnectionReset)
+			err := send()
+			So(errors.Is(err, ErrSubmission), ShouldBeTrue)
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(errors.Is(err, ErrInvalidAPIKey), ShouldBeFalse)
+		})
+
+		Convey("match ErrSubmission for unexpected answers", func() {
+			server.Script(rayguntest.BadRequest("invalid report"))
+			err := send()
+			So(errors.Is(err, ErrSubmission), ShouldBeTrue)
+			So(errors.Is(err, ErrPayloadTooLarge), ShouldBeFalse)
+		})
+
+		Convey("match ErrInvalidAPIKey for rejected keys", func() {
+			server.Script(rayguntest.Unauthorized)
+			err := send()
+			So(errors.Is(err, ErrInvalidAPIKey), ShouldBeTrue)
+			So(errors.Is(err, ErrSubmission), ShouldBeTrue)
+		})
+
+		Convey("match ErrPayloadTooLarge for oversized reports", func() {
+			server.Script(rayguntest.PayloadTooLarge)
+			So(errors.Is(send(), ErrPayloadTooLarge), ShouldBeTrue)
+		})
+
+		Convey("match ErrRateLimited while rate limited", func() {
+			server.Script(rayguntest.TooManyRequests("30"))
+			So(errors.Is(send(), ErrRateLimited), ShouldBeTrue)
+			So(errors.Is(send(), ErrRateLimited), ShouldBeTrue)
+		})
+
+		Convey("match ErrQueueFull if the queue is full", func() {
+			c.Asynchronous(true)
+			c.queue.start.Do(func() {})
+			c.queue.size = 1
+			So(send(), ShouldBeNil)
+			So(errors.Is(send(), ErrQueueFull), ShouldBeTrue)
+		})
+
+		Convey("match ErrReportCancelled for cancelled reports", func() {
+			c.BeforeSend(func(*PostData) bool { return false })
+			So(errors.Is(send(), ErrReportCancelled), ShouldBeTrue)
+		})
+
+		Convey("match ErrNoClient for nil clients", func() {
+			var nilClient *Client
+			So(errors.Is(nilClient.SendError(errors.New("Test Errors")), ErrNoClient), ShouldBeTrue)
+		})
+
+		Convey("match their category when returned in strict mode", func() {
+			c.Strict(true).RedactPatterns("(")
+			server.Script(rayguntest.Unauthorized)
+			err := send()
+			var degraded *DegradationError
+			So(errors.As(err, &degraded), ShouldBeTrue)
+			So(errors.Is(err, ErrInvalidAPIKey), ShouldBeTrue)
+			var syntaxErr *syntax.Error
+			So(errors.As(degraded.Degradations[0], &syntaxErr), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,186 @@
+package raygun4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxFlattenedKeys is the maximum number of dotted keys created by
+// flattening custom data. Further values are dropped and counted under
+// flattenedOmittedKey.
+const maxFlattenedKeys = 500
+
+// maxFlattenedValueLength is the maximum length in characters of flattened
+// string values.
+const maxFlattenedValueLength = 1000
+
+// flattenedOmittedKey is the custom data key the number of values dropped by
+// flattening is stored under.
+const flattenedOmittedKey = "flattenedOmittedValues"
+
+// FlattenCustomDataDepth is a chainable option-setting method to flatten
+// custom data nested deeper than the given number of levels, which the
+// Raygun UI renders as collapsed JSON. Maps and arrays beyond that depth are
+// replaced by dotted keys like "a.b.c" or "items.3.id" holding the values.
+// At most 500 keys are created and string values are cut at 1000
+// characters. Values whose key path contains a field redacted by
+// RedactFields are redacted, and the redaction patterns are applied to the
+// others. A depth of 0, the default, disables flattening.
+func (c *Client) FlattenCustomDataDepth(n int) *Client {
+	if c == nil {
+		return nil
+	}
+	c.flattenDepth = n
+	return c
+}
+
+// flattenCustomData flattens the given custom data as configured by
+// FlattenCustomDataDepth. It reports whether anything was flattened; if not,
+// the custom data is returned unchanged.
+func (c *Client) flattenCustomData(data interface{}) (interface{}, bool) {
+	if c.flattenDepth <= 0 || data == nil {
+		return data, false
+	}
+
+	enc, err := json.Marshal(data)
+	if err != nil {
+		return data, false
+	}
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(enc))
+	dec.UseNumber()
+	if dec.Decode(&generic) != nil || documentDepth(generic) <= c.flattenDepth {
+		return data, false
+	}
+
+	f := flattener{depth: c.flattenDepth, redaction: c.redaction}
+	flattened := f.nest(generic, 1)
+	if f.omitted > 0 {
+		if m, ok := flattened.(map[string]interface{}); ok {
+			m[flattenedOmittedKey] = f.omitted
+		}
+	}
+	return flattened, true
+}
+
+// documentDepth returns the number of nested levels of maps and arrays of
+// the given JSON value.
+func documentDepth(v interface{}) int {
+	depth := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			if d := documentDepth(child); d > depth {
+				depth = d
+			}
+		}
+		return depth + 1
+	case []interface{}:
+		for _, child := range v {
+			if d := documentDepth(child); d > depth {
+				depth = d
+			}
+		}
+		return depth + 1
+	}
+	return 0
+}
+
+// flattener flattens JSON values beyond a depth into dotted keys.
+type flattener struct {
+	depth     int
+	redaction redactor
+	keys      int // the number of dotted keys created so far
+	omitted   int // the number of values dropped because of maxFlattenedKeys
+}
+
+// nest copies the given value found at the given level, flattening the
+// contents of maps and arrays at the configured depth.
+func (f *flattener) nest(v interface{}, level int) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for _, k := range sortedKeys(v) {
+			if level < f.depth {
+				out[k] = f.nest(v[k], level+1)
+			} else {
+				f.flatten(k, v[k], out)
+			}
+		}
+		return out
+	case []interface{}:
+		if level < f.depth {
+			out := make([]interface{}, len(v))
+			for i, child := range v {
+				out[i] = f.nest(child, level+1)
+			}
+			return out
+		}
+		out := make(map[string]interface{}, len(v))
+		for i, child := range v {
+			f.flatten(strconv.Itoa(i), child, out)
+		}
+		return out
+	}
+	return v
+}
+
+// flatten adds the given value to out under the given key, or its contents
+// under dotted keys starting with it.
+func (f *flattener) flatten(key string, v interface{}, out map[string]interface{}) {
+	switch child := v.(type) {
+	case map[string]interface{}:
+		if len(child) > 0 {
+			for _, k := range sortedKeys(child) {
+				f.flatten(key+"."+k, child[k], out)
+			}
+			return
+		}
+	case []interface{}:
+		if len(child) > 0 {
+			for i, c := range child {
+				f.flatten(key+"."+strconv.Itoa(i), c, out)
+			}
+			return
+		}
+	}
+
+	if f.keys == maxFlattenedKeys {
+		f.omitted++
+		return
+	}
+	f.keys++
+	out[key] = f.value(key, v)
+}
+
+// value returns the given value stored under the given dotted key, redacted
+// and cut to maxFlattenedValueLength.
+func (f *flattener) value(key string, v interface{}) interface{} {
+	for _, segment := range strings.Split(key, ".") {
+		if containsFold(f.redaction.config.Fields, segment) {
+			return redactedValue
+		}
+	}
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = f.redaction.redactValue(s)
+	if runes := []rune(s); len(runes) > maxFlattenedValueLength {
+		s = string(runes[:maxFlattenedValueLength]) + "…"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of the given map in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
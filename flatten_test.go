@@ -0,0 +1,115 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFlattenCustomData(t *testing.T) {
+	Convey("FlattenCustomDataDepth", t, func() {
+		c, _ := New("app", "key")
+		c.newReportID = func() string { return "report-1" }
+		c.RedactFields("apiKey")
+		c.RedactPatterns(`sk_live_\w+`)
+		c.CustomData(map[string]interface{}{
+			"shallow": "value",
+			"a": map[string]interface{}{
+				"b": map[string]interface{}{
+					"c": map[string]interface{}{"d": 1},
+				},
+			},
+			"order": map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"id": 7},
+					map[string]interface{}{"id": 8, "tags": []string{"x"}},
+				},
+			},
+			"config": map[string]interface{}{
+				"service": map[string]interface{}{"apiKey": "secret", "note": "key sk_live_abc"},
+			},
+		})
+
+		// flattened returns the custom data of a report after a JSON round
+		// trip, as Raygun receives it.
+		flattened := func() map[string]interface{} {
+			post := c.createPost(errors.New("Test Flatten"), StackTrace{})
+			enc, _ := json.Marshal(post.Details.UserCustomData)
+			var data map[string]interface{}
+			json.Unmarshal(enc, &data)
+			return data
+		}
+
+		Convey("leaves custom data alone by default", func() {
+			data := flattened()
+			So(data["a"], ShouldResemble, map[string]interface{}{
+				"b": map[string]interface{}{"c": map[string]interface{}{"d": float64(1)}},
+			})
+		})
+
+		Convey("flattens levels beyond the depth into dotted keys", func() {
+			c.FlattenCustomDataDepth(2)
+			data := flattened()
+			delete(data, reportIDKey)
+			So(data, ShouldResemble, map[string]interface{}{
+				"shallow": "value",
+				"a":       map[string]interface{}{"b.c.d": float64(1)},
+				"order": map[string]interface{}{
+					"items.0.id":     float64(7),
+					"items.1.id":     float64(8),
+					"items.1.tags.0": "x",
+				},
+				"config": map[string]interface{}{
+					"service.apiKey": "[REDACTED]",
+					"service.note":   "key [REDACTED]",
+				},
+			})
+		})
+
+		Convey("indexes arrays at the depth", func() {
+			c.FlattenCustomDataDepth(3)
+			data := flattened()
+			So(data["order"], ShouldResemble, map[string]interface{}{
+				"items": map[string]interface{}{
+					"0.id":     float64(7),
+					"1.id":     float64(8),
+					"1.tags.0": "x",
+				},
+			})
+		})
+
+		Convey("leaves custom data within the depth unchanged", func() {
+			c.FlattenCustomDataDepth(4)
+			data := flattened()
+			So(data["config"], ShouldResemble, map[string]interface{}{
+				"service": map[string]interface{}{"apiKey": "secret", "note": "key sk_live_abc"},
+			})
+		})
+
+		Convey("caps the number of keys and the value lengths", func() {
+			items := make([]interface{}, maxFlattenedKeys+10)
+			for i := range items {
+				items[i] = map[string]interface{}{"v": strings.Repeat("x", maxFlattenedValueLength+1)}
+			}
+			c.CustomData(map[string]interface{}{"order": map[string]interface{}{"items": items}})
+			c.FlattenCustomDataDepth(2)
+			data := flattened()
+			order := data["order"].(map[string]interface{})
+			So(order, ShouldHaveLength, maxFlattenedKeys)
+			So(data[flattenedOmittedKey], ShouldEqual, 10)
+			So(order["items.0.v"], ShouldEqual, strings.Repeat("x", maxFlattenedValueLength)+"…")
+			So(order, ShouldNotContainKey, fmt.Sprintf("items.%d.v", maxFlattenedKeys))
+		})
+
+		Convey("is reported as degradation in strict mode", func() {
+			c.FlattenCustomDataDepth(2).Strict(true)
+			post := c.createPost(errors.New("Test Flatten"), StackTrace{})
+			So(post.degradations, ShouldHaveLength, 1)
+			So(post.degradations[0].Error(), ShouldEqual, "Custom data deeper than 2 levels was flattened")
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package raygun4go
+
+import "fmt"
+
+// FrameClass tells how a stack frame is reported, see FrameClassifier.
+type FrameClass int
+
+// The classes a FrameClassifier can assign to a stack frame.
+const (
+	// FrameKeep reports the frame as it is.
+	FrameKeep FrameClass = iota
+
+	// FrameHide drops the frame.
+	FrameHide
+
+	// FrameCollapse replaces each run of consecutive collapsed frames by a
+	// single element naming the number of frames in it.
+	FrameCollapse
+)
+
+// FrameClassifierFunc classifies a single stack frame.
+type FrameClassifierFunc func(StackTraceElement) FrameClass
+
+// FrameClassifier is a chainable option-setting method to set a function
+// classifying the frames of every reported stack trace, e.g. to collapse
+// generated code:
+//
+//	raygun.FrameClassifier(func(f raygun4go.StackTraceElement) raygun4go.FrameClass {
+//		if strings.Contains(f.PackageName, "/gen/") {
+//			return raygun4go.FrameCollapse
+//		}
+//		return raygun4go.FrameKeep
+//	})
+//
+// Hidden frames are dropped before runs of collapsed frames are determined,
+// so they do not split a run. Passing nil removes the classifier.
+func (c *Client) FrameClassifier(fn FrameClassifierFunc) *Client {
+	if c == nil {
+		return nil
+	}
+	c.frameClassifier = fn
+	return c
+}
+
+// classifyFrames applies the given classifier to the stack trace. It returns
+// the stack trace unchanged if there is no classifier.
+func classifyFrames(st StackTrace, classify FrameClassifierFunc) StackTrace {
+	if classify == nil || st == nil {
+		return st
+	}
+
+	classified := make(StackTrace, 0, len(st))
+	collapsed := 0
+	flush := func() {
+		if collapsed > 0 {
+			classified = append(classified, collapsedFrames(collapsed))
+			collapsed = 0
+		}
+	}
+	for _, frame := range st {
+		switch classify(frame) {
+		case FrameHide:
+		case FrameCollapse:
+			collapsed++
+		default:
+			flush()
+			classified = append(classified, frame)
+		}
+	}
+	flush()
+	return classified
+}
+
+// collapsedFrames returns the element replacing a run of n collapsed frames.
+func collapsedFrames(n int) StackTraceElement {
+	if n == 1 {
+		return StackTraceElement{MethodName: "… 1 generated frame …"}
+	}
+	return StackTraceElement{MethodName: fmt.Sprintf("… %d generated frames …", n)}
+}
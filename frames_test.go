@@ -0,0 +1,57 @@
+package raygun4go
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFrameClassifier(t *testing.T) {
+	Convey("FrameClassifier", t, func() {
+		st := StackTrace{
+			{10, "example.com/app/handlers", "orders.go", "Create"},
+			{20, "example.com/app/gen/orderpb", "order.pb.go", "Unmarshal"},
+			{30, "example.com/app/gen/orderpb", "order.pb.go", "unmarshalField"},
+			{40, "runtime", "proc.go", "goexit"},
+			{50, "example.com/app/gen/orderpb", "order_grpc.pb.go", "handler"},
+			{60, "example.com/app/server", "server.go", "Serve"},
+			{70, "example.com/app/gen/healthpb", "health.pb.go", "Check"},
+		}
+		classify := func(f StackTraceElement) FrameClass {
+			switch {
+			case strings.Contains(f.PackageName, "/gen/"):
+				return FrameCollapse
+			case f.PackageName == "runtime":
+				return FrameHide
+			}
+			return FrameKeep
+		}
+
+		c, _ := New("app", "key")
+		post := func() PostData {
+			return c.createPost(errors.New("Test FrameClassifier"), st)
+		}
+
+		Convey("keeps all frames by default", func() {
+			So(post().Details.Error.StackTrace, ShouldResemble, st)
+		})
+
+		Convey("hides and collapses frames", func() {
+			c.FrameClassifier(classify)
+			So(post().Details.Error.StackTrace, ShouldResemble, StackTrace{
+				{10, "example.com/app/handlers", "orders.go", "Create"},
+				{0, "", "", "… 3 generated frames …"},
+				{60, "example.com/app/server", "server.go", "Serve"},
+				{0, "", "", "… 1 generated frame …"},
+			})
+			So(len(st), ShouldEqual, 7)
+		})
+
+		Convey("can be removed", func() {
+			c.FrameClassifier(classify).FrameClassifier(nil)
+			So(post().Details.Error.StackTrace, ShouldResemble, st)
+		})
+	})
+}
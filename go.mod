@@ -1,11 +1,16 @@
 module github.com/MindscapeHQ/raygun4go
 
-go 1.13
+go 1.18
 
 require (
 	github.com/go-errors/errors v1.5.1
+	github.com/google/uuid v1.4.0
 	github.com/pborman/uuid v1.2.1
 	github.com/smartystreets/goconvey v1.8.1
 )
 
-require github.com/google/uuid v1.4.0 // indirect
+require (
+	github.com/gopherjs/gopherjs v1.17.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smarty/assertions v1.15.0 // indirect
+)
@@ -0,0 +1,67 @@
+package raygun4go
+
+// backgroundGoroutineTag is the tag of reports of panics in goroutines started
+// with Go.
+const backgroundGoroutineTag = "background-goroutine"
+
+// Go runs fn in a new goroutine and reports its panic, if any, like
+// HandleError does. The report is tagged "background-goroutine" and carries
+// the request, user and breadcrumbs of the client at the time Go was called,
+// so calling Go on the scoped client of the middleware attaches the request
+// being handled even after its response was written. The request data is
+// copied right away; its form is only included if it was parsed already, so
+// the request body is left untouched.
+//
+// On a nil client, fn still runs in a new goroutine but panics are not
+// recovered.
+func (c *Client) Go(fn func()) {
+	if c == nil {
+		go fn()
+		return
+	}
+
+	scope := c.Clone()
+	scope.context.Tags = append(copyStrings(scope.context.Tags), backgroundGoroutineTag)
+	scope.detachRequest()
+
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				scope.reportPanic(e, currentStack())
+			}
+		}()
+		fn()
+	}()
+}
+
+// detachRequest replaces the request of the client by a copy of its data, so
+// it can be reported after the request is done.
+func (c *Client) detachRequest() {
+	c.contextMu.Lock()
+	defer c.contextMu.Unlock()
+	c.context.RequestRef = detachedRequest(c.context)
+	c.context.Request = nil
+	c.context.requestForm = nil
+}
+
+// detachedRequest returns a copy of the data of the request of the given
+// context, including its form only if it was parsed already, or nil if there
+// is no request.
+func detachedRequest(ci contextInformation) *requestRef {
+	ref := ci.RequestRef
+	if ref == nil {
+		ref = newRequestRef(ci.Request)
+		if ref != nil && ci.requestForm != nil {
+			ref.form = ci.requestForm
+		}
+	}
+	if ref == nil {
+		return nil
+	}
+
+	detached := &requestRef{data: ref.data}
+	if form, ok := ref.form.parsed(); ok {
+		detached.data.Form = form
+	}
+	return detached
+}
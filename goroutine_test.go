@@ -0,0 +1,63 @@
+package raygun4go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGo(t *testing.T) {
+	Convey("Go", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Tags([]string{"api"}).User("jane")
+
+		Convey("reports panics with the request of the scoped client after the response", func() {
+			proceed := make(chan struct{})
+			handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				scope := FromContext(r.Context())
+				scope.RecordBreadcrumb(Breadcrumb{Message: "spawning worker", Timestamp: 1})
+				scope.Go(func() {
+					<-proceed
+					panic("Test Go")
+				})
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			r := httptest.NewRequest("POST", "http://www.example.com/jobs?id=7", strings.NewReader("name=report"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusAccepted)
+
+			r.URL.Path = "/changed"
+			close(proceed)
+
+			post, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(post.Details.Error.Message, ShouldEqual, "Test Go")
+			So(post.Details.Request.URL, ShouldEqual, "http://www.example.com/jobs?id=7")
+			So(post.Details.Request.Form, ShouldResemble, map[string]string{"name": "report"})
+			So(post.Details.User.Identifier, ShouldEqual, "jane")
+			So(post.Details.Tags, ShouldResemble, []string{"api", "background-goroutine"})
+			So(post.Details.Breadcrumbs, ShouldResemble, []Breadcrumb{{Timestamp: 1, Message: "spawning worker"}})
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("does not report goroutines that return", func() {
+			done := make(chan struct{})
+			c.Go(func() { close(done) })
+			<-done
+			c.CreateError("Test Go marker")
+
+			post, _ := server.next()
+			So(post.Details.Error.Message, ShouldEqual, "Test Go marker")
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package raygun4go
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+)
+
+// handlerFuncKey is the custom data key the name of the handler function
+// serving a request is stored under.
+const handlerFuncKey = "handlerFunc"
+
+// IncludeHandlerFunc makes the middleware add the name of the handler
+// function serving the request to the custom data of its reports, as
+// "handlerFunc". The handler must be a function, or a *http.ServeMux routing
+// the request to one; the name is left out for other handlers. If tag is true,
+// reports are also tagged "handler:<name>".
+func IncludeHandlerFunc(tag bool) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.handlerFunc = true
+		cfg.tagHandlerFunc = tag
+	}
+}
+
+// includeHandlerFunc sets the name of the function serving the given request
+// with the given handler, tagging the client with it if tag is true.
+func (c *Client) includeHandlerFunc(h http.Handler, r *http.Request, tag bool) {
+	name := handlerFuncName(h, r)
+	if name == "" {
+		return
+	}
+	c.handlerFunc = name
+	if tag {
+		c.contextMu.Lock()
+		c.context.Tags = append(copyStrings(c.context.Tags), "handler:"+name)
+		c.contextMu.Unlock()
+	}
+}
+
+// handlerFuncName returns the name of the function serving the given request
+// with the given handler. If the handler is a *http.ServeMux, the handler it
+// routes the request to is resolved first. It returns "" for handlers that are
+// not functions, such as other routers or wrapping middleware.
+func handlerFuncName(h http.Handler, r *http.Request) string {
+	if mux, ok := h.(*http.ServeMux); ok {
+		h, _ = mux.Handler(r)
+	}
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	return f.Name()
+}
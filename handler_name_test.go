@@ -0,0 +1,73 @@
+package raygun4go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func panickingOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	panic("Test orders handler")
+}
+
+func panickingUsersHandler(w http.ResponseWriter, r *http.Request) {
+	panic("Test users handler")
+}
+
+// wrappingHandler is a handler that is not a function.
+type wrappingHandler struct{ next http.Handler }
+
+func (h wrappingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+func TestHandlerFuncName(t *testing.T) {
+	Convey("Middleware handler names", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		serve := func(handler http.Handler, path string, opts ...MiddlewareOption) PostData {
+			c.Middleware(handler, opts...).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+			post, _ := server.next()
+			return post
+		}
+		handlerFunc := func(post PostData) interface{} {
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			return data["handlerFunc"]
+		}
+
+		Convey("are resolved for handler funcs", func() {
+			post := serve(http.HandlerFunc(panickingOrdersHandler), "/orders", IncludeHandlerFunc(false))
+			So(handlerFunc(post), ShouldEqual, "github.com/MindscapeHQ/raygun4go.panickingOrdersHandler")
+			So(post.Details.Tags, ShouldBeEmpty)
+		})
+
+		Convey("are resolved through a ServeMux", func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/orders", panickingOrdersHandler)
+			mux.HandleFunc("/users", panickingUsersHandler)
+			So(handlerFunc(serve(mux, "/orders", IncludeHandlerFunc(false))), ShouldEqual, "github.com/MindscapeHQ/raygun4go.panickingOrdersHandler")
+			So(handlerFunc(serve(mux, "/users", IncludeHandlerFunc(false))), ShouldEqual, "github.com/MindscapeHQ/raygun4go.panickingUsersHandler")
+		})
+
+		Convey("are added as a tag if requested", func() {
+			post := serve(http.HandlerFunc(panickingUsersHandler), "/users", IncludeHandlerFunc(true))
+			So(post.Details.Tags, ShouldResemble, []string{"handler:github.com/MindscapeHQ/raygun4go.panickingUsersHandler"})
+		})
+
+		Convey("are left out by default", func() {
+			post := serve(http.HandlerFunc(panickingOrdersHandler), "/orders")
+			So(handlerFunc(post), ShouldBeNil)
+		})
+
+		Convey("are left out for wrapped handlers", func() {
+			post := serve(wrappingHandler{http.HandlerFunc(panickingOrdersHandler)}, "/orders", IncludeHandlerFunc(true))
+			So(post.Details.Error.Message, ShouldEqual, "Test orders handler")
+			So(handlerFunc(post), ShouldBeNil)
+			So(post.Details.Tags, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package raygun4go
+
+import (
+	"sort"
+	"strings"
+)
+
+// HeaderTagMapping is a chainable option-setting method to tag every report
+// with the values of the given request headers. The mapping is from header
+// name to tag prefix, e.g. "X-Tenant": "tenant" tags a request sent with
+// "X-Tenant: acme" as "tenant:acme". Tags are normalized like KVTag does,
+// which caps their length. Headers missing from the request are skipped.
+func (c *Client) HeaderTagMapping(mapping map[string]string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.headerTags = copyStringMap(mapping)
+	return c
+}
+
+// HeaderCustomDataMapping is a chainable option-setting method to add the
+// values of the given request headers to the custom data of every report. The
+// mapping is from header name to custom data key. Values are truncated to 100
+// characters. Headers missing from the request are skipped.
+func (c *Client) HeaderCustomDataMapping(mapping map[string]string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.headerCustomData = copyStringMap(mapping)
+	return c
+}
+
+// mapHeaders adds the custom data entries mapped from the headers of the
+// given request data and returns the mapped tags. The redaction rules are
+// applied to the values.
+func (c *Client) mapHeaders(request RequestData, opts *reportOptions) []string {
+	if len(request.Headers) == 0 || (len(c.headerTags) == 0 && len(c.headerCustomData) == 0) {
+		return nil
+	}
+
+	var mapped []string
+	for name, prefix := range c.headerTags {
+		if value, ok := c.headerValue(request, name); ok {
+			mapped = append(mapped, string(KVTag(prefix, value)))
+		}
+	}
+	for name, key := range c.headerCustomData {
+		if value, ok := c.headerValue(request, name); ok {
+			if runes := []rune(value); len(runes) > maxTagValueLength {
+				value = string(runes[:maxTagValueLength])
+			}
+			opts.addCustomData(key, value)
+		}
+	}
+	sort.Strings(mapped)
+	return mapped
+}
+
+// headerValue returns the redacted value of the given header, matching its
+// name case-insensitively.
+func (c *Client) headerValue(request RequestData, name string) (string, bool) {
+	for k, v := range request.Headers {
+		if !strings.EqualFold(k, name) {
+			continue
+		}
+		if containsFold(c.redaction.config.Headers, k) {
+			return redactedValue, true
+		}
+		return c.redaction.redactValue(v), true
+	}
+	return "", false
+}
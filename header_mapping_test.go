@@ -0,0 +1,78 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHeaderMapping(t *testing.T) {
+	Convey("Header mapping", t, func() {
+		c, _ := New("app", "key")
+		r := httptest.NewRequest("GET", "/orders", nil)
+		r.Header.Set("X-Tenant", "acme")
+		r.Header.Set("X-Canary", "true")
+		r.Header.Set("X-Request-Id", "req-1")
+		c.Request(r)
+		post := func() PostData {
+			return c.createPost(errors.New("Test header mapping"), StackTrace{})
+		}
+		customData := func() map[string]interface{} {
+			data, _ := post().Details.UserCustomData.(map[string]interface{})
+			return data
+		}
+
+		Convey("#HeaderTagMapping tags reports with the header values", func() {
+			c.Tags([]string{"api"}).HeaderTagMapping(map[string]string{
+				"X-Tenant": "tenant",
+				"x-canary": "canary",
+				"X-Region": "region",
+			})
+			So(post().Details.Tags, ShouldContain, "api")
+			So(post().Details.Tags, ShouldContain, "tenant:acme")
+			So(post().Details.Tags, ShouldContain, "canary:true")
+			So(post().Details.Tags, ShouldHaveLength, 3)
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("#HeaderCustomDataMapping adds the header values to the custom data", func() {
+			c.HeaderCustomDataMapping(map[string]string{
+				"X-Request-Id": "requestId",
+				"X-Region":     "region",
+			})
+			So(customData()["requestId"], ShouldEqual, "req-1")
+			So(customData(), ShouldNotContainKey, "region")
+		})
+
+		Convey("caps the values", func() {
+			r.Header.Set("X-Tenant", strings.Repeat("a", 150))
+			c.HeaderTagMapping(map[string]string{"X-Tenant": "tenant"})
+			c.HeaderCustomDataMapping(map[string]string{"X-Tenant": "tenant"})
+			So(post().Details.Tags, ShouldResemble, []string{"tenant:" + strings.Repeat("a", 100)})
+			So(customData()["tenant"], ShouldEqual, strings.Repeat("a", 100))
+		})
+
+		Convey("applies the redaction rules", func() {
+			r.Header.Set("Authorization", "Bearer abc")
+			r.Header.Set("X-Tenant", "secret-42")
+			c.RedactPatterns(`secret-\d+`)
+			c.HeaderCustomDataMapping(map[string]string{"Authorization": "auth", "X-Tenant": "tenant"})
+			So(customData()["auth"], ShouldEqual, redactedValue)
+			So(customData()["tenant"], ShouldEqual, redactedValue)
+		})
+
+		Convey("keeps custom data set by the user", func() {
+			c.CustomData(map[string]interface{}{"requestId": "mine"})
+			c.HeaderCustomDataMapping(map[string]string{"X-Request-Id": "requestId"})
+			So(customData()["requestId"], ShouldEqual, "mine")
+		})
+
+		Convey("maps nothing without request data", func() {
+			c.HeaderTagMapping(map[string]string{"X-Tenant": "tenant"}).DisableRequestData(true)
+			So(post().Details.Tags, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package raygun4go
+
+// HeaderPolicyPreset is a named starting configuration of the request data
+// captured in reports, see HeaderPolicy.
+type HeaderPolicyPreset int
+
+const (
+	// PolicyStandard captures all headers, cookies and the client IP
+	// address, with the default redaction rules applied. It is the default.
+	PolicyStandard HeaderPolicyPreset = iota
+
+	// PolicyMinimal captures only the Host, User-Agent and Content-Type
+	// headers besides the HTTP method, and thus no cookies.
+	PolicyMinimal
+
+	// PolicyStrictPII captures only the headers in StrictPIIHeaders, no
+	// cookies and no client IP address.
+	PolicyStrictPII
+)
+
+// minimalHeaders are the headers captured with PolicyMinimal.
+var minimalHeaders = []string{"Content-Type", "Host", "User-Agent"}
+
+// strictPIIHeaders are the headers captured with PolicyStrictPII.
+var strictPIIHeaders = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Content-Length",
+	"Content-Type",
+	"Host",
+	"User-Agent",
+}
+
+// StrictPIIHeaders returns a copy of the header names captured with
+// PolicyStrictPII.
+func StrictPIIHeaders() []string {
+	return copyStrings(strictPIIHeaders)
+}
+
+// String returns the name of the preset.
+func (p HeaderPolicyPreset) String() string {
+	switch p {
+	case PolicyMinimal:
+		return "minimal"
+	case PolicyStrictPII:
+		return "strict-pii"
+	default:
+		return "standard"
+	}
+}
+
+// HeaderPolicy is a chainable option-setting method to replace the redaction
+// rules by the given preset. It is only a starting configuration: options
+// like RedactHeaders or AllowHeaders called afterwards adjust it. The
+// effective rules are returned by EffectiveRedactionConfig. Unknown presets
+// are treated as PolicyStandard.
+func (c *Client) HeaderPolicy(preset HeaderPolicyPreset) *Client {
+	if c == nil {
+		return nil
+	}
+	r := newDefaultRedactor()
+	switch preset {
+	case PolicyMinimal:
+		r.config.AllowedHeaders = copyStrings(minimalHeaders)
+	case PolicyStrictPII:
+		r.config.AllowedHeaders = copyStrings(strictPIIHeaders)
+		r.config.DropCookies = true
+		r.config.DropIPAddress = true
+	default:
+		preset = PolicyStandard
+	}
+	r.config.Policy = preset
+	r.invalid = c.redaction.invalid
+	c.redaction = r
+	return c
+}
+
+// AllowHeaders is a chainable option-setting method to add header names to
+// the allowlist of a policy capturing only some headers, like PolicyMinimal
+// and PolicyStrictPII. Allowing the Cookie header captures cookies again
+// unless the policy drops them. Without an allowlist, all headers are
+// captured already and AllowHeaders has no effect.
+func (c *Client) AllowHeaders(names ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	if c.redaction.config.AllowedHeaders != nil {
+		c.redaction.config.AllowedHeaders = append(c.redaction.config.AllowedHeaders, names...)
+	}
+	return c
+}
+
+// restrictRequest removes the headers, cookies and client IP address the
+// policy does not capture from the given request data.
+func (r redactor) restrictRequest(d *RequestData) {
+	allowed := r.config.AllowedHeaders
+	if allowed != nil && d.Headers != nil {
+		headers := make(map[string]string, len(d.Headers))
+		for name, value := range d.Headers {
+			if containsFold(allowed, name) {
+				headers[name] = value
+			}
+		}
+		d.Headers = headers
+	}
+	if r.config.DropCookies || (allowed != nil && !containsFold(allowed, "Cookie")) {
+		d.cookies = nil
+	}
+	if r.config.DropIPAddress {
+		d.IPAddress = ""
+	}
+}
@@ -0,0 +1,100 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHeaderPolicy(t *testing.T) {
+	Convey("HeaderPolicy", t, func() {
+		r := httptest.NewRequest("POST", "/orders?token=abc", nil)
+		r.RemoteAddr = "203.0.113.7:4711"
+		for name, value := range map[string]string{
+			"Accept":          "application/json",
+			"Accept-Language": "en",
+			"Authorization":   "Bearer abc",
+			"Content-Type":    "application/json",
+			"Cookie":          "session=s3cr3t",
+			"Referer":         "https://example.com/cart",
+			"User-Agent":      "shop/1.0",
+			"X-Forwarded-For": "198.51.100.1",
+			"X-Request-Id":    "req-1",
+		} {
+			r.Header.Set(name, value)
+		}
+
+		c, _ := New("app", "key")
+		c.WireFormat(WireFormatV2).Request(r)
+
+		// request returns the request data of a report.
+		request := func() RequestData {
+			return c.createPost(errors.New("Test HeaderPolicy"), StackTrace{}).Details.Request
+		}
+		// headerNames returns the names of the captured headers, sorted.
+		headerNames := func(d RequestData) []string {
+			var names []string
+			for name := range d.Headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names
+		}
+
+		Convey("PolicyStandard captures all headers with redaction", func() {
+			c.HeaderPolicy(PolicyStandard)
+			d := request()
+			So(headerNames(d), ShouldResemble, []string{
+				"Accept", "Accept-Language", "Authorization", "Content-Type", "Cookie",
+				"Referer", "User-Agent", "X-Forwarded-For", "X-Request-Id",
+			})
+			So(d.Headers["Authorization"], ShouldEqual, redactedValue)
+			So(d.cookies, ShouldResemble, map[string]string{"session": redactedValue})
+			So(d.IPAddress, ShouldEqual, "203.0.113.7:4711")
+			So(c.EffectiveRedactionConfig().Policy, ShouldEqual, PolicyStandard)
+		})
+
+		Convey("PolicyMinimal captures the method, host, user agent and content type", func() {
+			c.HeaderPolicy(PolicyMinimal)
+			d := request()
+			So(headerNames(d), ShouldResemble, []string{"Content-Type", "User-Agent"})
+			So(d.HTTPMethod, ShouldEqual, "POST")
+			So(d.HostName, ShouldEqual, "example.com")
+			So(d.cookies, ShouldBeNil)
+			So(d.IPAddress, ShouldEqual, "203.0.113.7:4711")
+		})
+
+		Convey("PolicyStrictPII captures allowlisted headers only, no cookies and no IP", func() {
+			c.HeaderPolicy(PolicyStrictPII)
+			d := request()
+			So(headerNames(d), ShouldResemble, []string{"Accept", "Accept-Language", "Content-Type", "User-Agent"})
+			So(d.cookies, ShouldBeNil)
+			So(d.IPAddress, ShouldEqual, "")
+			So(d.URL, ShouldEqual, "/orders?token=%5BREDACTED%5D")
+
+			config := c.EffectiveRedactionConfig()
+			So(config.Policy, ShouldEqual, PolicyStrictPII)
+			So(config.AllowedHeaders, ShouldResemble, StrictPIIHeaders())
+			So(config.DropCookies, ShouldBeTrue)
+			So(config.DropIPAddress, ShouldBeTrue)
+		})
+
+		Convey("is adjusted by further options", func() {
+			c.HeaderPolicy(PolicyStrictPII).AllowHeaders("X-Request-Id", "Cookie").RedactHeaders("User-Agent")
+			d := request()
+			So(headerNames(d), ShouldResemble, []string{"Accept", "Accept-Language", "Content-Type", "Cookie", "User-Agent", "X-Request-Id"})
+			So(d.Headers["User-Agent"], ShouldEqual, redactedValue)
+			So(d.Headers["Cookie"], ShouldEqual, redactedValue)
+			So(d.cookies, ShouldBeNil)
+		})
+
+		Convey("AllowHeaders has no effect without an allowlist", func() {
+			c.AllowHeaders("X-Request-Id")
+			So(request().Headers, ShouldHaveLength, 9)
+			So(c.EffectiveRedactionConfig().AllowedHeaders, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,43 @@
+package raygun4go
+
+import "net/http"
+
+// userAgent is the User-Agent header of submissions.
+const userAgent = "raygun4go/" + packageVersion
+
+// ExtraHeaders is a chainable option-setting method to set additional
+// headers sent with every submission, e.g. a routing header required by an
+// egress proxy. They cannot replace the headers set by raygun4go itself,
+// i.e. X-ApiKey, Content-Type, Content-Encoding, User-Agent and
+// Idempotency-Key. The map is copied; passing nil removes all extra headers.
+func (c *Client) ExtraHeaders(headers map[string]string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.extraHeaders = nil
+	if len(headers) > 0 {
+		c.extraHeaders = make(http.Header, len(headers))
+		for name, value := range headers {
+			c.extraHeaders.Set(name, value)
+		}
+	}
+	return c
+}
+
+// setHeaders sets the headers of the given request posting the given
+// payload. The extra headers are set first, so the ones set by raygun4go
+// replace them.
+func (c *Client) setHeaders(r *http.Request, p payload) {
+	for name, values := range c.extraHeaders {
+		r.Header[name] = values
+	}
+	r.Header.Set("X-ApiKey", p.apiKey)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("User-Agent", userAgent)
+	if p.reportID != "" {
+		r.Header.Set(idempotencyKeyHeader, p.reportID)
+	}
+	if p.gzipped {
+		r.Header.Set("Content-Encoding", "gzip")
+	}
+}
@@ -0,0 +1,50 @@
+package raygun4go
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHeaders(t *testing.T) {
+	Convey("Submission headers", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("identify raygun4go and the JSON body", func() {
+			So(c.SendError(errors.New("Test Headers")), ShouldBeNil)
+			header := server.Requests()[0].Header
+			So(header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(header.Get("User-Agent"), ShouldEqual, "raygun4go/"+packageVersion)
+			So(header.Get("X-ApiKey"), ShouldEqual, "key")
+		})
+
+		Convey("include the extra headers", func() {
+			c.ExtraHeaders(map[string]string{"X-Route": "eu-west", "x-team": "payments"})
+			So(c.Clone().SendError(errors.New("Test Headers")), ShouldBeNil)
+			header := server.Requests()[0].Header
+			So(header.Get("X-Route"), ShouldEqual, "eu-west")
+			So(header.Get("X-Team"), ShouldEqual, "payments")
+
+			Convey("which can be removed", func() {
+				c.ExtraHeaders(nil)
+				So(c.SendError(errors.New("Test Headers")), ShouldBeNil)
+				So(server.Requests()[1].Header.Get("X-Route"), ShouldEqual, "")
+			})
+		})
+
+		Convey("cannot be replaced by the extra headers", func() {
+			c.ExtraHeaders(map[string]string{"x-apikey": "other", "Content-Type": "text/plain", "User-Agent": "curl"})
+			So(c.SendError(errors.New("Test Headers")), ShouldBeNil)
+			header := server.Requests()[0].Header
+			So(header["X-Apikey"], ShouldResemble, []string{"key"})
+			So(header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(header.Get("User-Agent"), ShouldEqual, "raygun4go/"+packageVersion)
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrReportCancelled is returned when a BeforeSend hook cancelled a report.
+var ErrReportCancelled = errors.New("report was cancelled by a BeforeSend hook")
+
+// BeforeSendFunc is called with every report right before it is sent. It may
+// change the report and returns false to cancel it.
+type BeforeSendFunc func(post *PostData) bool
+
+// registeredHook is a BeforeSendFunc together with its registration id.
+type registeredHook struct {
+	id int
+	fn BeforeSendFunc
+}
+
+// hookRegistry holds the BeforeSend hooks of a client and all its clones.
+type hookRegistry struct {
+	mu     sync.RWMutex
+	nextID int
+	hooks  []registeredHook
+}
+
+// register adds the given hook and returns its id.
+func (r *hookRegistry) register(fn BeforeSendFunc) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	r.hooks = append(r.hooks, registeredHook{id: r.nextID, fn: fn})
+	return r.nextID
+}
+
+// unregister removes the hook with the given id.
+func (r *hookRegistry) unregister(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, h := range r.hooks {
+		if h.id == id {
+			hooks := make([]registeredHook, 0, len(r.hooks)-1)
+			r.hooks = append(append(hooks, r.hooks[:i]...), r.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// clear removes all hooks.
+func (r *hookRegistry) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = nil
+}
+
+// run calls the hooks in registration order until one of them cancels the
+// report. Hooks registered while running only apply to later reports.
+func (r *hookRegistry) run(post *PostData) bool {
+	r.mu.RLock()
+	hooks := r.hooks
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		if !h.fn(post) {
+			return false
+		}
+	}
+	return true
+}
+
+// BeforeSend registers a hook that is called with every report right before
+// it is sent, e.g. to scrub data. Hooks run in registration order; once a hook
+// returns false, the report is cancelled, the remaining hooks are skipped and
+// the sending method returns ErrReportCancelled. Hooks are shared by a client
+// and all its clones. The returned function unregisters the hook.
+func (c *Client) BeforeSend(fn BeforeSendFunc) (unregister func()) {
+	if c == nil || fn == nil {
+		return func() {}
+	}
+	id := c.hooks.register(fn)
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.hooks.unregister(id) })
+	}
+}
+
+// ClearBeforeSendHooks is a chainable option-setting method removing all
+// hooks registered with BeforeSend.
+func (c *Client) ClearBeforeSendHooks() *Client {
+	if c == nil {
+		return nil
+	}
+	c.hooks.clear()
+	return c
+}
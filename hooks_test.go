@@ -0,0 +1,86 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBeforeSend(t *testing.T) {
+	Convey("BeforeSend", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		var calls []string
+		hook := func(name string, send bool) BeforeSendFunc {
+			return func(post *PostData) bool {
+				calls = append(calls, name)
+				post.Details.Tags = append(post.Details.Tags, name)
+				return send
+			}
+		}
+		post := func() PostData {
+			return c.createPost(errors.New("Test BeforeSend"), StackTrace{})
+		}
+
+		Convey("runs the hooks in registration order", func() {
+			c.BeforeSend(hook("first", true))
+			unregister := c.BeforeSend(hook("second", true))
+			c.BeforeSend(hook("third", true))
+			unregister()
+			unregister()
+
+			So(c.Submit(post()), ShouldBeNil)
+			So(calls, ShouldResemble, []string{"first", "third"})
+
+			sent, _ := server.next()
+			So(sent.Details.Tags, ShouldResemble, []string{"first", "third"})
+		})
+
+		Convey("stops at the first hook cancelling the report", func() {
+			c.BeforeSend(hook("first", true))
+			c.BeforeSend(hook("second", false))
+			c.BeforeSend(hook("third", true))
+
+			So(c.Submit(post()), ShouldEqual, ErrReportCancelled)
+			So(calls, ShouldResemble, []string{"first", "second"})
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("shares the hooks with clones", func() {
+			c.BeforeSend(hook("first", false))
+			So(c.Clone().Submit(post()), ShouldEqual, ErrReportCancelled)
+		})
+
+		Convey("#ClearBeforeSendHooks removes all hooks", func() {
+			c.BeforeSend(hook("first", false))
+			c.ClearBeforeSendHooks()
+
+			So(c.Submit(post()), ShouldBeNil)
+			So(calls, ShouldBeEmpty)
+		})
+
+		Convey("is safe to use while reports are sent", func() {
+			c.BeforeSend(func(*PostData) bool { return false })
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					unregister := c.BeforeSend(func(*PostData) bool { return true })
+					unregister()
+				}()
+				go func() {
+					defer wg.Done()
+					c.Submit(PostData{})
+				}()
+			}
+			wg.Wait()
+			So(len(server.posts), ShouldEqual, 0)
+		})
+	})
+}
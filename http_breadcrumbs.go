@@ -0,0 +1,67 @@
+package raygun4go
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// breadcrumbTransport records a breadcrumb for every request it sends.
+type breadcrumbTransport struct {
+	client *Client
+	base   http.RoundTripper
+}
+
+// InstrumentHTTPClient wraps the given transport, or http.DefaultTransport if
+// it is nil, to record a breadcrumb with the method, host, path, status and
+// duration of every outbound request. The breadcrumb is recorded on the
+// client found in the request's context, e.g. the scoped client of the
+// middleware, or on c if there is none. Failed requests and 5xx responses are
+// recorded at BreadcrumbError level. The redaction patterns are applied to the
+// host and path, the query string is never recorded.
+func (c *Client) InstrumentHTTPClient(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if c == nil {
+		return base
+	}
+	return &breadcrumbTransport{client: c, base: base}
+}
+
+// RoundTrip sends the request and records its breadcrumb.
+func (t *breadcrumbTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c := FromContext(r.Context())
+	if c == nil {
+		c = t.client
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(r)
+	duration := time.Since(start)
+
+	host := c.redaction.redactValue(r.URL.Host)
+	path := c.redaction.redactValue(r.URL.EscapedPath())
+	data := map[string]interface{}{
+		"method":     r.Method,
+		"host":       host,
+		"path":       path,
+		"durationMs": duration.Milliseconds(),
+	}
+
+	b := Breadcrumb{Level: BreadcrumbInfo, Category: "http", CustomData: data}
+	switch {
+	case err != nil:
+		b.Level = BreadcrumbError
+		b.Message = fmt.Sprintf("%s %s%s failed: %s", r.Method, host, path, c.redaction.redactValue(err.Error()))
+	default:
+		data["status"] = resp.StatusCode
+		if resp.StatusCode >= 500 {
+			b.Level = BreadcrumbError
+		}
+		b.Message = fmt.Sprintf("%s %s%s %d", r.Method, host, path, resp.StatusCode)
+	}
+	c.RecordBreadcrumb(b)
+
+	return resp, err
+}
@@ -0,0 +1,78 @@
+package raygun4go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInstrumentHTTPClient(t *testing.T) {
+	Convey("InstrumentHTTPClient", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		Reset(downstream.Close)
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		c, _ := New("app", "key")
+		client := &http.Client{Transport: c.InstrumentHTTPClient(nil)}
+
+		Convey("records breadcrumbs on the scoped client of the middleware", func() {
+			handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for _, u := range []string{downstream.URL + "/ok?token=secret", downstream.URL + "/fail", unreachable.URL + "/gone"} {
+					req, _ := http.NewRequestWithContext(r.Context(), "GET", u, nil)
+					if resp, err := client.Do(req); err == nil {
+						resp.Body.Close()
+					}
+				}
+				panic("Test InstrumentHTTPClient")
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+			post, ok := server.next()
+			So(ok, ShouldBeTrue)
+			crumbs := post.Details.Breadcrumbs
+			So(len(crumbs), ShouldEqual, 3)
+
+			host := downstream.Listener.Addr().String()
+			So(crumbs[0].Level, ShouldEqual, BreadcrumbInfo)
+			So(crumbs[0].Category, ShouldEqual, "http")
+			So(crumbs[0].Message, ShouldEqual, "GET "+host+"/ok 200")
+			So(crumbs[0].CustomData["method"], ShouldEqual, "GET")
+			So(crumbs[0].CustomData["host"], ShouldEqual, host)
+			So(crumbs[0].CustomData["path"], ShouldEqual, "/ok")
+			So(crumbs[0].CustomData["status"], ShouldEqual, 200)
+			So(crumbs[0].CustomData, ShouldContainKey, "durationMs")
+
+			So(crumbs[1].Level, ShouldEqual, BreadcrumbError)
+			So(crumbs[1].Message, ShouldEqual, "GET "+host+"/fail 503")
+
+			So(crumbs[2].Level, ShouldEqual, BreadcrumbError)
+			So(crumbs[2].CustomData, ShouldNotContainKey, "status")
+
+			So(c.breadcrumbs.snapshot(), ShouldBeNil)
+		})
+
+		Convey("records on the client itself outside of requests", func() {
+			resp, err := client.Get(downstream.URL + "/ok")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(len(c.breadcrumbs.snapshot()), ShouldEqual, 1)
+		})
+
+		Convey("applies the redaction patterns", func() {
+			resp, err := client.Get(downstream.URL + "/cards/4111111111111111")
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(c.breadcrumbs.snapshot()[0].CustomData["path"], ShouldEqual, "/cards/[REDACTED]")
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package raygun4go
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultSubmitTimeout is the time a submission request may take unless
+// configured otherwise.
+const defaultSubmitTimeout = 10 * time.Second
+
+// defaultHTTPClient posts the reports of clients without an HTTP client set
+// by HTTPClient. It is shared so connections are reused across reports, and
+// uses the proxy configured in the environment.
+var defaultHTTPClient = &http.Client{}
+
+// maxDrainedResponse is the number of bytes of a response body read before
+// closing it, so the connection can be reused for the next report.
+const maxDrainedResponse = 64 << 10
+
+// maxErrorBody is the number of bytes of the body of an unexpected response
+// kept in the SubmitError.
+const maxErrorBody = 1 << 10
+
+// HTTPClient is a chainable option-setting method to set the HTTP client
+// reports are posted with, e.g. to configure timeouts, a proxy or a custom
+// transport. By default, a client shared by all raygun4go clients is used.
+// Passing nil restores the default.
+func (c *Client) HTTPClient(client *http.Client) *Client {
+	if c == nil {
+		return nil
+	}
+	c.refuseLiveMode("HTTPClient")
+	c.httpClient = client
+	return c
+}
+
+// postClient returns the HTTP client reports are posted with.
+func (c *Client) postClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	if c.relayClient != nil {
+		return c.relayClient
+	}
+	if c.transportClient != nil {
+		return c.transportClient
+	}
+	return defaultHTTPClient
+}
+
+// closeResponse reads the rest of the body of the given response, up to
+// maxDrainedResponse, and closes it. The transport only reuses the connection
+// of responses whose body was read to the end.
+func closeResponse(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedResponse))
+	resp.Body.Close()
+}
+
+// readErrorBody returns the start of the body of an unexpected response, at
+// most maxErrorBody bytes without surrounding whitespace.
+func readErrorBody(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return strings.TrimSpace(strings.ToValidUTF8(string(body), "\uFFFD"))
+}
+
+// Timeout is a chainable option-setting method to set the maximum time a
+// request submitting a report may take, 10 seconds by default, whichever
+// HTTP client is used. Requests exceeding it fail with a *SubmitError whose
+// Timeout field is set and which wraps context.DeadlineExceeded. HandleError
+// thus blocks for at most this long, or the panic submit budget if shorter.
+// A non-positive duration restores the default.
+func (c *Client) Timeout(d time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	if d <= 0 {
+		d = defaultSubmitTimeout
+	}
+	c.submitTimeout = d
+	return c
+}
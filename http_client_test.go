@@ -0,0 +1,186 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordingTransport answers all requests with 202 and records them.
+type recordingTransport struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, r)
+	t.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusAccepted,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    r,
+	}, nil
+}
+
+func (t *recordingTransport) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.requests)
+}
+
+func TestHTTPClient(t *testing.T) {
+	Convey("HTTPClient", t, func() {
+		transport := &recordingTransport{}
+		c, _ := New("app", "key")
+		c.HTTPClient(&http.Client{Transport: transport})
+
+		Convey("is used to post reports", func() {
+			So(c.SendError(errors.New("Test HTTPClient")), ShouldBeNil)
+			So(transport.count(), ShouldEqual, 1)
+			r := transport.requests[0]
+			So(r.Method, ShouldEqual, "POST")
+			So(r.URL.String(), ShouldEqual, raygunEndpoint+"/entries")
+			So(r.Header.Get("X-ApiKey"), ShouldEqual, "key")
+		})
+
+		Convey("is carried over by Clone", func() {
+			So(c.Clone().SendError(errors.New("Test HTTPClient")), ShouldBeNil)
+			So(transport.count(), ShouldEqual, 1)
+		})
+
+		Convey("falls back to a shared default", func() {
+			c.HTTPClient(nil)
+			So(c.postClient(), ShouldEqual, defaultHTTPClient)
+			other, _ := New("app", "key")
+			So(other.postClient(), ShouldEqual, defaultHTTPClient)
+		})
+	})
+}
+
+// newHandshakeCountingServer returns a TLS server accepting all reports and
+// the number of connections it accepted.
+func newHandshakeCountingServer() (*httptest.Server, *int64) {
+	var handshakes int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"accepted":true}`)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&handshakes, 1)
+		}
+	}
+	server.StartTLS()
+	return server, &handshakes
+}
+
+func TestConnectionReuse(t *testing.T) {
+	Convey("Submissions reuse connections", t, func() {
+		server, handshakes := newHandshakeCountingServer()
+		client := defaultHTTPClient
+		defaultHTTPClient = server.Client()
+		Reset(func() {
+			defaultHTTPClient = client
+			server.Close()
+		})
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		for i := 0; i < 20; i++ {
+			So(c.Clone().SendError(errors.New("Test ConnectionReuse")), ShouldBeNil)
+		}
+		So(atomic.LoadInt64(handshakes), ShouldEqual, 1)
+	})
+}
+
+// BenchmarkSubmit submits reports over TLS with the shared HTTP client and
+// with a new client per submission, reporting the TLS handshakes per report.
+func BenchmarkSubmit(b *testing.B) {
+	server, handshakes := newHandshakeCountingServer()
+	defer server.Close()
+	client := defaultHTTPClient
+	defaultHTTPClient = server.Client()
+	defer func() { defaultHTTPClient = client }()
+
+	c, _ := New("app", "key")
+	c.Endpoint(server.URL)
+	post := c.createPost(errors.New("Benchmark Submit"), StackTrace{})
+	transport := server.Client().Transport.(*http.Transport)
+
+	run := func(b *testing.B, client func() *Client) {
+		atomic.StoreInt64(handshakes, 0)
+		for i := 0; i < b.N; i++ {
+			if err := client().Submit(post); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(atomic.LoadInt64(handshakes))/float64(b.N), "handshakes/op")
+	}
+
+	b.Run("shared", func(b *testing.B) {
+		run(b, func() *Client { return c })
+	})
+	b.Run("per-submission", func(b *testing.B) {
+		run(b, func() *Client {
+			return c.Clone().HTTPClient(&http.Client{Transport: transport.Clone()})
+		})
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	Convey("Timeout", t, func() {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		Reset(func() {
+			close(release)
+			server.Close()
+		})
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("defaults to 10 seconds", func() {
+			So(c.submitTimeout, ShouldEqual, 10*time.Second)
+			So(c.Timeout(0).submitTimeout, ShouldEqual, 10*time.Second)
+		})
+
+		Convey("limits submissions", func() {
+			c.Timeout(50 * time.Millisecond)
+			start := time.Now()
+			err := c.Clone().SendError(errors.New("Test Timeout"))
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeTrue)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("limits how long HandleError blocks", func() {
+			c.Timeout(50 * time.Millisecond)
+			start := time.Now()
+			func() {
+				defer c.HandleError()
+				panic("Test Timeout")
+			}()
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package raygun4go
+
+import (
+	"errors"
+	"strconv"
+)
+
+// defaultHTTPStatusThreshold is the status up to which errors carrying an
+// HTTP status are not reported unless configured otherwise.
+const defaultHTTPStatusThreshold = 499
+
+// ResponseData holds information on the response sent for the request of a
+// report.
+type ResponseData struct {
+	StatusCode int `json:"statusCode"`
+}
+
+// statusCoder is implemented by errors carrying an HTTP status.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// ReportHTTPErrorsAbove is a chainable option-setting method to set the HTTP
+// status up to which SendError drops errors carrying a status, i.e. errors
+// implementing
+//
+//	interface{ StatusCode() int }
+//
+// or wrapping one. The default of 499 drops client errors and reports server
+// errors. Reported errors are tagged "http-status:<code>" and the status is
+// sent in the response section. Errors without a status are not affected. See
+// WithHTTPStatusThreshold to override the threshold for a single call.
+func (c *Client) ReportHTTPErrorsAbove(status int) *Client {
+	if c == nil {
+		return nil
+	}
+	c.httpStatusThreshold = status
+	return c
+}
+
+// WithHTTPStatusThreshold overrides the threshold set by ReportHTTPErrorsAbove
+// for a single report.
+func WithHTTPStatusThreshold(status int) ReportOption {
+	return func(o *reportOptions) {
+		o.httpStatusThreshold = status
+		o.hasHTTPStatusThreshold = true
+	}
+}
+
+// httpStatusOf returns the HTTP status carried by err or an error it wraps.
+func httpStatusOf(err error) (int, bool) {
+	var sc statusCoder
+	if !errors.As(err, &sc) {
+		return 0, false
+	}
+	return sc.StatusCode(), true
+}
+
+// dropHTTPError reports whether err carries an HTTP status at or below the
+// threshold, and records the status in the given options otherwise.
+func (c *Client) dropHTTPError(err error, opts *reportOptions) bool {
+	status, ok := httpStatusOf(err)
+	if !ok {
+		return false
+	}
+	threshold := c.httpStatusThreshold
+	if opts.hasHTTPStatusThreshold {
+		threshold = opts.httpStatusThreshold
+	}
+	if status <= threshold {
+		return true
+	}
+	opts.httpStatus = status
+	return false
+}
+
+// addHTTPStatus sets the response status of the given post and returns its
+// http-status tag, if the options carry a status.
+func addHTTPStatus(post *PostData, opts reportOptions) []string {
+	if opts.httpStatus == 0 {
+		return nil
+	}
+	post.Details.Response = &ResponseData{StatusCode: opts.httpStatus}
+	return []string{"http-status:" + strconv.Itoa(opts.httpStatus)}
+}
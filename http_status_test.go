@@ -0,0 +1,83 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// statusError is an error carrying an HTTP status.
+type statusError struct{ status int }
+
+func (e statusError) Error() string   { return fmt.Sprintf("Test status %d", e.status) }
+func (e statusError) StatusCode() int { return e.status }
+
+func TestReportHTTPErrorsAbove(t *testing.T) {
+	Convey("HTTP status errors", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+
+		Convey("are dropped up to 499 by default", func() {
+			So(c.SendError(statusError{404}), ShouldBeNil)
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("are reported with a tag and the response status above", func() {
+			So(c.Tags([]string{"api"}).SendError(statusError{503}), ShouldBeNil)
+			post, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(post.Details.Tags, ShouldResemble, []string{"api", "http-status:503"})
+			So(post.Details.Response, ShouldResemble, &ResponseData{StatusCode: 503})
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("are recognized when wrapped", func() {
+			c.SendError(fmt.Errorf("Test wrapped (%w)", statusError{404}))
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("use the configured threshold", func() {
+			c.ReportHTTPErrorsAbove(399)
+			c.SendError(statusError{404})
+			post, _ := server.next()
+			So(post.Details.Response.StatusCode, ShouldEqual, 404)
+		})
+
+		Convey("use the threshold given for a call", func() {
+			c.SendError(statusError{404}, WithHTTPStatusThreshold(0))
+			c.SendError(statusError{503}, WithHTTPStatusThreshold(503))
+			So(len(server.posts), ShouldEqual, 1)
+			post, _ := server.next()
+			So(post.Details.Response.StatusCode, ShouldEqual, 404)
+		})
+
+		Convey("do not affect other errors", func() {
+			c.SendError(fmt.Errorf("Test plain error"))
+			post, _ := server.next()
+			So(post.Details.Response, ShouldBeNil)
+		})
+	})
+
+	Convey("The response section", t, func() {
+		c, _ := New("app", "key")
+		post := c.createPost(fmt.Errorf("Test response section"), StackTrace{})
+
+		Convey("is left out if not set", func() {
+			b, _ := json.Marshal(post)
+			So(string(b), ShouldNotContainSubstring, `"response"`)
+		})
+
+		Convey("is sent in both wire formats", func() {
+			post.Details.Response = &ResponseData{StatusCode: 503}
+			b, _ := json.Marshal(post)
+			So(string(b), ShouldContainSubstring, `"response":{"statusCode":503}`)
+			post.wireFormat = WireFormatV2
+			b, _ = json.Marshal(post)
+			So(string(b), ShouldContainSubstring, `"response":{"statusCode":503}`)
+		})
+	})
+}
@@ -0,0 +1,223 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultImportConcurrency is the number of reports ImportReports sends at
+// once unless ImportConcurrency is given.
+const defaultImportConcurrency = 4
+
+// ErrImportStopped is the error of the reports ImportReports skipped after
+// another report failed.
+var ErrImportStopped = errors.New("import was stopped after a report failed")
+
+// ImportDisposition describes what happened to a report passed to
+// ImportReports.
+type ImportDisposition int
+
+const (
+	ImportSkipped   ImportDisposition = iota // the report was not sent, because the import was stopped or ctx was done
+	ImportSent                               // the report was accepted by Raygun
+	ImportFailed                             // sending the report failed
+	ImportCancelled                          // a BeforeSend hook cancelled the report
+)
+
+// String returns the name of the disposition.
+func (d ImportDisposition) String() string {
+	switch d {
+	case ImportSent:
+		return "sent"
+	case ImportFailed:
+		return "failed"
+	case ImportCancelled:
+		return "cancelled"
+	default:
+		return "skipped"
+	}
+}
+
+// ImportItem is the result of importing one report.
+type ImportItem struct {
+	Disposition ImportDisposition
+	Err         error // the reason the report was not sent, nil if it was
+}
+
+// ImportResult is the result of ImportReports.
+type ImportResult struct {
+	Items []ImportItem // the results, in the order of the reports passed
+
+	Sent, Failed, Cancelled, Skipped int // the number of items with each disposition
+}
+
+// importConfig holds the settings applied by ImportOptions.
+type importConfig struct {
+	concurrency     int                   // the number of reports sent at once
+	interval        time.Duration         // the minimum time between the start of two submissions, 0 for no limit
+	continueOnError bool                  // if true, failed reports do not stop the import
+	progress        func(done, total int) // called after each report, if set
+}
+
+// ImportOption configures ImportReports.
+type ImportOption func(*importConfig)
+
+// ImportConcurrency sets the number of reports ImportReports sends at once,
+// 4 by default.
+func ImportConcurrency(n int) ImportOption {
+	return func(cfg *importConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// ImportRateLimit limits ImportReports to sending perSecond reports per
+// second. By default, reports are sent as fast as the concurrency allows.
+func ImportRateLimit(perSecond float64) ImportOption {
+	return func(cfg *importConfig) {
+		if perSecond > 0 {
+			cfg.interval = time.Duration(float64(time.Second) / perSecond)
+		}
+	}
+}
+
+// ImportContinueOnError makes ImportReports send all reports even if some of
+// them fail. By default, it stops at the first failure and the reports not
+// sent yet are skipped with ErrImportStopped. Reports being sent at the time
+// are finished.
+func ImportContinueOnError() ImportOption {
+	return func(cfg *importConfig) {
+		cfg.continueOnError = true
+	}
+}
+
+// ImportProgress sets a function ImportReports calls after each report with
+// the number of reports handled so far and the total. Calls may come from
+// several goroutines, but never concurrently.
+func ImportProgress(fn func(done, total int)) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.progress = fn
+	}
+}
+
+// ImportReports sends the given reports to Raygun, e.g. to import errors
+// recorded by another tool, and returns the result of each. The reports are
+// sent as they are, keeping their occurredOn, and bypass deduplication as
+// well as the asynchronous queue; the BeforeSend hooks still apply. Once ctx
+// is done, the reports not sent yet are skipped with its error.
+func (c *Client) ImportReports(ctx context.Context, reports []PostData, opts ...ImportOption) ImportResult {
+	result := ImportResult{Items: make([]ImportItem, len(reports))}
+	if c == nil {
+		for i := range result.Items {
+			result.Items[i] = ImportItem{Disposition: ImportFailed, Err: ErrNoClient}
+		}
+		result.Failed = len(reports)
+		return result
+	}
+
+	cfg := importConfig{concurrency: defaultImportConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	skipReason := func() error {
+		select {
+		case <-stop:
+			return ErrImportStopped
+		default:
+			return ctx.Err()
+		}
+	}
+
+	var ticks <-chan time.Time
+	if cfg.interval > 0 {
+		ticker := time.NewTicker(cfg.interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	var mu sync.Mutex // guards result and the progress callback
+	done := 0
+	finish := func(i int, item ImportItem) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Items[i] = item
+		done++
+		if cfg.progress != nil {
+			cfg.progress(done, len(reports))
+		}
+	}
+
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for w := 0; w < cfg.concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				if err := skipReason(); err != nil {
+					finish(i, ImportItem{Disposition: ImportSkipped, Err: err})
+					continue
+				}
+				item := c.importReport(ctx, reports[i])
+				if item.Disposition == ImportFailed && !cfg.continueOnError {
+					stopOnce.Do(func() { close(stop) })
+				}
+				finish(i, item)
+			}
+		}()
+	}
+
+	for i := range reports {
+		if skipReason() == nil && ticks != nil && i > 0 {
+			select {
+			case <-ticks:
+			case <-ctx.Done():
+			case <-stop:
+			}
+		}
+		if err := skipReason(); err != nil {
+			finish(i, ImportItem{Disposition: ImportSkipped, Err: err})
+			continue
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			finish(i, ImportItem{Disposition: ImportSkipped, Err: ctx.Err()})
+		case <-stop:
+			finish(i, ImportItem{Disposition: ImportSkipped, Err: ErrImportStopped})
+		}
+	}
+	close(indexes)
+	workers.Wait()
+
+	for _, item := range result.Items {
+		switch item.Disposition {
+		case ImportSent:
+			result.Sent++
+		case ImportFailed:
+			result.Failed++
+		case ImportCancelled:
+			result.Cancelled++
+		default:
+			result.Skipped++
+		}
+	}
+	return result
+}
+
+// importReport sends a single report for ImportReports.
+func (c *Client) importReport(ctx context.Context, post PostData) ImportItem {
+	if !c.hooks.run(&post) {
+		return ImportItem{Disposition: ImportCancelled, Err: ErrReportCancelled}
+	}
+	if err := c.submitCoreWithContext(ctx, post); err != nil {
+		return ImportItem{Disposition: ImportFailed, Err: err}
+	}
+	return ImportItem{Disposition: ImportSent}
+}
@@ -0,0 +1,135 @@
+package raygun4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImportReports(t *testing.T) {
+	Convey("ImportReports", t, func() {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		var received []PostData
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			var post PostData
+			json.NewDecoder(r.Body).Decode(&post)
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			received = append(received, post)
+			mu.Unlock()
+			if strings.HasPrefix(post.Details.Error.Message, "reject") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defaultEndpoint := raygunEndpoint
+		raygunEndpoint = server.URL
+		Reset(func() {
+			raygunEndpoint = defaultEndpoint
+			server.Close()
+		})
+
+		c, _ := New("app", "key")
+		c.dedup = newDeduplicator(time.Hour)
+		report := func(message string) PostData {
+			post := c.createPost(errors.New(message), StackTrace{})
+			post.OccuredOn = "2019-05-06T07:08:09Z"
+			return post
+		}
+		reports := []PostData{
+			report("accept"), report("reject"), report("accept"), report("accept"),
+			report("reject"), report("accept"), report("accept"), report("accept"),
+		}
+
+		Convey("reports the result of each item", func() {
+			result := c.ImportReports(context.Background(), reports, ImportConcurrency(2), ImportContinueOnError())
+			So(result.Sent, ShouldEqual, 6)
+			So(result.Failed, ShouldEqual, 2)
+			for i, item := range result.Items {
+				if reports[i].Details.Error.Message == "reject" {
+					So(item.Disposition, ShouldEqual, ImportFailed)
+					So(item.Err.Error(), ShouldContainSubstring, "400")
+				} else {
+					So(item.Disposition, ShouldEqual, ImportSent)
+					So(item.Err, ShouldBeNil)
+				}
+			}
+		})
+
+		Convey("bounds the concurrency", func() {
+			c.ImportReports(context.Background(), reports, ImportConcurrency(2), ImportContinueOnError())
+			So(maxInFlight, ShouldBeBetweenOrEqual, 1, 2)
+		})
+
+		Convey("keeps occurredOn and bypasses deduplication", func() {
+			c.ImportReports(context.Background(), reports, ImportContinueOnError())
+			So(received, ShouldHaveLength, len(reports))
+			for _, post := range received {
+				So(post.OccuredOn, ShouldEqual, "2019-05-06T07:08:09Z")
+			}
+		})
+
+		Convey("stops at the first failure by default", func() {
+			result := c.ImportReports(context.Background(), reports, ImportConcurrency(1))
+			So(result.Items[0].Disposition, ShouldEqual, ImportSent)
+			So(result.Items[1].Disposition, ShouldEqual, ImportFailed)
+			So(result.Skipped, ShouldEqual, len(reports)-2)
+			for _, item := range result.Items[2:] {
+				So(item.Err, ShouldEqual, ErrImportStopped)
+			}
+		})
+
+		Convey("skips the remaining reports once ctx is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			progress, reportedTotal := 0, 0
+			result := c.ImportReports(ctx, reports, ImportConcurrency(1), ImportContinueOnError(), ImportProgress(func(done, total int) {
+				progress, reportedTotal = done, total
+				if done == 3 {
+					cancel()
+				}
+			}))
+			So(progress, ShouldEqual, len(reports))
+			So(reportedTotal, ShouldEqual, len(reports))
+			So(result.Sent+result.Failed, ShouldEqual, 3)
+			So(result.Items[len(reports)-1].Err, ShouldEqual, context.Canceled)
+		})
+
+		Convey("limits the rate", func() {
+			start := time.Now()
+			c.ImportReports(context.Background(), reports[:3], ImportRateLimit(20), ImportContinueOnError())
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+		})
+
+		Convey("applies the BeforeSend hooks", func() {
+			c.BeforeSend(func(post *PostData) bool {
+				return post.Details.Error.Message != "reject"
+			})
+			result := c.ImportReports(context.Background(), reports)
+			So(result.Sent, ShouldEqual, 6)
+			So(result.Cancelled, ShouldEqual, 2)
+			So(result.Items[1].Err, ShouldEqual, ErrReportCancelled)
+		})
+	})
+}
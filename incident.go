@@ -0,0 +1,70 @@
+package raygun4go
+
+import (
+	"sync"
+	"time"
+)
+
+// incidentRefKey is the custom data key the incident reference is stored
+// under.
+const incidentRefKey = "incidentRef"
+
+// incidentReference holds the incident reference of a client and all its
+// clones.
+type incidentReference struct {
+	mu      sync.RWMutex
+	ref     string
+	expires time.Time // the time the reference is cleared at, zero if never
+}
+
+// set replaces the reference.
+func (i *incidentReference) set(ref string, expires time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.ref = ref
+	i.expires = expires
+}
+
+// get returns the reference unless it expired by now.
+func (i *incidentReference) get(now time.Time) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if !i.expires.IsZero() && !now.Before(i.expires) {
+		return ""
+	}
+	return i.ref
+}
+
+// SetIncidentReference is a chainable option-setting method to set a
+// reference, e.g. the ID of an ongoing incident, that is added to every report
+// until ClearIncidentReference is called: as an "incident:<ref>" tag and as
+// "incidentRef" in the custom data. The reference is shared by the client and
+// all its clones and may be changed while reports are being sent; each report
+// carries the reference set when it was created.
+func (c *Client) SetIncidentReference(ref string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.incident.set(ref, time.Time{})
+	return c
+}
+
+// SetIncidentReferenceFor is like SetIncidentReference, but the reference is
+// cleared automatically once the given time has passed.
+func (c *Client) SetIncidentReferenceFor(ref string, ttl time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	c.incident.set(ref, c.clock().Add(ttl))
+	return c
+}
+
+// ClearIncidentReference is a chainable option-setting method removing the
+// reference set by SetIncidentReference.
+func (c *Client) ClearIncidentReference() *Client {
+	if c == nil {
+		return nil
+	}
+	c.incident.set("", time.Time{})
+	return c
+}
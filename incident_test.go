@@ -0,0 +1,74 @@
+package raygun4go
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIncidentReference(t *testing.T) {
+	Convey("IncidentReference", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return now }
+		c.Tags([]string{"api"})
+		send := func(c *Client) PostData {
+			So(c.SendError(errors.New("Test IncidentReference")), ShouldBeNil)
+			post, _ := server.next()
+			return post
+		}
+
+		Convey("is added to reports until cleared", func() {
+			c.SetIncidentReference("INC-42")
+			post := send(c)
+			So(post.Details.Tags, ShouldResemble, []string{"api", "incident:INC-42"})
+			So(withoutReportID(post), ShouldResemble, map[string]interface{}{"incidentRef": "INC-42"})
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+
+			c.ClearIncidentReference()
+			post = send(c)
+			So(post.Details.Tags, ShouldResemble, []string{"api"})
+			So(withoutReportID(post), ShouldBeNil)
+		})
+
+		Convey("is shared by clones", func() {
+			clone := c.Clone()
+			c.SetIncidentReference("INC-42")
+			So(send(clone).Details.Tags, ShouldResemble, []string{"api", "incident:INC-42"})
+		})
+
+		Convey("#SetIncidentReferenceFor clears the reference after the TTL", func() {
+			c.SetIncidentReferenceFor("INC-43", time.Hour)
+			now = now.Add(59 * time.Minute)
+			So(send(c).Details.Tags, ShouldResemble, []string{"api", "incident:INC-43"})
+
+			now = now.Add(time.Minute)
+			So(send(c).Details.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("can be changed while reports are created", func() {
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					c.SetIncidentReference("INC-44")
+					c.ClearIncidentReference()
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					c.createPost(errors.New("Test IncidentReference"), StackTrace{})
+				}
+			}()
+			wg.Wait()
+		})
+	})
+}
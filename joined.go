@@ -0,0 +1,136 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	// defaultJoinedErrorReports is the number of reports sent for an error
+	// joining several errors unless configured otherwise.
+	defaultJoinedErrorReports = 5
+
+	// maxOmittedMessageLength is the number of characters the messages of
+	// omitted joined errors are truncated to.
+	maxOmittedMessageLength = 100
+
+	// The custom data keys and the tag prefix of the reports sent for joined
+	// errors.
+	joinedErrorsIDKey          = "joinedErrorsId"
+	omittedJoinedErrorsKey     = "omittedJoinedErrors"
+	omittedJoinedMessagesKey   = "omittedJoinedErrorMessages"
+	joinedErrorsCorrelationTag = "joined-errors"
+)
+
+// ReportJoinedErrors is a chainable option-setting method to make SendError
+// send one report per error joined by an error such as one returned by
+// errors.Join, instead of a single report for all of them. Joined errors that
+// join errors themselves are flattened.
+//
+// At most max reports are sent, see MaxJoinedErrorReports. The remaining
+// errors are summarized in the custom data of the first report, under
+// "omittedJoinedErrors" and "omittedJoinedErrorMessages". All reports share a
+// "joined-errors:<id>" tag and carry the id in their custom data under
+// "joinedErrorsId", so they can be found together.
+func (c *Client) ReportJoinedErrors(separately bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.splitJoinedErrors = separately
+	return c
+}
+
+// MaxJoinedErrorReports is a chainable option-setting method to set the
+// maximum number of reports sent for a single joined error, see
+// ReportJoinedErrors. The default is 5. Values below 1 are ignored.
+func (c *Client) MaxJoinedErrorReports(max int) *Client {
+	if c == nil {
+		return nil
+	}
+	if max >= 1 {
+		c.maxJoinedErrorReports = max
+	}
+	return c
+}
+
+// joinedErrors returns the errors joined by err, flattening nested joins. It
+// returns nil if err does not join several errors.
+func joinedErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	var leaves []error
+	for _, e := range joined.Unwrap() {
+		if e == nil {
+			continue
+		}
+		if nested := joinedErrors(e); nested != nil {
+			leaves = append(leaves, nested...)
+		} else {
+			leaves = append(leaves, e)
+		}
+	}
+	if len(leaves) < 2 {
+		return nil
+	}
+	return leaves
+}
+
+// sendJoinedErrors sends a report for each of the given errors up to the
+// configured maximum, summarizing the rest in the first report. It returns the
+// first error sending a report.
+func (c *Client) sendJoinedErrors(ctx context.Context, leaves []error, st StackTrace, opts reportOptions) error {
+	id := uuid.New()
+	sent := leaves
+	if len(sent) > c.maxJoinedErrorReports {
+		sent = leaves[:c.maxJoinedErrorReports]
+	}
+
+	var result error
+	for i, leaf := range sent {
+		o := opts
+		o.customData = make(map[string]interface{}, len(opts.customData)+3)
+		for k, v := range opts.customData {
+			o.customData[k] = v
+		}
+		o.addCustomData(joinedErrorsIDKey, id)
+		if i == 0 && len(leaves) > len(sent) {
+			omitted := leaves[len(sent):]
+			messages := make([]string, len(omitted))
+			for j, e := range omitted {
+				messages[j] = truncateMessage(e.Error())
+			}
+			o.addCustomData(omittedJoinedErrorsKey, len(omitted))
+			o.addCustomData(omittedJoinedMessagesKey, messages)
+		}
+
+		o.runtimeTags = append(copyStrings(opts.runtimeTags), joinedErrorsCorrelationTag+":"+id)
+
+		post := c.createPostWithOptions(errors.New(leaf.Error()), stackOf(leaf, st), o)
+		if err := c.SubmitWithContext(ctx, post); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// stackOf returns the stack trace embedded in err, see embeddedStack, or the
+// given fallback if there is none.
+func stackOf(err error, fallback StackTrace) StackTrace {
+	if st, ok := embeddedStack(err); ok {
+		return st
+	}
+	return fallback
+}
+
+// truncateMessage truncates the given message to maxOmittedMessageLength
+// characters.
+func truncateMessage(message string) string {
+	if runes := []rune(message); len(runes) > maxOmittedMessageLength {
+		return string(runes[:maxOmittedMessageLength]) + "…"
+	}
+	return message
+}
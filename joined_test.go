@@ -0,0 +1,112 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	goerrors "github.com/go-errors/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReportJoinedErrors(t *testing.T) {
+	Convey("#ReportJoinedErrors", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		leaves := make([]error, 10)
+		for i := range leaves {
+			leaves[i] = fmt.Errorf("Test error %d", i)
+		}
+		joined := errors.Join(leaves...)
+		customData := func(post PostData) map[string]interface{} {
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			return data
+		}
+		correlationTag := func(post PostData) string {
+			for _, tag := range post.Details.Tags {
+				if strings.HasPrefix(tag, "joined-errors:") {
+					return tag
+				}
+			}
+			return ""
+		}
+
+		Convey("sends a single report by default", func() {
+			So(c.SendError(joined), ShouldBeNil)
+			So(len(server.posts), ShouldEqual, 1)
+		})
+
+		Convey("sends a report per joined error up to the cap", func() {
+			c.ReportJoinedErrors(true).MaxJoinedErrorReports(3)
+			So(c.SendError(joined), ShouldBeNil)
+			So(len(server.posts), ShouldEqual, 3)
+
+			first, _ := server.next()
+			second, _ := server.next()
+			third, _ := server.next()
+			So(first.Details.Error.Message, ShouldEqual, "Test error 0")
+			So(second.Details.Error.Message, ShouldEqual, "Test error 1")
+			So(third.Details.Error.Message, ShouldEqual, "Test error 2")
+
+			So(customData(first)["omittedJoinedErrors"], ShouldEqual, 7)
+			So(customData(first)["omittedJoinedErrorMessages"], ShouldResemble, []interface{}{
+				"Test error 3", "Test error 4", "Test error 5", "Test error 6",
+				"Test error 7", "Test error 8", "Test error 9",
+			})
+			So(customData(second), ShouldNotContainKey, "omittedJoinedErrors")
+
+			tag := correlationTag(first)
+			So(tag, ShouldNotBeEmpty)
+			So(correlationTag(second), ShouldEqual, tag)
+			So(correlationTag(third), ShouldEqual, tag)
+			So(customData(third)["joinedErrorsId"], ShouldEqual, strings.TrimPrefix(tag, "joined-errors:"))
+		})
+
+		Convey("caps at 5 reports by default", func() {
+			c.ReportJoinedErrors(true)
+			c.SendError(joined)
+			So(len(server.posts), ShouldEqual, 5)
+		})
+
+		Convey("flattens nested joins", func() {
+			c.ReportJoinedErrors(true)
+			c.SendError(errors.Join(leaves[0], errors.Join(leaves[1], leaves[2])))
+			So(len(server.posts), ShouldEqual, 3)
+		})
+
+		Convey("truncates the omitted messages", func() {
+			c.ReportJoinedErrors(true).MaxJoinedErrorReports(1)
+			c.SendError(errors.Join(leaves[0], errors.New(strings.Repeat("a", 150))))
+			post, _ := server.next()
+			So(customData(post)["omittedJoinedErrorMessages"], ShouldResemble, []interface{}{strings.Repeat("a", 100) + "…"})
+		})
+
+		Convey("uses the stack of joined go-errors errors", func() {
+			c.ReportJoinedErrors(true)
+			goerror := goerrors.New("Test go-errors")
+
+			c.SendError(errors.Join(goerror, leaves[1]))
+			post, _ := server.next()
+			So(post.Details.Error.StackTrace[0].LineNumber, ShouldEqual, goerror.StackFrames()[0].LineNumber)
+		})
+
+		Convey("keeps the client's tags and the given options", func() {
+			c.Tags([]string{"api"}).ReportJoinedErrors(true)
+			c.SendError(errors.Join(leaves[0], leaves[1]), WithOccurrenceWindow(c.clock(), c.clock(), 2))
+			post, _ := server.next()
+			So(post.Details.Tags[0], ShouldEqual, "api")
+			So(customData(post), ShouldContainKey, OccurrenceWindowKey)
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("sends errors joining a single error as usual", func() {
+			c.ReportJoinedErrors(true)
+			c.SendError(errors.Join(leaves[0], nil))
+			post, _ := server.next()
+			So(correlationTag(post), ShouldBeEmpty)
+		})
+	})
+}
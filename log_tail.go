@@ -0,0 +1,143 @@
+package raygun4go
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// logTailKey is the custom data key the recent log lines are stored under.
+const logTailKey = "logTail"
+
+// maxLogTailBytes is the maximum total size of the log lines attached to a
+// report. The oldest lines are left out beyond it.
+const maxLogTailBytes = 8 << 10
+
+// maxLogLineBytes is the maximum size of a log line. Longer lines are split.
+const maxLogLineBytes = 1 << 10
+
+// LogTailWriter returns a writer keeping the last n lines written to it, to
+// be added to the output of a logger, e.g. with io.MultiWriter. The lines are
+// prefixed with the time they were written and attached to the following
+// reports in the custom data as "logTail", with the redaction patterns
+// applied and up to 8 KiB in total. The writer is safe for concurrent use and
+// shared with all clones; calling LogTailWriter again returns the same writer
+// keeping n lines from then on.
+func (c *Client) LogTailWriter(n int) io.Writer {
+	if c == nil {
+		return io.Discard
+	}
+	if c.logTail == nil {
+		c.logTail = newLogTail(n, c.clock)
+	} else {
+		c.logTail.setMax(n)
+	}
+	return c.logTail
+}
+
+// logTail is a ring of the most recent log lines.
+type logTail struct {
+	mu      sync.Mutex
+	clock   func() time.Time
+	lines   []string // the ring of lines, oldest first starting at next once full
+	next    int      // the index the next line is stored at
+	full    bool     // if true, all entries of lines are in use
+	partial []byte   // the start of a line not terminated yet
+}
+
+// newLogTail returns an empty logTail keeping up to max lines.
+func newLogTail(max int, clock func() time.Time) *logTail {
+	if max < 1 {
+		max = 1
+	}
+	return &logTail{clock: clock, lines: make([]string, max)}
+}
+
+// Write records the complete lines of p. The rest is kept until the line is
+// terminated.
+func (t *logTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			break
+		}
+		t.record(t.partial[:i])
+		t.partial = t.partial[i+1:]
+	}
+	for len(t.partial) > maxLogLineBytes {
+		t.record(t.partial[:maxLogLineBytes])
+		t.partial = t.partial[maxLogLineBytes:]
+	}
+	if len(t.partial) == 0 {
+		t.partial = nil
+	}
+	return len(p), nil
+}
+
+// record adds the given line to the ring. The caller must hold mu.
+func (t *logTail) record(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	t.lines[t.next] = t.clock().UTC().Format("2006-01-02T15:04:05.000Z") + " " + string(line)
+	t.next++
+	if t.next == len(t.lines) {
+		t.next = 0
+		t.full = true
+	}
+}
+
+// snapshot returns the recorded lines, oldest first.
+func (t *logTail) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]string(nil), t.lines[:t.next]...)
+	}
+	return append(append([]string(nil), t.lines[t.next:]...), t.lines[:t.next]...)
+}
+
+// setMax changes the maximum number of lines kept, keeping the most recent
+// ones.
+func (t *logTail) setMax(max int) {
+	if max < 1 {
+		max = 1
+	}
+	lines := t.snapshot()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	t.lines = make([]string, max)
+	copy(t.lines, lines)
+	t.next = len(lines) % max
+	t.full = len(lines) == max
+}
+
+// logTailCustomData returns the recent log lines to attach to a report,
+// redacted and capped at maxLogTailBytes, or nil if there are none.
+func (c *Client) logTailCustomData() []string {
+	if c.logTail == nil {
+		return nil
+	}
+	lines := c.logTail.snapshot()
+	size := 0
+	start := len(lines)
+	for start > 0 {
+		line := c.redaction.redactValue(lines[start-1])
+		if size+len(line) > maxLogTailBytes {
+			break
+		}
+		size += len(line)
+		start--
+		lines[start] = line
+	}
+	if start == len(lines) {
+		return nil
+	}
+	return lines[start:]
+}
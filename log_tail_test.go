@@ -0,0 +1,96 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLogTailWriter(t *testing.T) {
+	Convey("LogTailWriter", t, func() {
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+		logTail := func(c *Client) []string {
+			post := c.createPost(errors.New("Test LogTailWriter"), StackTrace{})
+			lines, _ := post.Details.UserCustomData.(map[string]interface{})[logTailKey].([]string)
+			return lines
+		}
+
+		Convey("attaches nothing by default", func() {
+			So(logTail(c), ShouldBeNil)
+		})
+
+		Convey("keeps the last lines in order", func() {
+			w := c.LogTailWriter(10)
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 250; i++ {
+						fmt.Fprintf(w, "worker %d line %d\n", g, i)
+					}
+				}(g)
+			}
+			wg.Wait()
+			fmt.Fprintln(w, "last")
+
+			lines := logTail(c)
+			So(lines, ShouldHaveLength, 10)
+			So(lines[9], ShouldEqual, "2024-01-02T03:04:05.000Z last")
+			seen := map[string]int{}
+			for _, line := range lines[:9] {
+				var g, i int
+				fmt.Sscanf(strings.TrimPrefix(line, "2024-01-02T03:04:05.000Z "), "worker %d line %d", &g, &i)
+				So(i, ShouldBeGreaterThan, seen[fmt.Sprint(g)]-1)
+				So(i, ShouldBeGreaterThanOrEqualTo, 240)
+				seen[fmt.Sprint(g)] = i
+			}
+		})
+
+		Convey("joins lines written in parts", func() {
+			w := c.LogTailWriter(5)
+			io.WriteString(w, "first ")
+			io.WriteString(w, "line\nsecond line\r\nthird")
+			So(logTail(c), ShouldResemble, []string{
+				"2024-01-02T03:04:05.000Z first line",
+				"2024-01-02T03:04:05.000Z second line",
+			})
+		})
+
+		Convey("applies the redaction patterns", func() {
+			w := c.LogTailWriter(5)
+			c.RedactPatterns(`sk_live_\w+`)
+			fmt.Fprintln(w, "using key sk_live_abc")
+			So(logTail(c), ShouldResemble, []string{"2024-01-02T03:04:05.000Z using key [REDACTED]"})
+		})
+
+		Convey("caps the total size", func() {
+			w := c.LogTailWriter(100)
+			for i := 0; i < 100; i++ {
+				fmt.Fprintln(w, strings.Repeat("x", 200))
+			}
+			lines := logTail(c)
+			So(len(lines), ShouldBeLessThan, 100)
+			So(len(strings.Join(lines, "")), ShouldBeLessThanOrEqualTo, maxLogTailBytes)
+		})
+
+		Convey("is shared with clones and keeps lines when resized", func() {
+			w := c.LogTailWriter(5)
+			for i := 0; i < 5; i++ {
+				fmt.Fprintf(w, "line %d\n", i)
+			}
+			So(c.LogTailWriter(2), ShouldEqual, w)
+			So(logTail(c.Clone()), ShouldResemble, []string{
+				"2024-01-02T03:04:05.000Z line 3",
+				"2024-01-02T03:04:05.000Z line 4",
+			})
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package raygun4go
+
+import "log"
+
+// Logger receives the diagnostic messages of a client, see Client.Logger.
+// Errorf is called for problems, like failed submissions and recovered
+// panics, Debugf for everything else, like successful submissions. It must be
+// safe for concurrent use.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// stdLogger is a Logger writing to a *log.Logger.
+type stdLogger struct {
+	l *log.Logger
+}
+
+// StdLogger returns a Logger writing all messages to the given *log.Logger,
+// or to the standard logger of the log package if l is nil.
+//
+//	raygun.Logger(raygun4go.StdLogger(log.New(os.Stderr, "raygun: ", log.LstdFlags)))
+func StdLogger(l *log.Logger) Logger {
+	if l == nil {
+		l = log.Default()
+	}
+	return stdLogger{l: l}
+}
+
+// Debugf implements Logger.
+func (s stdLogger) Debugf(format string, args ...interface{}) {
+	s.l.Printf(format, args...)
+}
+
+// Errorf implements Logger.
+func (s stdLogger) Errorf(format string, args ...interface{}) {
+	s.l.Printf(format, args...)
+}
+
+// Logger is a chainable option-setting method to pass the diagnostic messages
+// of the client to the given logger, e.g. one writing structured logs.
+// Setting a logger enables the messages like LogToStdOut(true), which writes
+// them to the standard logger of the log package. Passing nil restores that
+// default.
+func (c *Client) Logger(l Logger) *Client {
+	if c == nil {
+		return nil
+	}
+	c.logger = l
+	return c
+}
+
+// logging reports whether the diagnostic messages of the client are logged.
+func (c *Client) logging() bool {
+	return c.logger != nil || c.logToStdOut
+}
+
+// output returns the logger of the client, the standard logger if none is
+// set.
+func (c *Client) output() Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return StdLogger(nil)
+}
+
+// debugf logs an informational message if logging is enabled.
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.logging() {
+		c.output().Debugf(format, args...)
+	}
+}
+
+// errorf logs a problem if logging is enabled.
+func (c *Client) errorf(format string, args ...interface{}) {
+	if c.logging() {
+		c.output().Errorf(format, args...)
+	}
+}
@@ -0,0 +1,105 @@
+package raygun4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordingLogger records the messages logged to it.
+type recordingLogger struct {
+	mu     sync.Mutex
+	debug  []string
+	errors []string
+}
+
+// Debugf records a debug message.
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debug = append(l.debug, fmt.Sprintf(format, args...))
+}
+
+// Errorf records an error message.
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+// logged returns the recorded messages.
+func (l *recordingLogger) logged() (debug, errors string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.debug, "\n"), strings.Join(l.errors, "\n")
+}
+
+func TestLogger(t *testing.T) {
+	Convey("Logger", t, func() {
+		server := rayguntest.New()
+		logger := &recordingLogger{}
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Logger(logger)
+		Reset(func() {
+			c.Close()
+			server.Close()
+		})
+
+		Convey("receives successful submissions as debug messages", func() {
+			So(c.SendError(errors.New("foo")), ShouldBeNil)
+			debug, errs := logger.logged()
+			So(debug, ShouldContainSubstring, "Successfully sent message to Raygun")
+			So(errs, ShouldEqual, "")
+		})
+
+		Convey("receives recovered panics as errors", func() {
+			func() {
+				defer c.HandleError()
+				panic("boom")
+			}()
+			_, errs := logger.logged()
+			So(errs, ShouldContainSubstring, "Recovering from: boom")
+		})
+
+		Convey("receives failed submissions as errors", func() {
+			server.Fallback(rayguntest.Status(500))
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("foo")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			debug, errs := logger.logged()
+			So(errs, ShouldContainSubstring, "Unable to send")
+			So(errs, ShouldContainSubstring, "foo")
+			So(debug, ShouldNotContainSubstring, "Successfully sent")
+		})
+
+		Convey("is copied by clones", func() {
+			So(c.Clone().SendError(errors.New("foo")), ShouldBeNil)
+			debug, _ := logger.logged()
+			So(debug, ShouldContainSubstring, "Successfully sent message to Raygun")
+		})
+
+		Convey("falls back to the standard logger if nil", func() {
+			c.Logger(nil)
+			So(c.logging(), ShouldBeFalse)
+			So(c.output(), ShouldResemble, StdLogger(log.Default()))
+			c.LogToStdOut(true)
+			So(c.logging(), ShouldBeTrue)
+		})
+	})
+
+	Convey("StdLogger", t, func() {
+		var buf bytes.Buffer
+		l := StdLogger(log.New(&buf, "raygun: ", 0))
+		l.Debugf("debug %d", 1)
+		l.Errorf("error %d", 2)
+		So(buf.String(), ShouldEqual, "raygun: debug 1\nraygun: error 2\n")
+		So(StdLogger(nil), ShouldResemble, StdLogger(log.Default()))
+	})
+}
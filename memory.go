@@ -0,0 +1,115 @@
+package raygun4go
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// memoryCustomDataKey is the custom data key the memory snapshot is
+	// stored under.
+	memoryCustomDataKey = "memory"
+
+	// oomSuspectTag is the tag added to panic reports when memory usage is
+	// close to the limit.
+	oomSuspectTag = "oom-suspect"
+
+	// oomSuspectPercent is the percentage of the memory limit above which a
+	// panic is tagged as a suspected out of memory condition.
+	oomSuspectPercent = 90
+)
+
+// The cgroup files the memory usage and limit are read from, for cgroup v2
+// and v1 respectively.
+var cgroupMemoryFiles = []struct{ usage, limit string }{
+	{"/sys/fs/cgroup/memory.current", "/sys/fs/cgroup/memory.max"},
+	{"/sys/fs/cgroup/memory/memory.usage_in_bytes", "/sys/fs/cgroup/memory/memory.limit_in_bytes"},
+}
+
+// readCgroupFile reads a cgroup file, it is replaced in tests.
+var readCgroupFile = os.ReadFile
+
+// memorySnapshot is the memory information added to panic reports.
+type memorySnapshot struct {
+	HeapInUse    uint64  `json:"heapInUse"`              // bytes in in-use heap spans
+	Sys          uint64  `json:"sys"`                    // bytes obtained from the OS
+	NumGC        uint32  `json:"numGC"`                  // completed GC cycles
+	Usage        uint64  `json:"usage,omitempty"`        // bytes used by the cgroup, if readable
+	Limit        uint64  `json:"limit,omitempty"`        // the cgroup memory limit, if any
+	LimitPercent float64 `json:"limitPercent,omitempty"` // the usage in percent of the limit
+}
+
+// IncludeMemoryOnPanic is a chainable option-setting method to add a snapshot
+// of the memory statistics to the custom data of panic reports, under
+// "memory". If the process runs in a cgroup with a memory limit, the snapshot
+// includes how close the usage is to the limit, and reports using more than
+// 90% of it are tagged "oom-suspect".
+func (c *Client) IncludeMemoryOnPanic(include bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.memoryOnPanic = include
+	return c
+}
+
+// newMemorySnapshot reads the memory statistics of the runtime and the
+// cgroup limit, if any.
+func newMemorySnapshot() memorySnapshot {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	s := memorySnapshot{
+		HeapInUse: stats.HeapInuse,
+		Sys:       stats.Sys,
+		NumGC:     stats.NumGC,
+	}
+
+	usage, limit, ok := cgroupMemory()
+	if !ok {
+		return s
+	}
+	s.Usage, s.Limit = usage, limit
+	if usage == 0 {
+		usage = stats.Sys
+	}
+	s.LimitPercent = math.Round(float64(usage)/float64(limit)*1000) / 10
+	return s
+}
+
+// oomSuspect reports whether the memory usage is close to the limit.
+func (s memorySnapshot) oomSuspect() bool {
+	return s.Limit > 0 && s.LimitPercent >= oomSuspectPercent
+}
+
+// cgroupMemory returns the memory usage and limit of the cgroup, trying the
+// cgroup v2 layout first. It returns false if no limit is set or the files
+// cannot be read, e.g. outside of Linux. The usage is 0 if only the limit is
+// readable.
+func cgroupMemory() (usage, limit uint64, ok bool) {
+	for _, files := range cgroupMemoryFiles {
+		limit, ok = readCgroupValue(files.limit)
+		if !ok {
+			continue
+		}
+		usage, _ = readCgroupValue(files.usage)
+		return usage, limit, true
+	}
+	return 0, 0, false
+}
+
+// readCgroupValue reads a number of bytes from the given cgroup file. It
+// returns false if the file cannot be read or the value is unlimited, which
+// cgroup v2 writes as "max" and v1 as a number close to the largest int64.
+func readCgroupValue(path string) (uint64, bool) {
+	b, err := readCgroupFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || v == 0 || v >= 1<<62 {
+		return 0, false
+	}
+	return v, true
+}
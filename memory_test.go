@@ -0,0 +1,101 @@
+package raygun4go
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIncludeMemoryOnPanic(t *testing.T) {
+	Convey("#IncludeMemoryOnPanic", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		files := map[string]string{}
+		readCgroupFile = func(path string) ([]byte, error) {
+			content, ok := files[path]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(content), nil
+		}
+		Reset(func() { readCgroupFile = os.ReadFile })
+
+		c, _ := New("app", "key")
+		c.IncludeMemoryOnPanic(true)
+		report := func(c *Client) PostData {
+			c.reportPanic("Test IncludeMemoryOnPanic", StackTrace{})
+			post, _ := server.next()
+			return post
+		}
+		memory := func(post PostData) map[string]interface{} {
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			memory, _ := data["memory"].(map[string]interface{})
+			return memory
+		}
+
+		Convey("adds the runtime statistics", func() {
+			m := memory(report(c))
+			So(m["heapInUse"], ShouldBeGreaterThan, 0)
+			So(m["sys"], ShouldBeGreaterThan, 0)
+			So(m, ShouldContainKey, "numGC")
+			So(m, ShouldNotContainKey, "limit")
+		})
+
+		Convey("adds nothing unless enabled", func() {
+			c.IncludeMemoryOnPanic(false)
+			So(withoutReportID(report(c)), ShouldBeNil)
+		})
+
+		Convey("with a cgroup v2 limit", func() {
+			files["/sys/fs/cgroup/memory.max"] = "1000\n"
+
+			Convey("adds the proximity to the limit", func() {
+				files["/sys/fs/cgroup/memory.current"] = "500\n"
+				post := report(c)
+				So(memory(post)["usage"], ShouldEqual, 500)
+				So(memory(post)["limit"], ShouldEqual, 1000)
+				So(memory(post)["limitPercent"], ShouldEqual, 50)
+				So(post.Details.Tags, ShouldNotContain, "oom-suspect")
+			})
+
+			Convey("tags reports close to the limit", func() {
+				files["/sys/fs/cgroup/memory.current"] = "950\n"
+				post := report(c)
+				So(memory(post)["limitPercent"], ShouldEqual, 95)
+				So(post.Details.Tags, ShouldContain, "oom-suspect")
+			})
+		})
+
+		Convey("with a cgroup v1 limit", func() {
+			files["/sys/fs/cgroup/memory/memory.limit_in_bytes"] = "2000\n"
+			files["/sys/fs/cgroup/memory/memory.usage_in_bytes"] = "1900\n"
+			post := report(c)
+			So(memory(post)["limitPercent"], ShouldEqual, 95)
+			So(post.Details.Tags, ShouldContain, "oom-suspect")
+		})
+
+		Convey("without a limit", func() {
+			files["/sys/fs/cgroup/memory.max"] = "max\n"
+			files["/sys/fs/cgroup/memory.current"] = "950\n"
+			files["/sys/fs/cgroup/memory/memory.limit_in_bytes"] = "9223372036854771712\n"
+			post := report(c)
+			So(memory(post), ShouldNotContainKey, "limit")
+			So(post.Details.Tags, ShouldNotContain, "oom-suspect")
+		})
+	})
+}
+
+func TestMemorySnapshot(t *testing.T) {
+	Convey("memorySnapshot", t, func() {
+		Convey("#oomSuspect is false without a limit", func() {
+			So(memorySnapshot{LimitPercent: 95}.oomSuspect(), ShouldBeFalse)
+		})
+
+		Convey("#oomSuspect uses the threshold", func() {
+			So(memorySnapshot{Limit: 100, LimitPercent: 89.9}.oomSuspect(), ShouldBeFalse)
+			So(memorySnapshot{Limit: 100, LimitPercent: 90}.oomSuspect(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// MetricsSink receives the delivery metrics of a client, e.g. to export them
+// to Prometheus. Its methods are called from the goroutines delivering
+// reports, so they must be safe for concurrent use and must not block.
+type MetricsSink interface {
+	// IncSent counts a report Raygun accepted.
+	IncSent()
+
+	// IncFailed counts a report whose delivery failed, after retries. The
+	// reason is one of "rate_limited", "invalid_api_key", "rejected",
+	// "server_error", "timeout", "canceled", "network", "circuit_open",
+	// "offline", "disabled" and "other".
+	IncFailed(reason string)
+
+	// IncDropped counts a report discarded without a delivery attempt, see
+	// OnDrop.
+	IncDropped()
+
+	// ObserveLatency records the duration of a delivery attempt, including
+	// its retries.
+	ObserveLatency(d time.Duration)
+}
+
+// noMetrics is the MetricsSink discarding all metrics. It is the default.
+type noMetrics struct{}
+
+func (noMetrics) IncSent()                     {}
+func (noMetrics) IncFailed(string)             {}
+func (noMetrics) IncDropped()                  {}
+func (noMetrics) ObserveLatency(time.Duration) {}
+
+// Metrics is a chainable option-setting method to set the sink receiving the
+// delivery metrics of the client and the clones made afterwards. Passing nil
+// discards them, which is the default. Stats returns the same counts.
+func (c *Client) Metrics(sink MetricsSink) *Client {
+	if c == nil {
+		return nil
+	}
+	if sink == nil {
+		sink = noMetrics{}
+	}
+	c.metrics = sink
+	return c
+}
+
+// submitTimed submits the given post like submitCoreWithContext and records
+// the duration of the attempt.
+func (c *Client) submitTimed(ctx context.Context, post PostData) error {
+	start := time.Now()
+	err := c.submitCoreWithContext(ctx, post)
+	c.metrics.ObserveLatency(time.Since(start))
+	return err
+}
+
+// failureReason returns the reason passed to MetricsSink.IncFailed for a
+// delivery failing with err.
+func failureReason(err error) string {
+	var submitErr *SubmitError
+	switch {
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrRateLimitExceeded):
+		return "rate_limited"
+	case errors.Is(err, ErrInvalidAPIKey):
+		return "invalid_api_key"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrOffline):
+		return "offline"
+	case errors.Is(err, ErrClientDisabled):
+		return "disabled"
+	case errors.As(err, &submitErr):
+		switch {
+		case submitErr.StatusCode >= http.StatusInternalServerError:
+			return "server_error"
+		case submitErr.StatusCode != 0:
+			return "rejected"
+		case submitErr.Timeout || errors.Is(err, context.DeadlineExceeded):
+			return "timeout"
+		case submitErr.Canceled:
+			return "canceled"
+		}
+		return "network"
+	}
+	return "other"
+}
@@ -0,0 +1,126 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordingSink is a MetricsSink recording all calls.
+type recordingSink struct {
+	mu        sync.Mutex
+	sent      int
+	failed    []string
+	dropped   int
+	latencies []time.Duration
+}
+
+func (s *recordingSink) IncSent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent++
+}
+
+func (s *recordingSink) IncFailed(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, reason)
+}
+
+func (s *recordingSink) IncDropped() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped++
+}
+
+func (s *recordingSink) ObserveLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies = append(s.latencies, d)
+}
+
+func TestMetrics(t *testing.T) {
+	Convey("#Metrics", t, func() {
+		server := rayguntest.New()
+		sink := &recordingSink{}
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Metrics(sink)
+		Reset(func() {
+			c.Close()
+			server.Close()
+		})
+		send := func() error {
+			return c.SendError(errors.New("Test Metrics"))
+		}
+
+		Convey("counts sent reports and their latency", func() {
+			server.Script(rayguntest.Slow(10*time.Millisecond, rayguntest.Accepted))
+			So(send(), ShouldBeNil)
+			So(sink.sent, ShouldEqual, 1)
+			So(sink.failed, ShouldBeEmpty)
+			So(sink.latencies, ShouldHaveLength, 1)
+			So(sink.latencies[0], ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+		})
+
+		Convey("counts failed reports with their reason", func() {
+			server.Script(rayguntest.BadRequest("invalid"), rayguntest.Status(http.StatusBadGateway))
+			So(send(), ShouldNotBeNil)
+			So(send(), ShouldNotBeNil)
+			So(sink.sent, ShouldEqual, 0)
+			So(sink.failed, ShouldResemble, []string{"rejected", "server_error"})
+			So(sink.latencies, ShouldHaveLength, 2)
+		})
+
+		Convey("counts reports dropped by the queue", func() {
+			transport := gatedTransport{NewRecordingTransport(), make(chan struct{}, 10), make(chan struct{})}
+			c.Transport(transport).Asynchronous(true).QueueOverflow(DropNewest, 1)
+			So(send(), ShouldBeNil)
+			<-transport.started
+			So(send(), ShouldBeNil)
+			So(send(), ShouldEqual, ErrQueueFull)
+			close(transport.release)
+			So(c.Flush(context.Background()), ShouldBeNil)
+
+			sink.mu.Lock()
+			defer sink.mu.Unlock()
+			So(sink.dropped, ShouldEqual, 1)
+			So(sink.sent, ShouldEqual, 2)
+		})
+
+		Convey("discards the metrics without a sink", func() {
+			c.Metrics(nil)
+			So(send(), ShouldBeNil)
+			So(sink.sent, ShouldEqual, 0)
+		})
+
+		Convey("is kept by clones", func() {
+			So(c.Clone().metrics, ShouldEqual, sink)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.Metrics(sink), ShouldBeNil)
+		})
+	})
+
+	Convey("failureReason", t, func() {
+		So(failureReason(&RateLimitError{RetryAfter: time.Second}), ShouldEqual, "rate_limited")
+		So(failureReason(ErrRateLimitExceeded), ShouldEqual, "rate_limited")
+		So(failureReason(&SubmitError{StatusCode: http.StatusForbidden, Err: ErrInvalidAPIKey}), ShouldEqual, "invalid_api_key")
+		So(failureReason(&SubmitError{StatusCode: http.StatusBadRequest}), ShouldEqual, "rejected")
+		So(failureReason(&SubmitError{StatusCode: http.StatusServiceUnavailable}), ShouldEqual, "server_error")
+		So(failureReason(&SubmitError{Err: context.DeadlineExceeded, Timeout: true}), ShouldEqual, "timeout")
+		So(failureReason(&SubmitError{Err: context.Canceled, Canceled: true}), ShouldEqual, "canceled")
+		So(failureReason(&SubmitError{Err: errors.New("connection refused")}), ShouldEqual, "network")
+		So(failureReason(ErrCircuitOpen), ShouldEqual, "circuit_open")
+		So(failureReason(ErrOffline), ShouldEqual, "offline")
+		So(failureReason(ErrClientDisabled), ShouldEqual, "disabled")
+		So(failureReason(errors.New("Test Metrics")), ShouldEqual, "other")
+	})
+}
@@ -0,0 +1,227 @@
+package raygun4go
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// responseBodyKey is the custom data key a captured response body snippet is
+// stored under.
+const responseBodyKey = "response.body"
+
+// contextKey is the type of the keys this package stores in contexts.
+type contextKey int
+
+// clientContextKey is the key of the scoped client stored in a context.
+const clientContextKey contextKey = 0
+
+// NewContext returns a copy of ctx carrying the given client. Use FromContext
+// to retrieve it.
+func NewContext(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey, c)
+}
+
+// FromContext returns the client stored in ctx by NewContext or the
+// middleware. It returns nil if there is none, which is safe to use.
+func FromContext(ctx context.Context) *Client {
+	c, _ := ctx.Value(clientContextKey).(*Client)
+	return c
+}
+
+// middlewareConfig holds the settings applied by MiddlewareOptions.
+type middlewareConfig struct {
+	captureMaxBytes int   // the maximum number of response body bytes captured, 0 disables capturing
+	captureStatuses []int // the statuses whose bodies are captured, all 5xx if empty
+	handlerFunc     bool  // if true, the name of the handler function is added to reports
+	tagHandlerFunc  bool  // if true, reports are tagged with the name of the handler function
+}
+
+// MiddlewareOption configures the handler returned by Client.Middleware.
+type MiddlewareOption func(*middlewareConfig)
+
+// CaptureResponseBody makes the middleware capture up to maxBytes of response
+// bodies sent with one of the given statuses, or any 5xx status if none are
+// given. Reports of the request's scoped client made after the body was
+// written carry the captured snippet in their custom data under
+// "response.body". Bodies of other responses are never buffered.
+func CaptureResponseBody(maxBytes int, statuses ...int) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.captureMaxBytes = maxBytes
+		cfg.captureStatuses = statuses
+	}
+}
+
+// Middleware wraps the given handler, reporting all panics together with the
+// request and answering them with a 500 if no response was written yet.
+//
+// Each request is handled with its own clone of the client, which has the
+// request attached and can be retrieved by the handler with FromContext to
+// report further errors or add context information.
+func (c *Client) Middleware(next http.Handler, opts ...MiddlewareOption) http.Handler {
+	if c == nil {
+		return next
+	}
+
+	var cfg middlewareConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scoped := c.Clone()
+		rw := &responseWriter{ResponseWriter: w, config: cfg}
+		if cfg.captureMaxBytes > 0 {
+			scoped.response = rw
+		}
+		r = r.WithContext(NewContext(r.Context(), scoped))
+		scoped.Request(r)
+		scoped.defaultListenAddr(r)
+		if cfg.handlerFunc {
+			scoped.includeHandlerFunc(next, r, cfg.tagHandlerFunc)
+		}
+
+		defer func() {
+			e := recover()
+			if e == nil {
+				return
+			}
+			if e == http.ErrAbortHandler {
+				panic(e)
+			}
+			scoped.reportPanic(e, currentStack())
+			if !rw.wroteHeader() {
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter records the status of a response and captures its body if
+// the middleware is configured to.
+type responseWriter struct {
+	http.ResponseWriter
+	config middlewareConfig
+
+	mu      sync.Mutex
+	status  int
+	capture []byte
+}
+
+// WriteHeader records the status and passes it on.
+func (w *responseWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	if w.status == 0 {
+		w.status = status
+	}
+	w.mu.Unlock()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write captures the body if configured and passes it on.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.capturing() {
+		remaining := w.config.captureMaxBytes - len(w.capture)
+		if remaining > len(b) {
+			remaining = len(b)
+		}
+		w.capture = append(w.capture, b[:remaining]...)
+	}
+	w.mu.Unlock()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes flushes on if the wrapped ResponseWriter supports them.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes hijacking on, e.g. for websocket upgrades. It returns
+// http.ErrNotSupported if the wrapped ResponseWriter does not support it.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.mu.Lock()
+		if w.status == 0 {
+			w.status = http.StatusSwitchingProtocols
+		}
+		w.mu.Unlock()
+	}
+	return conn, rw, err
+}
+
+// Push passes HTTP/2 server pushes on. It returns http.ErrNotSupported if the
+// wrapped ResponseWriter does not support them.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// ReadFrom passes the body on to the ReadFrom of the wrapped ResponseWriter,
+// e.g. to use sendfile, unless the body is captured.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.mu.Lock()
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	capturing := w.capturing()
+	w.mu.Unlock()
+
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok && !capturing {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{w}, r)
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// capturing reports whether the body of the response is captured. It must be
+// called with w.mu held.
+func (w *responseWriter) capturing() bool {
+	if w.config.captureMaxBytes <= len(w.capture) {
+		return false
+	}
+	if len(w.config.captureStatuses) == 0 {
+		return w.status >= 500 && w.status < 600
+	}
+	for _, s := range w.config.captureStatuses {
+		if s == w.status {
+			return true
+		}
+	}
+	return false
+}
+
+// wroteHeader reports whether a status was sent already.
+func (w *responseWriter) wroteHeader() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status != 0
+}
+
+// capturedBody returns the captured body snippet.
+func (w *responseWriter) capturedBody() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.capture)
+}
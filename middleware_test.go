@@ -0,0 +1,170 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMiddleware(t *testing.T) {
+	Convey("Middleware", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		serve := func(handler http.HandlerFunc, opts ...MiddlewareOption) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://www.example.com/path?foo=bar", nil)
+			c.Middleware(handler, opts...).ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("reports panics with the request", func() {
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				panic("Test Middleware")
+			})
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+			post, ok := server.next()
+			So(ok, ShouldBeTrue)
+			So(post.Details.Error.Message, ShouldEqual, "Test Middleware")
+			So(post.Details.Request.URL, ShouldEqual, "http://www.example.com/path?foo=bar")
+		})
+
+		Convey("reports panics without the request if request data is disabled", func() {
+			c.DisableRequestData(true)
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				panic("Test Middleware")
+			})
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+			post, _ := server.next()
+			So(post.Details.Error.Message, ShouldEqual, "Test Middleware")
+			So(post.Details.Request, ShouldResemble, RequestData{})
+		})
+
+		Convey("re-panics with http.ErrAbortHandler", func() {
+			So(func() {
+				serve(func(w http.ResponseWriter, r *http.Request) {
+					panic(http.ErrAbortHandler)
+				})
+			}, ShouldPanicWith, http.ErrAbortHandler)
+			So(len(server.posts), ShouldEqual, 0)
+		})
+
+		Convey("passes a scoped client in the request context", func() {
+			serve(func(w http.ResponseWriter, r *http.Request) {
+				scoped := FromContext(r.Context())
+				So(scoped, ShouldNotEqual, c)
+				So(scoped.context.Request, ShouldEqual, r)
+			})
+			So(FromContext(context.Background()), ShouldBeNil)
+		})
+
+		Convey("passes hijacking on", func() {
+			upgrades := httptest.NewServer(c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, buf, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				defer conn.Close()
+				buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\nhello")
+				buf.Flush()
+				panic("Test Middleware hijacked")
+			})))
+			defer upgrades.Close()
+
+			conn, err := net.Dial("tcp", upgrades.Listener.Addr().String())
+			So(err, ShouldBeNil)
+			defer conn.Close()
+			io.WriteString(conn, "GET / HTTP/1.1\r\nHost: example.com\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+			answer, _ := io.ReadAll(conn)
+			So(string(answer), ShouldStartWith, "HTTP/1.1 101 Switching Protocols")
+			So(string(answer), ShouldEndWith, "hello")
+
+			post, _ := server.next()
+			So(post.Details.Error.Message, ShouldEqual, "Test Middleware hijacked")
+		})
+
+		Convey("reports hijacking as unsupported if the ResponseWriter is not a Hijacker", func() {
+			var err error
+			serve(func(w http.ResponseWriter, r *http.Request) {
+				_, _, err = w.(http.Hijacker).Hijack()
+			})
+			So(err, ShouldEqual, http.ErrNotSupported)
+		})
+
+		Convey("#CaptureResponseBody", func() {
+			report := func(status int, body string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(status)
+					w.Write([]byte(body))
+					FromContext(r.Context()).SendError(errors.New("Test CaptureResponseBody"))
+				}
+			}
+
+			Convey("attaches the body of 5xx responses", func() {
+				w := serve(report(http.StatusBadGateway, "upstream failed"), CaptureResponseBody(100))
+				So(w.Body.String(), ShouldEqual, "upstream failed")
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldResemble, map[string]interface{}{
+					"response.body": "upstream failed",
+				})
+			})
+
+			Convey("attaches bodies copied with io.Copy", func() {
+				serve(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+					io.Copy(w, strings.NewReader("copied"))
+					FromContext(r.Context()).SendError(errors.New("Test CaptureResponseBody"))
+				}, CaptureResponseBody(100))
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldResemble, map[string]interface{}{
+					"response.body": "copied",
+				})
+			})
+
+			Convey("truncates the body", func() {
+				serve(report(http.StatusInternalServerError, strings.Repeat("x", 20)), CaptureResponseBody(8))
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldResemble, map[string]interface{}{
+					"response.body": "xxxxxxxx",
+				})
+			})
+
+			Convey("ignores other statuses", func() {
+				serve(report(http.StatusOK, "fine"), CaptureResponseBody(100))
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldBeNil)
+			})
+
+			Convey("captures the given statuses only", func() {
+				serve(report(http.StatusNotFound, "missing"), CaptureResponseBody(100, http.StatusNotFound))
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldResemble, map[string]interface{}{
+					"response.body": "missing",
+				})
+			})
+
+			Convey("is disabled by default", func() {
+				serve(report(http.StatusInternalServerError, "failed"))
+
+				post, _ := server.next()
+				So(withoutReportID(post), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package raygun4go
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxInlineBytes is the maximum length of byte slices in custom data that are
+// rendered as a string. Longer ones are summarized by length and hash.
+const maxInlineBytes = 64
+
+// maxNormalizeDepth is the maximum nesting of maps and slices in custom data
+// normalizeCustomData descends into.
+const maxNormalizeDepth = 32
+
+// RawCustomDataValues is a chainable option-setting method to send
+// time.Duration, time.Time and []byte values in custom data as encoding/json
+// renders them, i.e. as nanoseconds, in RFC 3339 with nanoseconds and in
+// base64. By default, they are normalized to be readable in Raygun: durations
+// like "5s", times in RFC 3339, and byte slices of up to 64 bytes as their
+// text if printable UTF-8, as hex otherwise. Longer byte slices are summarized
+// by their length and SHA-256 hash. Values nested in maps with string keys
+// and in slices are normalized as well, but not struct fields.
+func (c *Client) RawCustomDataValues(raw bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.rawCustomDataValues = raw
+	return c
+}
+
+// normalizeCustomData returns the given custom data normalized as described
+// for RawCustomDataValues, unless disabled. Maps and slices are copied if any
+// of their values change.
+func (c *Client) normalizeCustomData(data interface{}) interface{} {
+	if c.rawCustomDataValues {
+		return data
+	}
+	normalized, _ := normalizeValue(data, 0)
+	return normalized
+}
+
+// normalizeValue returns the normalized form of the given value found at the
+// given depth and whether it differs from the value.
+func normalizeValue(v interface{}, depth int) (interface{}, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case time.Duration:
+		return v.String(), true
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true
+	case []byte:
+		return bytesString(v), true
+	}
+	if depth == maxNormalizeDepth {
+		return v, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		out := make(map[string]interface{}, rv.Len())
+		changed := false
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, ok := normalizeValue(iter.Value().Interface(), depth+1)
+			out[iter.Key().String()] = value
+			changed = changed || ok
+		}
+		if changed {
+			return out, true
+		}
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, rv.Len())
+		changed := false
+		for i := range out {
+			value, ok := normalizeValue(rv.Index(i).Interface(), depth+1)
+			out[i] = value
+			changed = changed || ok
+		}
+		if changed {
+			return out, true
+		}
+	}
+	return v, false
+}
+
+// bytesString renders the given bytes as text if they are short printable
+// UTF-8, as hex if they are short otherwise, and else as a summary of their
+// length and SHA-256 hash.
+func bytesString(b []byte) string {
+	if len(b) > maxInlineBytes {
+		sum := sha256.Sum256(b)
+		return fmt.Sprintf("[%d bytes, sha256:%s]", len(b), hex.EncodeToString(sum[:8]))
+	}
+	if isPrintable(b) {
+		return string(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// isPrintable reports whether the given bytes are valid UTF-8 consisting of
+// printable characters and whitespace only.
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
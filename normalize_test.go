@@ -0,0 +1,86 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalizeCustomData(t *testing.T) {
+	Convey("Custom data normalization", t, func() {
+		at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		long := []byte(strings.Repeat("x", maxInlineBytes+1))
+		c, _ := New("app", "key")
+		c.CustomData(map[string]interface{}{
+			"timeout": 5 * time.Second,
+			"at":      at,
+			"text":    []byte("hello"),
+			"binary":  []byte{0x00, 0xff},
+			"long":    long,
+			"raw":     json.RawMessage(`{"a":1}`),
+			"nested": map[string]interface{}{
+				"delays": []time.Duration{time.Millisecond, time.Minute},
+			},
+			"plain": "value",
+		})
+
+		// customData returns the custom data of a report after a JSON round
+		// trip, as Raygun receives it.
+		customData := func() map[string]interface{} {
+			post := c.createPost(errors.New("Test Normalize"), StackTrace{})
+			enc, _ := json.Marshal(post.Details.UserCustomData)
+			var data map[string]interface{}
+			json.Unmarshal(enc, &data)
+			return data
+		}
+
+		Convey("renders durations like 5s", func() {
+			So(customData()["timeout"], ShouldEqual, "5s")
+		})
+
+		Convey("renders times in RFC 3339", func() {
+			So(customData()["at"], ShouldEqual, "2024-01-02T03:04:05Z")
+		})
+
+		Convey("renders short byte slices as text or hex", func() {
+			data := customData()
+			So(data["text"], ShouldEqual, "hello")
+			So(data["binary"], ShouldEqual, "00ff")
+		})
+
+		Convey("summarizes long byte slices", func() {
+			So(customData()["long"], ShouldEqual, "[65 bytes, sha256:9537c5fdf120482f]")
+		})
+
+		Convey("normalizes nested values", func() {
+			So(customData()["nested"], ShouldResemble, map[string]interface{}{
+				"delays": []interface{}{"1ms", "1m0s"},
+			})
+		})
+
+		Convey("leaves other values alone", func() {
+			data := customData()
+			So(data["raw"], ShouldResemble, map[string]interface{}{"a": float64(1)})
+			So(data["plain"], ShouldEqual, "value")
+		})
+
+		Convey("applies to the flattened form", func() {
+			c.FlattenCustomDataDepth(1)
+			So(customData()["nested.delays.1"], ShouldEqual, "1m0s")
+		})
+
+		Convey("can be disabled", func() {
+			c.RawCustomDataValues(true)
+			data := customData()
+			So(data["timeout"], ShouldEqual, float64(5*time.Second))
+			So(data["text"], ShouldEqual, "aGVsbG8=")
+			So(data["nested"], ShouldResemble, map[string]interface{}{
+				"delays": []interface{}{float64(time.Millisecond), float64(time.Minute)},
+			})
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultOfflineRetryDelay is the delay before the first retry of the
+	// reports in the offline storage after a failed delivery.
+	defaultOfflineRetryDelay = 10 * time.Second
+
+	// maxOfflineRetryDelay is the longest delay between retries of the
+	// reports in the offline storage.
+	maxOfflineRetryDelay = 10 * time.Minute
+)
+
+// offlineStore keeps the reports that could not be delivered because of an
+// outage on disk and retries them from a background loop. It is shared by a
+// client and all clones made after OfflineStorage was set.
+type offlineStore struct {
+	client     *Client // the client delivering the stored reports
+	dir        string
+	maxReports int
+	retryDelay time.Duration // the delay before the first retry of the default backoff
+
+	mu  sync.Mutex // serializes writing and evicting reports
+	seq int        // the number of reports stored so far, used in file names
+
+	stateMu    sync.Mutex    // guards state and policy
+	state      offlineState  // the offline detection, see OfflineDetection
+	policy     drainPolicy   // the delivery of the stored reports, see DrainPolicy
+	reschedule chan struct{} // signals the loop to end its pause, e.g. after going offline
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wake    chan struct{} // signals the loop that a report was stored
+	stopped chan struct{} // closed once the loop has stopped
+}
+
+// OfflineStorage is a chainable option-setting method to keep at most
+// maxReports reports that could not be delivered in dir, and resend them from
+// the background once Raygun is reachable. Passing "" disables it.
+func (c *Client) OfflineStorage(dir string, maxReports int) *Client {
+	if c == nil {
+		return nil
+	}
+	if c.offline != nil {
+		c.offline.close()
+	}
+	c.offline = nil
+	if dir == "" {
+		return c
+	}
+	if maxReports <= 0 {
+		maxReports = defaultQueueSize
+	}
+	c.offline = newOfflineStore(c, dir, maxReports, defaultOfflineRetryDelay)
+	return c
+}
+
+// newOfflineStore returns a store of the reports of the given client in the
+// given directory and starts its loop.
+func newOfflineStore(c *Client, dir string, maxReports int, retryDelay time.Duration) *offlineStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &offlineStore{
+		client:     c,
+		dir:        dir,
+		maxReports: maxReports,
+		retryDelay: retryDelay,
+		ctx:        ctx,
+		cancel:     cancel,
+		wake:       make(chan struct{}, 1),
+		reschedule: make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+		state:      offlineState{threshold: c.offlineThreshold, probeInterval: c.offlineProbeInterval},
+		policy:     c.drain,
+	}
+	go s.run()
+	return s
+}
+
+// storable reports whether a submission failing with err may succeed once
+// the outage causing it is over, so the report is worth storing.
+func storable(err error) bool {
+	var submitErr *SubmitError
+	if errors.As(err, &submitErr) {
+		if submitErr.StatusCode != 0 {
+			return submitErr.StatusCode >= 500
+		}
+		return !submitErr.Canceled
+	}
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrOffline)
+}
+
+// spilled reports whether a report whose submission failed with err was
+// kept in the offline storage, so it must not be retried elsewhere.
+func (c *Client) spilled(err error) bool {
+	return c.offline != nil && storable(err)
+}
+
+// spill stores the given report if its submission failed with an error
+// worth retrying later.
+func (c *Client) spill(post PostData, err error) {
+	if !c.spilled(err) {
+		return
+	}
+	if err := c.offline.store([]queuedReport{{client: c, post: post, attempts: 1}}); err != nil {
+		c.errorf("Unable to store %s for later delivery (%s)", post.Summary(), err.Error())
+	}
+}
+
+// store writes the given reports to the directory, evicts the oldest reports
+// beyond the limit and wakes the loop.
+func (s *offlineStore) store(reports []queuedReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("Unable to create directory (%w)", err)
+	}
+	prefix := time.Now().UTC().Format("20060102T150405.000000000Z")
+	for _, r := range reports {
+		s.seq++
+		name := fmt.Sprintf("%s-%06d%s", prefix, s.seq%1000000, storedReportExtension)
+		if err := writeStoredReport(s.dir, name, r); err != nil {
+			return err
+		}
+	}
+	s.evict()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// evict removes the oldest reports beyond the limit.
+func (s *offlineStore) evict() {
+	files, err := storedReportFiles(s.dir)
+	if err != nil {
+		return
+	}
+	for len(files) > s.maxReports {
+		if err := os.Remove(files[0]); err != nil && !os.IsNotExist(err) {
+			s.client.errorf("%s", err.Error())
+		}
+		files = files[1:]
+	}
+}
+
+// run delivers the stored reports until the store is closed: right away,
+// then whenever a report was stored, in batches as set by DrainPolicy, and
+// with growing delays while deliveries fail, or every probe interval once
+// offline.
+func (s *offlineStore) run() {
+	defer close(s.stopped)
+
+	s.mu.Lock()
+	s.evict()
+	s.mu.Unlock()
+
+	failures := 0
+	for {
+		policy := s.drainPolicy()
+		delivered, more := s.deliver(policy.batchSize)
+		var delay time.Duration
+		switch {
+		case !delivered:
+			delay = policy.backoff(failures)
+			if interval := s.retryInterval(); interval > 0 {
+				delay = interval
+			}
+			failures++
+		case !more:
+			s.online()
+			failures = 0
+			select {
+			case <-s.wake:
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		default:
+			s.online()
+			failures = 0
+			delay = policy.interval
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.reschedule:
+			timer.Stop()
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// deliver submits up to batchSize stored reports oldest first, all of them
+// if batchSize is 0, and removes the ones that were accepted or rejected for
+// good. It stops at the first report that failed because of an outage and
+// reports whether the batch was delivered, and whether reports are left.
+func (s *offlineStore) deliver(batchSize int) (delivered, more bool) {
+	files, err := storedReportFiles(s.dir)
+	if err != nil {
+		s.client.errorf("%s", err.Error())
+		return false, true
+	}
+	if batchSize > 0 && len(files) > batchSize {
+		files, more = files[:batchSize], true
+	}
+
+	c := s.client
+	for _, file := range files {
+		if s.ctx.Err() != nil {
+			return false, true
+		}
+		stored, err := readStoredReport(file)
+		if errors.Is(err, errCorruptReport) {
+			if err := quarantineReport(file); err != nil {
+				c.errorf("%s", err.Error())
+			}
+			continue
+		}
+		if err != nil {
+			continue // evicted meanwhile
+		}
+
+		if !c.limiter.take(c.clock()) {
+			return false, true
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, c.submitTimeout)
+		err = c.postReport(ctx, stored.Post)
+		cancel()
+		if storable(err) || err == ErrClientDisabled || s.ctx.Err() != nil {
+			return false, true
+		}
+		if err != nil {
+			c.errorf("Dropping stored %s (%s)", stored.Post.Summary(), err.Error())
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			c.errorf("%s", err.Error())
+		}
+	}
+	return true, more
+}
+
+// close stops the loop and waits for it.
+func (s *offlineStore) close() {
+	s.cancel()
+	<-s.stopped
+}
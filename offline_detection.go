@@ -0,0 +1,126 @@
+package raygun4go
+
+import (
+	"errors"
+	"time"
+)
+
+// defaultOfflineProbeInterval is the interval the offline storage probes
+// Raygun at while the client is offline.
+const defaultOfflineProbeInterval = 30 * time.Second
+
+// ErrOffline is returned for reports submitted while the client is offline,
+// see OfflineDetection. The reports are kept by the offline storage.
+var ErrOffline = errors.New("Raygun is unreachable, the report is stored for later delivery")
+
+// offlineState tracks the network failures of the submissions of a client
+// using the offline storage, see OfflineDetection.
+type offlineState struct {
+	threshold     int           // the number of consecutive network failures going offline, disabled if 0
+	probeInterval time.Duration // the interval Raygun is probed at while offline
+
+	failures int  // the number of consecutive network failures
+	offline  bool // if true, reports are stored without contacting Raygun
+}
+
+// OfflineDetection is a chainable option-setting method to take the client
+// offline after threshold consecutive network failures, like DNS, dial or
+// timeout errors: reports then go to the offline storage at once, returning
+// ErrOffline, and the storage probes Raygun every probeInterval, draining its
+// reports in order once Raygun is reachable again. It has no effect without
+// OfflineStorage, which sets the maximum number of reports kept. A threshold
+// of 0 or less disables it, its default, and a probeInterval of 0 or less
+// restores the default of 30 seconds.
+func (c *Client) OfflineDetection(threshold int, probeInterval time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	if threshold < 0 {
+		threshold = 0
+	}
+	if probeInterval <= 0 {
+		probeInterval = defaultOfflineProbeInterval
+	}
+	c.offlineThreshold, c.offlineProbeInterval = threshold, probeInterval
+	c.offline.detect(threshold, probeInterval)
+	return c
+}
+
+// networkFailure reports whether a submission failing with err could not
+// reach Raygun at all, as opposed to being answered or canceled.
+func networkFailure(err error) bool {
+	var submitErr *SubmitError
+	return errors.As(err, &submitErr) && submitErr.StatusCode == 0 && !submitErr.Canceled
+}
+
+// detect sets the offline detection parameters and goes online if the
+// detection is disabled.
+func (s *offlineStore) detect(threshold int, probeInterval time.Duration) {
+	if s == nil {
+		return
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.state.threshold, s.state.probeInterval = threshold, probeInterval
+	if threshold == 0 {
+		s.state.failures, s.state.offline = 0, false
+	}
+}
+
+// isOffline reports whether reports are stored without contacting Raygun.
+func (s *offlineStore) isOffline() bool {
+	if s == nil {
+		return false
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.state.offline
+}
+
+// observe records the result of a submission to Raygun, going offline after
+// too many consecutive network failures.
+func (s *offlineStore) observe(err error) {
+	if s == nil {
+		return
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if s.state.threshold == 0 || isCanceled(err) {
+		return
+	}
+	if !networkFailure(err) {
+		s.state.failures = 0
+		return
+	}
+	s.state.failures++
+	if s.state.failures >= s.state.threshold && !s.state.offline {
+		s.state.offline = true
+		select {
+		case s.reschedule <- struct{}{}:
+		default:
+		}
+		s.client.errorf("Raygun is unreachable after %d failures, storing reports for later delivery", s.state.failures)
+	}
+}
+
+// online leaves the offline state once the stored reports were delivered.
+func (s *offlineStore) online() {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.state.failures = 0
+	if s.state.offline {
+		s.client.debugf("Raygun is reachable again, stored reports were delivered")
+	}
+	s.state.offline = false
+}
+
+// retryInterval returns the interval to probe Raygun at while offline, and
+// 0 while online.
+func (s *offlineStore) retryInterval() time.Duration {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if !s.state.offline {
+		return 0
+	}
+	return s.state.probeInterval
+}
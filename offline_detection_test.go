@@ -0,0 +1,143 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// idRecorder records the report ids of the requests it sees.
+type idRecorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *idRecorder) record(req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, req.Header.Get(idempotencyKeyHeader))
+}
+
+func (r *idRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids...)
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestOfflineDetection(t *testing.T) {
+	Convey("OfflineDetection", t, func() {
+		// The endpoint is down until up starts a server listening on its
+		// address.
+		l, _ := net.Listen("tcp", "127.0.0.1:0")
+		addr := l.Addr().String()
+		l.Close()
+		received := &idRecorder{}
+		var server *httptest.Server
+		up := func() {
+			l, err := net.Listen("tcp", addr)
+			So(err, ShouldBeNil)
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received.record(r)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			server.Listener = l
+			server.Start()
+		}
+
+		attempted := &idRecorder{}
+		dir, _ := os.MkdirTemp("", "raygun4go")
+		c, _ := New("app", "key")
+		seq := 0
+		c.newReportID = func() string {
+			seq++
+			return fmt.Sprintf("report-%d", seq)
+		}
+		c.Endpoint("http://"+addr).Retries(0, 0).HTTPClient(&http.Client{
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				attempted.record(r)
+				return http.DefaultTransport.RoundTrip(r)
+			}),
+		})
+		c.OfflineDetection(2, 20*time.Millisecond)
+		c.offline = newOfflineStore(c, dir, 10, time.Hour)
+		Reset(func() {
+			c.Close()
+			if server != nil {
+				server.Close()
+			}
+			os.RemoveAll(dir)
+		})
+		send := func() error {
+			return c.SendError(errors.New("Test OfflineDetection"))
+		}
+		stored := func() int {
+			files, _ := storedReportFiles(dir)
+			return len(files)
+		}
+
+		Convey("stores reports without sending them while offline and drains them in order", func() {
+			So(send(), ShouldNotEqual, ErrOffline)
+			So(c.offline.isOffline(), ShouldBeFalse)
+			So(send(), ShouldNotEqual, ErrOffline)
+			So(c.offline.isOffline(), ShouldBeTrue)
+
+			So(send(), ShouldEqual, ErrOffline)
+			So(send(), ShouldEqual, ErrOffline)
+			So(stored(), ShouldEqual, 4)
+			So(attempted.recorded(), ShouldNotContain, "report-3")
+			So(attempted.recorded(), ShouldNotContain, "report-4")
+
+			up()
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			So(received.recorded(), ShouldResemble, []string{"report-1", "report-2", "report-3", "report-4"})
+			So(c.offline.isOffline(), ShouldBeFalse)
+
+			So(send(), ShouldBeNil)
+			So(received.recorded(), ShouldHaveLength, 5)
+		})
+
+		Convey("does not go offline on answers of Raygun", func() {
+			up()
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			So(send(), ShouldNotBeNil)
+			So(send(), ShouldNotBeNil)
+			So(send(), ShouldNotEqual, ErrOffline)
+			So(c.offline.isOffline(), ShouldBeFalse)
+		})
+
+		Convey("is disabled by a non-positive threshold", func() {
+			c.OfflineDetection(0, 0)
+			So(send(), ShouldNotBeNil)
+			So(send(), ShouldNotBeNil)
+			So(send(), ShouldNotEqual, ErrOffline)
+			So(c.offline.isOffline(), ShouldBeFalse)
+			So(c.offlineProbeInterval, ShouldEqual, defaultOfflineProbeInterval)
+		})
+
+		Convey("is kept by clones", func() {
+			So(c.Clone().offlineThreshold, ShouldEqual, 2)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.OfflineDetection(1, time.Second), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// eventually reports whether the condition holds within 5 seconds.
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestOfflineStorage(t *testing.T) {
+	Convey("OfflineStorage", t, func() {
+		server := rayguntest.New()
+		dir, _ := os.MkdirTemp("", "raygun4go")
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Retries(0, 0)
+		Reset(func() {
+			c.Close()
+			server.Close()
+			os.RemoveAll(dir)
+		})
+
+		// offlineStorage enables the offline storage retrying quickly.
+		offlineStorage := func(maxReports int) {
+			c.offline = newOfflineStore(c, dir, maxReports, 10*time.Millisecond)
+		}
+		stored := func() int {
+			files, _ := storedReportFiles(dir)
+			return len(files)
+		}
+		send := func(message string) error {
+			return c.SendError(errors.New(message))
+		}
+
+		Convey("stores reports during an outage and delivers them later", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			offlineStorage(10)
+			post := c.createPost(errors.New("Test OfflineStorage"), StackTrace{})
+			post.OccuredOn = "2024-01-02T03:04:05Z"
+			err := c.Submit(post)
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(stored(), ShouldEqual, 1)
+
+			So(eventually(func() bool { return server.Count() >= 3 }), ShouldBeTrue)
+			So(stored(), ShouldEqual, 1)
+
+			server.Fallback(rayguntest.Accepted)
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			requests := server.Requests()
+			last := requests[len(requests)-1]
+			So(last.Header.Get(idempotencyKeyHeader), ShouldEqual, post.ReportID())
+			var entry struct {
+				OccurredOn string `json:"occurredOn"`
+			}
+			So(last.Decode(&entry), ShouldBeNil)
+			So(entry.OccurredOn, ShouldEqual, "2024-01-02T03:04:05Z")
+		})
+
+		Convey("stores reports on network errors", func() {
+			offlineStorage(10)
+			server.Close()
+			So(send("Test OfflineStorage"), ShouldNotBeNil)
+			So(stored(), ShouldEqual, 1)
+		})
+
+		Convey("delivers the reports of a previous run at once", func() {
+			var reports []queuedReport
+			for i := 0; i < 3; i++ {
+				reports = append(reports, queuedReport{post: c.createPost(fmt.Errorf("Test OfflineStorage %d", i), StackTrace{})})
+			}
+			So(persistReports(dir, reports), ShouldBeNil)
+
+			offlineStorage(10)
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			So(server.Count(), ShouldEqual, 3)
+			var entry struct {
+				Details struct {
+					Error struct {
+						Message string `json:"message"`
+					} `json:"error"`
+				} `json:"details"`
+			}
+			server.Requests()[0].Decode(&entry)
+			So(entry.Details.Error.Message, ShouldEqual, "Test OfflineStorage 0")
+		})
+
+		Convey("keeps the newest reports up to the limit", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			offlineStorage(3)
+			for i := 0; i < 5; i++ {
+				So(send(fmt.Sprintf("Test OfflineStorage %d", i)), ShouldNotBeNil)
+			}
+			So(stored(), ShouldEqual, 3)
+			files, _ := storedReportFiles(dir)
+			oldest, err := readStoredReport(files[0])
+			So(err, ShouldBeNil)
+			So(oldest.Post.Details.Error.Message, ShouldEqual, "Test OfflineStorage 2")
+		})
+
+		Convey("stores reports while rate limited", func() {
+			server.Script(rayguntest.TooManyRequests("60"))
+			offlineStorage(10)
+			So(errors.Is(send("Test OfflineStorage"), ErrRateLimited), ShouldBeTrue)
+			So(errors.Is(send("Test OfflineStorage"), ErrRateLimited), ShouldBeTrue)
+			So(stored(), ShouldEqual, 2)
+		})
+
+		Convey("does not store reports rejected for good", func() {
+			server.Script(rayguntest.BadRequest("invalid"))
+			offlineStorage(10)
+			So(send("Test OfflineStorage"), ShouldNotBeNil)
+			So(stored(), ShouldEqual, 0)
+		})
+
+		Convey("does not write the API key of WithApplication to disk nor send the report with the key of the client", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			offlineStorage(10)
+			So(c.SendError(errors.New("Test OfflineStorage"), WithApplication("platform", "platform-key")), ShouldNotBeNil)
+			files, _ := storedReportFiles(dir)
+			So(files, ShouldHaveLength, 1)
+			data, _ := os.ReadFile(files[0])
+			So(string(data), ShouldNotContainSubstring, "platform-key")
+
+			server.Fallback(rayguntest.Accepted)
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			for _, request := range server.Requests() {
+				So(request.APIKey(), ShouldEqual, "platform-key")
+			}
+		})
+
+		Convey("delivers reports of WithApplication with the key resolved by APIKeySelector", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			offlineStorage(10)
+			c.APIKeySelector(func(post PostData) string {
+				return map[string]string{"platform": "resolved-key"}[post.Application()]
+			})
+			So(c.SendError(errors.New("Test OfflineStorage"), WithApplication("platform", "platform-key")), ShouldNotBeNil)
+			So(stored(), ShouldEqual, 1)
+
+			server.Fallback(rayguntest.Accepted)
+			So(eventually(func() bool { return stored() == 0 }), ShouldBeTrue)
+			requests := server.Requests()
+			So(requests[len(requests)-1].APIKey(), ShouldEqual, "resolved-key")
+		})
+
+		Convey("does not store canceled submissions", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			offlineStorage(10)
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			So(c.SendErrorWithContext(ctx, errors.New("Test OfflineStorage")), ShouldNotBeNil)
+			So(stored(), ShouldEqual, 0)
+		})
+
+		Convey("is stopped by Close", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			offlineStorage(10)
+			So(send("Test OfflineStorage"), ShouldNotBeNil)
+			So(c.Close(), ShouldBeNil)
+			time.Sleep(20 * time.Millisecond)
+			count := server.Count()
+			time.Sleep(50 * time.Millisecond)
+			So(server.Count(), ShouldEqual, count)
+			So(stored(), ShouldEqual, 1)
+		})
+
+		Convey("is disabled by an empty directory", func() {
+			c.OfflineStorage(dir, 10).OfflineStorage("", 10)
+			So(c.offline, ShouldBeNil)
+			server.Script(rayguntest.Status(http.StatusServiceUnavailable))
+			So(send("Test OfflineStorage"), ShouldNotBeNil)
+			So(stored(), ShouldEqual, 0)
+		})
+	})
+}
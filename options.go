@@ -0,0 +1,220 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidOption is matched by the errors New returns for options with
+// invalid values, see errors.Is.
+var ErrInvalidOption = errors.New("invalid raygun4go option")
+
+// Option configures a client created by New. Unlike the chainable
+// option-setting methods they mirror, options reject invalid values: New
+// fails with the error of the first option returning one.
+//
+//	raygun, err := raygun4go.New("myapp", apiKey,
+//		raygun4go.WithVersion("1.2.3"),
+//		raygun4go.WithAsynchronous(true),
+//		raygun4go.WithEndpoint(endpoint),
+//	)
+type Option func(*Client) error
+
+// invalidOption returns an error matching ErrInvalidOption for the option of
+// the given name and its invalid value.
+func invalidOption(name string, value interface{}) error {
+	return fmt.Errorf("%w %s(%v)", ErrInvalidOption, name, value)
+}
+
+// WithVersion sets the version of the application like Version.
+func WithVersion(version string) Option {
+	return func(c *Client) error {
+		c.Version(version)
+		return nil
+	}
+}
+
+// WithDefaultTags sets the tags of all reports like Tags. Unlike the
+// WithTags report option, it applies to every report of the client.
+func WithDefaultTags(tags ...string) Option {
+	return func(c *Client) error {
+		c.Tags(tags)
+		return nil
+	}
+}
+
+// WithCustomData sets the custom data of all reports like CustomData.
+func WithCustomData(data interface{}) Option {
+	return func(c *Client) error {
+		c.CustomData(data)
+		return nil
+	}
+}
+
+// WithUser sets the user of all reports like User.
+func WithUser(user string) Option {
+	return func(c *Client) error {
+		c.User(user)
+		return nil
+	}
+}
+
+// WithAsynchronous sets asynchronous mode like Asynchronous.
+func WithAsynchronous(a bool) Option {
+	return func(c *Client) error {
+		c.Asynchronous(a)
+		return nil
+	}
+}
+
+// WithSilent sets silent mode like Silent.
+func WithSilent(s bool) Option {
+	return func(c *Client) error {
+		c.Silent(s)
+		return nil
+	}
+}
+
+// WithLogToStdOut sets logging like LogToStdOut.
+func WithLogToStdOut(l bool) Option {
+	return func(c *Client) error {
+		c.LogToStdOut(l)
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the diagnostic messages like Logger.
+func WithLogger(l Logger) Option {
+	return func(c *Client) error {
+		c.Logger(l)
+		return nil
+	}
+}
+
+// WithEnabled switches the client off or on like Enabled.
+func WithEnabled(enabled bool) Option {
+	return func(c *Client) error {
+		c.Enabled(enabled)
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client reports are posted with like
+// HTTPClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		c.HTTPClient(client)
+		return nil
+	}
+}
+
+// WithTransport sets the transport reports are delivered with like
+// Transport.
+func WithTransport(t Transport) Option {
+	return func(c *Client) error {
+		c.Transport(t)
+		return nil
+	}
+}
+
+// WithEndpoint sets the URL of the Raygun API like SetEndpoint, failing for
+// invalid URLs.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) error {
+		return c.SetEndpoint(endpoint)
+	}
+}
+
+// WithRegion sets the region of the Raygun API like SetRegion, failing for
+// unknown regions.
+func WithRegion(name string) Option {
+	return func(c *Client) error {
+		return c.SetRegion(name)
+	}
+}
+
+// WithTimeout sets the maximum time a request may take like Timeout,
+// failing unless d is positive.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return invalidOption("WithTimeout", d)
+		}
+		c.Timeout(d)
+		return nil
+	}
+}
+
+// WithRetries sets the retries of failed requests like Retries, failing for
+// negative values.
+func WithRetries(max int, baseDelay time.Duration) Option {
+	return func(c *Client) error {
+		if max < 0 || baseDelay < 0 {
+			return invalidOption("WithRetries", fmt.Sprintf("%d, %s", max, baseDelay))
+		}
+		c.Retries(max, baseDelay)
+		return nil
+	}
+}
+
+// WithBatch sets batching like Batch, failing unless maxSize is between 1
+// and 1000 and flushInterval is positive.
+func WithBatch(maxSize int, flushInterval time.Duration) Option {
+	return func(c *Client) error {
+		if maxSize <= 0 || maxSize > defaultQueueSize || flushInterval <= 0 {
+			return invalidOption("WithBatch", fmt.Sprintf("%d, %s", maxSize, flushInterval))
+		}
+		c.Batch(maxSize, flushInterval)
+		return nil
+	}
+}
+
+// WithQueueOverflow sets the asynchronous queue like QueueOverflow, failing
+// for unknown policies and unless size is between 1 and 1000.
+func WithQueueOverflow(policy OverflowPolicy, size int) Option {
+	return func(c *Client) error {
+		if (policy != DropNewest && policy != DropOldest && policy != Block) || size <= 0 || size > defaultQueueSize {
+			return invalidOption("WithQueueOverflow", fmt.Sprintf("%d, %d", policy, size))
+		}
+		c.QueueOverflow(policy, size)
+		return nil
+	}
+}
+
+// WithRateLimit limits the reports sent a minute like RateLimit, failing
+// unless maxPerMinute is positive.
+func WithRateLimit(maxPerMinute int) Option {
+	return func(c *Client) error {
+		if maxPerMinute <= 0 {
+			return invalidOption("WithRateLimit", maxPerMinute)
+		}
+		c.RateLimit(maxPerMinute)
+		return nil
+	}
+}
+
+// WithSampleRate sends a fraction of the reports like SampleRate, failing
+// unless fraction is between 0 and 1.
+func WithSampleRate(fraction float64) Option {
+	return func(c *Client) error {
+		if fraction < 0 || fraction > 1 {
+			return invalidOption("WithSampleRate", fraction)
+		}
+		c.SampleRate(fraction)
+		return nil
+	}
+}
+
+// WithOfflineStorage keeps undeliverable reports on disk like
+// OfflineStorage, failing unless dir is set and maxReports is positive.
+func WithOfflineStorage(dir string, maxReports int) Option {
+	return func(c *Client) error {
+		if dir == "" || maxReports <= 0 {
+			return invalidOption("WithOfflineStorage", fmt.Sprintf("%q, %d", dir, maxReports))
+		}
+		c.OfflineStorage(dir, maxReports)
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptions(t *testing.T) {
+	Convey("New with options", t, func() {
+		Convey("applies the options", func() {
+			httpClient := &http.Client{}
+			transport := NewRecordingTransport()
+			logger := StdLogger(nil)
+			c, err := New("app", "key",
+				WithVersion("1.2.3"),
+				WithDefaultTags("a", "b"),
+				WithCustomData(map[string]string{"k": "v"}),
+				WithUser("user"),
+				WithAsynchronous(true),
+				WithSilent(true),
+				WithLogToStdOut(true),
+				WithLogger(logger),
+				WithHTTPClient(httpClient),
+				WithTransport(transport),
+				WithEndpoint("https://collector.example.com/"),
+				WithTimeout(3*time.Second),
+				WithRetries(2, time.Millisecond),
+				WithQueueOverflow(DropOldest, 10),
+				WithRateLimit(60),
+				WithSampleRate(0.5),
+			)
+			So(err, ShouldBeNil)
+			defer c.Close()
+
+			So(c.context.Version, ShouldEqual, "1.2.3")
+			So(c.context.Tags, ShouldResemble, []string{"a", "b"})
+			So(c.context.CustomData, ShouldResemble, map[string]string{"k": "v"})
+			So(c.context.User, ShouldEqual, "user")
+			So(c.asynchronous, ShouldBeTrue)
+			So(c.silent, ShouldBeTrue)
+			So(c.logToStdOut, ShouldBeTrue)
+			So(c.logger, ShouldEqual, logger)
+			So(c.httpClient, ShouldEqual, httpClient)
+			So(c.transport, ShouldEqual, transport)
+			So(c.endpoint, ShouldEqual, "https://collector.example.com")
+			So(c.submitTimeout, ShouldEqual, 3*time.Second)
+			So(c.maxRetries, ShouldEqual, 2)
+			So(c.retryBaseDelay, ShouldEqual, time.Millisecond)
+			So(c.queue.policy, ShouldEqual, DropOldest)
+			So(c.queue.size, ShouldEqual, 10)
+			So(c.limiter.perMinute, ShouldEqual, 60)
+			So(c.sampler.fraction, ShouldEqual, 0.5)
+		})
+
+		Convey("applies the options in order", func() {
+			c, err := New("app", "key", WithRegion("eu"), WithBatch(10, time.Minute), WithVersion("1"), WithVersion("2"))
+			So(err, ShouldBeNil)
+			defer c.Close()
+			So(c.endpoint, ShouldEqual, EndpointEU)
+			So(c.batch.maxSize, ShouldEqual, 10)
+			So(c.context.Version, ShouldEqual, "2")
+		})
+
+		Convey("sets the offline storage", func() {
+			dir, _ := os.MkdirTemp("", "raygun4go")
+			defer os.RemoveAll(dir)
+			c, err := New("app", "key", WithOfflineStorage(dir, 5))
+			So(err, ShouldBeNil)
+			defer c.Close()
+			So(c.offline.dir, ShouldEqual, dir)
+			So(c.offline.maxReports, ShouldEqual, 5)
+		})
+
+		Convey("returns the error of the first failing option", func() {
+			c, err := New("app", "key", WithVersion("1"), WithEndpoint("ftp://example.com"), WithTimeout(0))
+			So(c, ShouldBeNil)
+			So(err.Error(), ShouldContainSubstring, `"ftp://example.com" is not an http or https URL`)
+
+			_, err = New("app", "key", WithRegion("mars"))
+			So(errors.Is(err, ErrUnknownRegion), ShouldBeTrue)
+		})
+
+		Convey("rejects invalid values", func() {
+			for _, opt := range []Option{
+				WithTimeout(0),
+				WithRetries(-1, 0),
+				WithBatch(0, time.Minute),
+				WithBatch(10, 0),
+				WithQueueOverflow(OverflowPolicy(42), 10),
+				WithQueueOverflow(DropNewest, 1001),
+				WithRateLimit(0),
+				WithSampleRate(1.5),
+				WithOfflineStorage("", 10),
+			} {
+				_, err := New("app", "key", opt)
+				So(errors.Is(err, ErrInvalidOption), ShouldBeTrue)
+			}
+			_, err := New("app", "key", WithTimeout(-time.Second))
+			So(err.Error(), ShouldEqual, "invalid raygun4go option WithTimeout(-1s)")
+		})
+
+		Convey("still requires appName and apiKey", func() {
+			_, err := New("", "key", WithVersion("1"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
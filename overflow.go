@@ -0,0 +1,83 @@
+package raygun4go
+
+// OverflowPolicy decides what happens to reports submitted in asynchronous
+// mode while the queue is full, see QueueOverflow.
+type OverflowPolicy int
+
+const (
+	// DropNewest rejects the submitted report with ErrQueueFull. It is the
+	// default.
+	DropNewest OverflowPolicy = iota
+
+	// DropOldest discards the oldest queued report of the same priority to
+	// make room for the submitted one.
+	DropOldest
+
+	// Block makes the submission wait for room in the queue, until the
+	// context passed to SubmitWithContext is done or the client is closed.
+	Block
+)
+
+// String returns the name of the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "drop-oldest"
+	case Block:
+		return "block"
+	default:
+		return "drop-newest"
+	}
+}
+
+// QueueOverflow is a chainable option-setting method to set the number of
+// reports the asynchronous queue holds and what happens to reports submitted
+// while it is full. Reports in delivery do not count against the size, and
+// queued reports of a lower priority are dropped first, see Priority.
+// Discarded reports are counted by Stats and passed to the function set with
+// OnDrop. A size of 0 or less restores the default of 1000, which is also the
+// maximum, and unknown policies are treated as DropNewest. The queue is
+// shared with all clones, so QueueOverflow changes it for them as well.
+// Batching keeps its own buffer, which rejects reports once it holds 1000.
+func (c *Client) QueueOverflow(policy OverflowPolicy, size int) *Client {
+	if c == nil {
+		return nil
+	}
+	if size <= 0 || size > defaultQueueSize {
+		size = defaultQueueSize
+	}
+	if policy != DropOldest && policy != Block {
+		policy = DropNewest
+	}
+	c.queue.setOverflow(policy, size)
+	return c
+}
+
+// OnDrop is a chainable option-setting method to set a function called with
+// every report the client submitted that is discarded without a delivery
+// attempt: because the asynchronous queue or the batching buffer was full,
+// the context of a blocked submission was done, or Close gave up on it
+// without a directory to write it to. The function is called synchronously,
+// e.g. to log the error message locally, and must not block. Passing nil
+// removes it.
+func (c *Client) OnDrop(fn func(PostData)) *Client {
+	if c == nil {
+		return nil
+	}
+	c.onDrop = fn
+	return c
+}
+
+// dropReports counts the given reports as dropped, passes them to the OnDrop
+// function of the clients that submitted them and resolves their results
+// with err, the reason they were dropped.
+func dropReports(err error, reports ...queuedReport) {
+	for _, r := range reports {
+		r.client.stats.drop()
+		r.client.metrics.IncDropped()
+		r.result.resolve(err)
+		if r.client.onDrop != nil {
+			r.client.onDrop(r.post)
+		}
+	}
+}
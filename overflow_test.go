@@ -0,0 +1,197 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// gatedTransport records reports like RecordingTransport, but holds every
+// delivery until it is released.
+type gatedTransport struct {
+	*RecordingTransport
+	started chan struct{}
+	release chan struct{}
+}
+
+func (t gatedTransport) Send(ctx context.Context, post PostData) error {
+	t.started <- struct{}{}
+	<-t.release
+	return t.RecordingTransport.Send(ctx, post)
+}
+
+// messages returns the error messages of the given reports.
+func messages(posts []PostData) []string {
+	var m []string
+	for _, post := range posts {
+		m = append(m, post.Details.Error.Message)
+	}
+	return m
+}
+
+func TestQueueOverflow(t *testing.T) {
+	Convey("QueueOverflow", t, func() {
+		transport := gatedTransport{NewRecordingTransport(), make(chan struct{}, 10), make(chan struct{})}
+		var mu sync.Mutex
+		var dropped []PostData
+		c, _ := New("app", "key")
+		c.Asynchronous(true).Transport(transport).OnDrop(func(post PostData) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped = append(dropped, post)
+		})
+		var releaseOnce sync.Once
+		Reset(func() {
+			releaseOnce.Do(func() { close(transport.release) })
+			c.Close()
+		})
+
+		// fill submits the first report, waits until its delivery started and
+		// then fills the queue of two reports.
+		fill := func() {
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			<-transport.started
+			So(c.SendError(errors.New("report 2")), ShouldBeNil)
+			So(c.SendError(errors.New("report 3")), ShouldBeNil)
+		}
+		deliver := func() []string {
+			releaseOnce.Do(func() { close(transport.release) })
+			So(c.Flush(context.Background()), ShouldBeNil)
+			return messages(transport.Posts())
+		}
+
+		Convey("rejects new reports with DropNewest", func() {
+			c.QueueOverflow(DropNewest, 2)
+			fill()
+			So(c.SendError(errors.New("report 4")), ShouldEqual, ErrQueueFull)
+
+			So(deliver(), ShouldResemble, []string{"report 1", "report 2", "report 3"})
+			So(messages(dropped), ShouldResemble, []string{"report 4"})
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 4, Succeeded: 3, Dropped: 1})
+		})
+
+		Convey("discards the oldest report with DropOldest", func() {
+			c.QueueOverflow(DropOldest, 2)
+			fill()
+			So(c.SendError(errors.New("report 4")), ShouldBeNil)
+			So(c.SendError(errors.New("report 5")), ShouldBeNil)
+
+			So(deliver(), ShouldResemble, []string{"report 1", "report 4", "report 5"})
+			So(messages(dropped), ShouldResemble, []string{"report 2", "report 3"})
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 5, Succeeded: 3, Dropped: 2})
+		})
+
+		Convey("waits for room with Block", func() {
+			c.QueueOverflow(Block, 2)
+			fill()
+			returned := make(chan error, 1)
+			go func() {
+				returned <- c.SendError(errors.New("report 4"))
+			}()
+			blocked := true
+			select {
+			case <-returned:
+				blocked = false
+			case <-time.After(50 * time.Millisecond):
+			}
+			So(blocked, ShouldBeTrue)
+
+			releaseOnce.Do(func() { close(transport.release) })
+			So(<-returned, ShouldBeNil)
+			So(deliver(), ShouldResemble, []string{"report 1", "report 2", "report 3", "report 4"})
+			So(dropped, ShouldBeEmpty)
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 4, Succeeded: 4})
+		})
+
+		Convey("waits with Block until the context is done", func() {
+			c.QueueOverflow(Block, 2)
+			fill()
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			post := c.createPost(errors.New("report 4"), StackTrace{})
+			So(c.SubmitWithContext(ctx, post), ShouldEqual, context.DeadlineExceeded)
+			So(messages(dropped), ShouldResemble, []string{"report 4"})
+
+			So(deliver(), ShouldHaveLength, 3)
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 4, Succeeded: 3, Dropped: 1})
+		})
+
+		Convey("counts failed deliveries", func() {
+			transport.Fail(errors.New("Test failure"))
+			for i := 0; i < 3; i++ {
+				So(c.SendError(fmt.Errorf("report %d", i)), ShouldBeNil)
+			}
+			deliver()
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 3, Failed: 3})
+		})
+
+		Convey("treats unknown policies as DropNewest", func() {
+			c.QueueOverflow(OverflowPolicy(42), 2)
+			So(c.queue.policy, ShouldEqual, DropNewest)
+			c.QueueOverflow(Block, 0)
+			So(c.queue.size, ShouldEqual, defaultQueueSize)
+		})
+	})
+}
+
+func TestPending(t *testing.T) {
+	Convey("#Pending and #InFlight", t, func() {
+		transport := gatedTransport{NewRecordingTransport(), make(chan struct{}, 10), make(chan struct{})}
+		c, _ := New("app", "key")
+		c.Transport(transport)
+		var releaseOnce sync.Once
+		release := func() {
+			releaseOnce.Do(func() { close(transport.release) })
+		}
+		Reset(func() {
+			release()
+			c.Close()
+		})
+
+		Convey("count the reports of the asynchronous queue", func() {
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			<-transport.started
+			for i := 2; i <= 4; i++ {
+				So(c.SendError(fmt.Errorf("report %d", i)), ShouldBeNil)
+			}
+			So(c.Pending(), ShouldEqual, 3)
+			So(c.InFlight(), ShouldEqual, 1)
+			So(c.Clone().Stats(), ShouldResemble, Stats{Submitted: 4, Pending: 3, InFlight: 1})
+
+			release()
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(c.Pending(), ShouldEqual, 0)
+			So(c.InFlight(), ShouldEqual, 0)
+		})
+
+		Convey("count the reports of the batch", func() {
+			c.Batch(10, time.Hour)
+			for i := 1; i <= 3; i++ {
+				So(c.SendError(fmt.Errorf("report %d", i)), ShouldBeNil)
+			}
+			So(c.Pending(), ShouldEqual, 3)
+
+			flushed := make(chan error)
+			go func() { flushed <- c.Flush(context.Background()) }()
+			<-transport.started
+			So(c.Pending(), ShouldEqual, 0)
+			So(c.InFlight(), ShouldEqual, 3)
+
+			release()
+			So(<-flushed, ShouldBeNil)
+			So(c.InFlight(), ShouldEqual, 0)
+		})
+
+		Convey("are 0 for a nil client", func() {
+			var nilClient *Client
+			So(nilClient.Pending(), ShouldEqual, 0)
+			So(nilClient.InFlight(), ShouldEqual, 0)
+		})
+	})
+}
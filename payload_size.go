@@ -0,0 +1,195 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxPayloadSize is the size limit of Raygun for reports, 128 KiB.
+	defaultMaxPayloadSize = 128 << 10
+
+	// truncatedTag is added to reports trimmed to fit the payload size
+	// limit.
+	truncatedTag = "raygun4go-truncated"
+
+	// truncatedCustomDataKey is the custom data key replacing the custom data
+	// removed to fit the payload size limit.
+	truncatedCustomDataKey = "truncatedCustomData"
+
+	// maxTrimmedValueLength is the number of bytes request values are cut to
+	// when trimming a report.
+	maxTrimmedValueLength = 256
+
+	// minTrimmedFrames is the number of stack frames kept at least when
+	// trimming a report.
+	minTrimmedFrames = 10
+)
+
+// MaxPayloadSize is a chainable option-setting method to set the size in
+// bytes of the largest JSON payload sent to Raygun, 128 KiB by default as
+// Raygun rejects larger reports. Larger reports are trimmed until they fit:
+// first the custom data is replaced by a note with its original size, then
+// the values of the form, headers and query string are cut to 256 bytes,
+// then the stack trace is halved down to its top 10 frames. Trimmed reports
+// are tagged "raygun4go-truncated". Reports submitted with SubmitBytes are
+// sent as they are. A non-positive size restores the default.
+func (c *Client) MaxPayloadSize(n int) *Client {
+	if c == nil {
+		return nil
+	}
+	if n <= 0 {
+		n = defaultMaxPayloadSize
+	}
+	c.maxPayloadSize = n
+	return c
+}
+
+// fitPayload returns the given payload of the given post, or if it exceeds
+// the payload size limit, the payload of the post trimmed as described for
+// MaxPayloadSize. The trimmed payload may still exceed the limit if the
+// remaining parts of the post are too large.
+func (c *Client) fitPayload(post PostData, payload []byte) ([]byte, error) {
+	if len(payload) <= c.maxPayloadSize {
+		return payload, nil
+	}
+	original := len(payload)
+
+	post.Details.Tags = append(append([]string(nil), post.Details.Tags...), truncatedTag)
+	trims := []func(*PostData) bool{trimCustomData, trimRequestValues, trimStackTrace}
+	for _, trim := range trims {
+		for trim(&post) {
+			var err error
+			if payload, err = json.Marshal(post); err != nil {
+				return nil, newKindError(ErrMarshalFailure, err, "Unable to convert to JSON (%s): %#v", err.Error(), post)
+			}
+			if len(payload) <= c.maxPayloadSize {
+				c.logTruncation(original, len(payload))
+				return payload, nil
+			}
+		}
+	}
+	c.logTruncation(original, len(payload))
+	return payload, nil
+}
+
+// logTruncation logs that a report was trimmed from the original size to the
+// given size, if LogToStdOut is set.
+func (c *Client) logTruncation(original, size int) {
+	c.debugf("Trimmed report of %d bytes to %d bytes to fit the payload size limit of %d bytes", original, size, c.maxPayloadSize)
+}
+
+// trimCustomData replaces the custom data of the given post by a note with
+// its original size, keeping the report id. It reports whether the post
+// changed.
+func trimCustomData(post *PostData) bool {
+	data := post.Details.UserCustomData
+	if data == nil {
+		return false
+	}
+	if m, ok := data.(map[string]interface{}); ok {
+		if _, done := m[truncatedCustomDataKey]; done {
+			return false
+		}
+	}
+
+	encoded, _ := json.Marshal(data)
+	trimmed := map[string]interface{}{
+		truncatedCustomDataKey: map[string]interface{}{
+			"note":         "Removed to fit the payload size limit",
+			"originalSize": len(encoded),
+		},
+	}
+	if id := post.ReportID(); id != "" {
+		trimmed[reportIDKey] = id
+	}
+	post.Details.UserCustomData = trimmed
+	return true
+}
+
+// trimRequestValues cuts the form, header and query string values of the
+// given post to maxTrimmedValueLength bytes. It reports whether the post
+// changed.
+func trimRequestValues(post *PostData) bool {
+	request := &post.Details.Request
+	changed := false
+	for _, values := range []*map[string]string{&request.Form, &request.Headers, &request.QueryString} {
+		if trimmed, ok := trimValues(*values); ok {
+			*values = trimmed
+			changed = true
+		}
+	}
+	if trimmed, ok := trimMultiValues(request.queryValues); ok {
+		request.queryValues = trimmed
+		changed = true
+	}
+	return changed
+}
+
+// trimValues returns a copy of the given map with the values cut to
+// maxTrimmedValueLength bytes and whether any value was cut.
+func trimValues(values map[string]string) (map[string]string, bool) {
+	var trimmed map[string]string
+	for key, value := range values {
+		if len(value) <= maxTrimmedValueLength {
+			continue
+		}
+		if trimmed == nil {
+			trimmed = make(map[string]string, len(values))
+			for k, v := range values {
+				trimmed[k] = v
+			}
+		}
+		trimmed[key] = trimValue(value)
+	}
+	return trimmed, trimmed != nil
+}
+
+// trimMultiValues is trimValues for maps holding several values per key.
+func trimMultiValues(values map[string][]string) (map[string][]string, bool) {
+	changed := false
+	trimmed := make(map[string][]string, len(values))
+	for key, vs := range values {
+		trimmed[key] = make([]string, len(vs))
+		for i, v := range vs {
+			if len(v) > maxTrimmedValueLength {
+				v = trimValue(v)
+				changed = true
+			}
+			trimmed[key][i] = v
+		}
+	}
+	return trimmed, changed
+}
+
+// trimValue cuts the given value to maxTrimmedValueLength bytes without
+// splitting a character, marking the cut with an ellipsis.
+func trimValue(value string) string {
+	cut := maxTrimmedValueLength - len("…")
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + "…"
+}
+
+// trimStackTrace halves the stack trace of the given post, keeping at least
+// its top minTrimmedFrames frames and marking the cut like a truncated stack
+// trace. It reports whether the post changed.
+func trimStackTrace(post *PostData) bool {
+	st := post.Details.Error.StackTrace
+	if n := len(st); n > 0 && st[n-1].FileName == "" && isTruncationMarker(st[n-1].MethodName) {
+		st = st[:n-1]
+	}
+	if len(st) <= minTrimmedFrames {
+		return false
+	}
+
+	keep := len(st) / 2
+	if keep < minTrimmedFrames {
+		keep = minTrimmedFrames
+	}
+	trimmed := append(StackTrace(nil), st[:keep]...)
+	trimmed.AddEntry(0, "", "", stackTruncatedMarker)
+	post.Details.Error.StackTrace = trimmed
+	return true
+}
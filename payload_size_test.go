@@ -0,0 +1,126 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMaxPayloadSize(t *testing.T) {
+	Convey("MaxPayloadSize", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		type entry struct {
+			Details struct {
+				Error struct {
+					Message    string     `json:"message"`
+					StackTrace StackTrace `json:"stackTrace"`
+				} `json:"error"`
+				Tags           []string               `json:"tags"`
+				UserCustomData map[string]interface{} `json:"userCustomData"`
+				Request        RequestData            `json:"request"`
+			} `json:"details"`
+		}
+		sent := func() (rayguntest.Request, entry) {
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 1)
+			var e entry
+			So(requests[0].Decode(&e), ShouldBeNil)
+			return requests[0], e
+		}
+
+		Convey("defaults to the limit of Raygun", func() {
+			So(c.maxPayloadSize, ShouldEqual, 128<<10)
+			So(c.MaxPayloadSize(1000).MaxPayloadSize(0).maxPayloadSize, ShouldEqual, 128<<10)
+			So(c.Clone().maxPayloadSize, ShouldEqual, 128<<10)
+		})
+
+		Convey("leaves reports within the limit unchanged", func() {
+			c.CustomData(map[string]interface{}{"blob": strings.Repeat("x", 1000)})
+			So(c.SendError(errors.New("Test MaxPayloadSize")), ShouldBeNil)
+			_, e := sent()
+			So(e.Details.Tags, ShouldNotContain, "raygun4go-truncated")
+			So(e.Details.UserCustomData["blob"], ShouldHaveLength, 1000)
+		})
+
+		Convey("replaces oversized custom data by a note", func() {
+			c.CustomData(map[string]interface{}{"blob": strings.Repeat("x", 200<<10)})
+			post := c.createPost(errors.New("Test MaxPayloadSize"), StackTrace{})
+			So(c.Submit(post), ShouldBeNil)
+			request, e := sent()
+			So(len(request.Body), ShouldBeLessThanOrEqualTo, 128<<10)
+			So(e.Details.Error.Message, ShouldEqual, "Test MaxPayloadSize")
+			So(e.Details.Tags, ShouldContain, "raygun4go-truncated")
+			So(e.Details.UserCustomData[reportIDKey], ShouldEqual, post.ReportID())
+			So(e.Details.UserCustomData, ShouldNotContainKey, "blob")
+			note := e.Details.UserCustomData[truncatedCustomDataKey].(map[string]interface{})
+			So(note["originalSize"], ShouldBeGreaterThan, 200<<10)
+		})
+
+		Convey("cuts request values", func() {
+			r := httptest.NewRequest("POST", "/orders?q="+strings.Repeat("q", 2000), nil)
+			for i := 0; i < 20; i++ {
+				r.Header.Set(fmt.Sprintf("X-Large-%d", i), strings.Repeat("é", 1000))
+			}
+			c.Request(r).MaxPayloadSize(16 << 10)
+			So(c.SendError(errors.New("Test MaxPayloadSize")), ShouldBeNil)
+			request, e := sent()
+			So(len(request.Body), ShouldBeLessThanOrEqualTo, 16<<10)
+			So(e.Details.Tags, ShouldContain, "raygun4go-truncated")
+			value := e.Details.Request.Headers["X-Large-0"]
+			So(len(value), ShouldBeLessThanOrEqualTo, maxTrimmedValueLength)
+			So(value, ShouldStartWith, "éé")
+			So(value, ShouldEndWith, "…")
+			So(e.Details.Request.QueryString["q"], ShouldEndWith, "…")
+		})
+
+		Convey("halves the stack trace", func() {
+			var st StackTrace
+			for i := 0; i < 400; i++ {
+				st.AddEntry(i, "main", fmt.Sprintf("/src/app/handlers/very/deep/path/file%d.go", i), fmt.Sprintf("handler%d.func%d", i, i))
+			}
+			c.MaxPayloadSize(8 << 10)
+			post := c.createPost(errors.New("Test MaxPayloadSize"), st)
+			So(c.Submit(post), ShouldBeNil)
+			request, e := sent()
+			So(len(request.Body), ShouldBeLessThanOrEqualTo, 8<<10)
+			So(e.Details.Error.Message, ShouldEqual, "Test MaxPayloadSize")
+			frames := e.Details.Error.StackTrace
+			So(len(frames), ShouldBeBetween, minTrimmedFrames, 100)
+			So(frames[:minTrimmedFrames], ShouldResemble, st[:minTrimmedFrames])
+			So(frames[len(frames)-1].MethodName, ShouldEqual, stackTruncatedMarker)
+		})
+
+		Convey("keeps the top frames if the report still does not fit", func() {
+			var st StackTrace
+			for i := 0; i < 50; i++ {
+				st.AddEntry(i, "main", "file.go", fmt.Sprintf("handler%d", i))
+			}
+			c.MaxPayloadSize(100)
+			post := c.createPost(errors.New("Test MaxPayloadSize"), st)
+			payload, err := c.encodePost(post)
+			So(err, ShouldBeNil)
+			So(string(payload), ShouldContainSubstring, "raygun4go-truncated")
+			So(string(payload), ShouldContainSubstring, "handler9")
+			So(string(payload), ShouldNotContainSubstring, "handler10")
+		})
+
+		Convey("sends SubmitBytes payloads as they are", func() {
+			c.MaxPayloadSize(100)
+			post := c.createPost(errors.New("Test MaxPayloadSize"), StackTrace{})
+			payload, _ := post.MarshalJSON()
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			request, _ := sent()
+			So(string(request.Body), ShouldEqual, string(payload))
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// pingTag is the tag of the reports sent by Ping.
+	pingTag = "raygun4go-ping"
+
+	// pingDataKey is the custom data key marking the reports sent by Ping.
+	pingDataKey = "ping"
+)
+
+// Ping posts a minimal throwaway report at once, in any mode, to check the
+// API key and the connection to Raygun. It returns nil if Raygun accepted the
+// report, and an error matching ErrInvalidAPIKey if it rejected the key.
+func (c *Client) Ping(ctx context.Context) error {
+	if c == nil {
+		return ErrNoClient
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown host"
+	}
+	message := fmt.Sprintf("raygun4go ping from %s, safe to ignore", hostname)
+
+	var opts reportOptions
+	WithTags(pingTag)(&opts)
+	opts.addCustomData(pingDataKey, "Sent by Ping to check the Raygun setup, safe to ignore")
+	post := c.createPostWithOptions(errors.New(message), StackTrace{}, opts)
+	return pingError(c.submitCoreWithContext(ctx, post))
+}
+
+// pingError returns the error Ping returns for the given error of the
+// submission.
+func pingError(err error) error {
+	var submitErr *SubmitError
+	switch {
+	case err == nil || !errors.As(err, &submitErr):
+		return err
+	case IsInvalidAPIKey(err):
+		return fmt.Errorf("Raygun rejected the API key (%w)", err)
+	case submitErr.Timeout:
+		return fmt.Errorf("Raygun did not answer in time (%w)", err)
+	case submitErr.StatusCode == 0:
+		return fmt.Errorf("Unable to reach Raygun (%w)", err)
+	}
+	return err
+}
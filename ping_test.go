@@ -0,0 +1,91 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPing(t *testing.T) {
+	Convey("Ping", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("returns nil once Raygun accepted the report", func() {
+			So(c.Ping(context.Background()), ShouldBeNil)
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 1)
+
+			var entry struct {
+				Details struct {
+					Error struct {
+						Message string `json:"message"`
+					} `json:"error"`
+					Tags           []string               `json:"tags"`
+					UserCustomData map[string]interface{} `json:"userCustomData"`
+				} `json:"details"`
+			}
+			So(requests[0].Decode(&entry), ShouldBeNil)
+			So(entry.Details.Error.Message, ShouldStartWith, "raygun4go ping from ")
+			So(entry.Details.Error.Message, ShouldEndWith, "safe to ignore")
+			So(entry.Details.Tags, ShouldContain, "raygun4go-ping")
+			So(entry.Details.UserCustomData, ShouldContainKey, "ping")
+		})
+
+		Convey("reports a rejected API key", func() {
+			server.Script(rayguntest.Unauthorized)
+			err := c.Ping(context.Background())
+			So(errors.Is(err, ErrInvalidAPIKey), ShouldBeTrue)
+			So(err.Error(), ShouldStartWith, "Raygun rejected the API key")
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("reports an unreachable endpoint", func() {
+			unreachable := httptest.NewServer(http.NotFoundHandler())
+			unreachable.Close()
+			c.Endpoint(unreachable.URL).Retries(0, 0)
+			err := c.Ping(context.Background())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "Unable to reach Raygun")
+			So(errors.Is(err, ErrSubmission), ShouldBeTrue)
+		})
+
+		Convey("reports a timeout", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			c.Timeout(50*time.Millisecond).Retries(0, 0)
+			err := c.Ping(context.Background())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "Raygun did not answer in time")
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeTrue)
+		})
+
+		Convey("is posted at once in any mode", func() {
+			c.Silent(true).BufferOnly(true).Asynchronous(true).Batch(10, time.Hour)
+			c.BeforeSend(func(*PostData) bool { return false })
+			So(c.Ping(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+			So(c.DrainPayloads(), ShouldBeEmpty)
+		})
+
+		Convey("is captured by a TestingClient", func() {
+			tc := NewTestClient()
+			So(tc.Ping(context.Background()), ShouldBeNil)
+			So(tc.Reports(), ShouldHaveLength, 1)
+			So(strings.Join(tc.Reports()[0].Details.Tags, ","), ShouldContainSubstring, "raygun4go-ping")
+		})
+	})
+}
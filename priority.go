@@ -0,0 +1,126 @@
+package raygun4go
+
+import "context"
+
+// Priority decides the order reports are delivered in by the asynchronous
+// queue, and which ones it drops first while it is full.
+type Priority int
+
+const (
+	// PriorityLow is for reports that may wait, or be dropped, in favour of
+	// all others.
+	PriorityLow Priority = iota - 1
+
+	// PriorityNormal is the priority of handled errors. It is the default.
+	PriorityNormal
+
+	// PriorityHigh is the priority of recovered panics, e.g. reported by
+	// HandleError.
+	PriorityHigh
+)
+
+// priorityLevels is the number of priorities.
+const priorityLevels = int(PriorityHigh-PriorityLow) + 1
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p.valid() {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// valid returns the priority, or PriorityNormal if it is unknown.
+func (p Priority) valid() Priority {
+	if p < PriorityLow || p > PriorityHigh {
+		return PriorityNormal
+	}
+	return p
+}
+
+// WithPriority sets the priority of a single report in the asynchronous
+// queue. Unknown priorities are treated as PriorityNormal.
+func WithPriority(priority Priority) ReportOption {
+	return func(o *reportOptions) {
+		o.priority = priority.valid()
+	}
+}
+
+// SendErrorWithPriority sends the given error like SendError with the given
+// priority, see WithPriority.
+func (c *Client) SendErrorWithPriority(error error, priority Priority, opts ...ReportOption) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	opts = append(opts[:len(opts):len(opts)], WithPriority(priority))
+	return c.sendError(context.Background(), error, currentStack(), opts)
+}
+
+// reportQueue holds queued reports in order of priority, and of submission
+// within each priority. It is not safe for concurrent use.
+type reportQueue struct {
+	levels [priorityLevels][]queuedReport // the reports of each priority, lowest first
+	n      int                            // the number of reports
+}
+
+// len returns the number of reports.
+func (q *reportQueue) len() int {
+	return q.n
+}
+
+// level returns the index of the given priority in levels.
+func level(p Priority) int {
+	return int(p.valid() - PriorityLow)
+}
+
+// push adds the given report after the reports of the same priority.
+func (q *reportQueue) push(r queuedReport) {
+	i := level(r.post.priority)
+	q.levels[i] = append(q.levels[i], r)
+	q.n++
+}
+
+// pop removes and returns the oldest report of the highest priority.
+func (q *reportQueue) pop() (queuedReport, bool) {
+	for i := priorityLevels - 1; i >= 0; i-- {
+		if len(q.levels[i]) > 0 {
+			return q.take(i), true
+		}
+	}
+	return queuedReport{}, false
+}
+
+// popLowest removes and returns the oldest report of the lowest priority, if
+// it is at most the given one.
+func (q *reportQueue) popLowest(max Priority) (queuedReport, bool) {
+	for i := 0; i <= level(max); i++ {
+		if len(q.levels[i]) > 0 {
+			return q.take(i), true
+		}
+	}
+	return queuedReport{}, false
+}
+
+// holdsLower reports whether a report of a lower priority than the given one is
+// queued.
+func (q *reportQueue) holdsLower(p Priority) bool {
+	for i := 0; i < level(p); i++ {
+		if len(q.levels[i]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// take removes and returns the oldest report of the given level.
+func (q *reportQueue) take(i int) queuedReport {
+	r := q.levels[i][0]
+	q.levels[i][0] = queuedReport{}
+	q.levels[i] = q.levels[i][1:]
+	q.n--
+	return r
+}
@@ -0,0 +1,158 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// prioritized returns a queued report with the given message and priority.
+func prioritized(message string, priority Priority) queuedReport {
+	return queuedReport{post: PostData{Details: DetailsData{Error: ErrorData{Message: message}}, priority: priority}}
+}
+
+// popAll returns the messages of the reports left in the given queue, in the
+// order they are popped.
+func popAll(q *reportQueue) []string {
+	var m []string
+	for {
+		r, ok := q.pop()
+		if !ok {
+			return m
+		}
+		m = append(m, r.post.Details.Error.Message)
+	}
+}
+
+func TestReportQueue(t *testing.T) {
+	Convey("reportQueue", t, func() {
+		var q reportQueue
+		q.push(prioritized("normal 1", PriorityNormal))
+		q.push(prioritized("low 1", PriorityLow))
+		q.push(prioritized("high 1", PriorityHigh))
+		q.push(prioritized("normal 2", PriorityNormal))
+		q.push(prioritized("high 2", PriorityHigh))
+		q.push(prioritized("low 2", PriorityLow))
+
+		Convey("pops the highest priority first, oldest first", func() {
+			So(q.len(), ShouldEqual, 6)
+			So(popAll(&q), ShouldResemble, []string{"high 1", "high 2", "normal 1", "normal 2", "low 1", "low 2"})
+			So(q.len(), ShouldEqual, 0)
+		})
+
+		Convey("drops the oldest report of the lowest priority first", func() {
+			r, ok := q.popLowest(PriorityHigh)
+			So(ok, ShouldBeTrue)
+			So(r.post.Details.Error.Message, ShouldEqual, "low 1")
+			So(q.holdsLower(PriorityNormal), ShouldBeTrue)
+			q.popLowest(PriorityHigh)
+			So(q.holdsLower(PriorityNormal), ShouldBeFalse)
+
+			r, _ = q.popLowest(PriorityNormal)
+			So(r.post.Details.Error.Message, ShouldEqual, "normal 1")
+			So(popAll(&q), ShouldResemble, []string{"high 1", "high 2", "normal 2"})
+		})
+
+		Convey("drops nothing of a higher priority than the given one", func() {
+			var high reportQueue
+			high.push(prioritized("high 1", PriorityHigh))
+			_, ok := high.popLowest(PriorityNormal)
+			So(ok, ShouldBeFalse)
+			So(high.len(), ShouldEqual, 1)
+		})
+
+		Convey("treats unknown priorities as normal", func() {
+			var q reportQueue
+			q.push(prioritized("normal", PriorityNormal))
+			q.push(prioritized("unknown", Priority(42)))
+			q.push(prioritized("high", PriorityHigh))
+			So(popAll(&q), ShouldResemble, []string{"high", "normal", "unknown"})
+		})
+	})
+
+	Convey("Priority", t, func() {
+		So(PriorityLow.String(), ShouldEqual, "low")
+		So(PriorityNormal.String(), ShouldEqual, "normal")
+		So(PriorityHigh.String(), ShouldEqual, "high")
+		So(Priority(42).String(), ShouldEqual, "normal")
+	})
+}
+
+func TestQueuePriority(t *testing.T) {
+	Convey("Queue priority", t, func() {
+		transport := gatedTransport{NewRecordingTransport(), make(chan struct{}, 10), make(chan struct{})}
+		var mu sync.Mutex
+		var dropped []PostData
+		c, _ := New("app", "key")
+		c.Asynchronous(true).Transport(transport).OnDrop(func(post PostData) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped = append(dropped, post)
+		})
+		var releaseOnce sync.Once
+		Reset(func() {
+			releaseOnce.Do(func() { close(transport.release) })
+			c.Close()
+		})
+
+		// fill submits the first report, waits until its delivery started and
+		// then queues the given reports.
+		fill := func(priorities ...Priority) {
+			So(c.SendError(errors.New("report 0")), ShouldBeNil)
+			<-transport.started
+			for i, p := range priorities {
+				So(c.SendErrorWithPriority(fmt.Errorf("report %d", i+1), p), ShouldBeNil)
+			}
+		}
+		deliver := func() []string {
+			releaseOnce.Do(func() { close(transport.release) })
+			So(c.Flush(context.Background()), ShouldBeNil)
+			return messages(transport.Posts())
+		}
+
+		Convey("delivers panics before handled errors", func() {
+			fill(PriorityNormal, PriorityNormal)
+			func() {
+				defer c.HandleError()
+				panic("report 3")
+			}()
+			So(deliver(), ShouldResemble, []string{"report 0", "report 3", "report 1", "report 2"})
+		})
+
+		Convey("delivers reports in order of their explicit priority", func() {
+			fill(PriorityLow, PriorityNormal, PriorityHigh)
+			So(deliver(), ShouldResemble, []string{"report 0", "report 3", "report 2", "report 1"})
+		})
+
+		Convey("drops reports of a lower priority first while full", func() {
+			c.QueueOverflow(DropNewest, 2)
+			fill(PriorityNormal, PriorityLow)
+			So(c.SendErrorWithPriority(errors.New("report 3"), PriorityHigh), ShouldBeNil)
+			So(c.SendErrorWithPriority(errors.New("report 4"), PriorityNormal), ShouldEqual, ErrQueueFull)
+
+			So(deliver(), ShouldResemble, []string{"report 0", "report 3", "report 1"})
+			So(messages(dropped), ShouldResemble, []string{"report 2", "report 4"})
+		})
+
+		Convey("drops the oldest report of the same priority with DropOldest", func() {
+			c.QueueOverflow(DropOldest, 2)
+			fill(PriorityHigh, PriorityNormal)
+			So(c.SendErrorWithPriority(errors.New("report 3"), PriorityNormal), ShouldBeNil)
+			So(c.SendErrorWithPriority(errors.New("report 4"), PriorityLow), ShouldEqual, ErrQueueFull)
+
+			So(deliver(), ShouldResemble, []string{"report 0", "report 1", "report 3"})
+			So(messages(dropped), ShouldResemble, []string{"report 2", "report 4"})
+		})
+
+		Convey("drops the lowest priority first when the queue shrinks", func() {
+			fill(PriorityHigh, PriorityLow, PriorityNormal)
+			c.QueueOverflow(DropNewest, 2)
+			So(messages(dropped), ShouldResemble, []string{"report 2"})
+			So(deliver(), ShouldResemble, []string{"report 0", "report 1", "report 3"})
+		})
+	})
+}
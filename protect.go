@@ -0,0 +1,96 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRecoveredPanic is matched by the errors Protect returns for recovered
+// panics, see errors.Is.
+var ErrRecoveredPanic = errors.New("recovered panic")
+
+// panicError is the error Protect returns for a recovered panic.
+type panicError struct {
+	value interface{} // the value passed to panic
+}
+
+// Error returns the message of the recovered value.
+func (e *panicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrRecoveredPanic.Error(), e.value)
+}
+
+// Is reports whether target is ErrRecoveredPanic.
+func (e *panicError) Is(target error) bool {
+	return target == ErrRecoveredPanic
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *panicError) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+// ShouldReport is a chainable option-setting method to set a function
+// deciding which errors returned by functions run with Protect are reported,
+// e.g. to skip expected errors like context.Canceled. By default, all of them
+// are. Passing nil restores the default.
+func (c *Client) ShouldReport(fn func(error) bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.shouldReport = fn
+	return c
+}
+
+// Protect runs fn and returns its result. A returned error is reported if
+// the ShouldReport function of the client accepts it, and passed through
+// unchanged either way. A panic is recovered and reported like HandleError
+// does; Protect then returns the zero value and an error matching
+// ErrRecoveredPanic, which unwraps to the value passed to panic if that is an
+// error. If Repanic is set, Protect re-panics with the original value
+// instead; HandleError and the middleware further up the stack recover it
+// without reporting it again.
+//
+//	order, err := raygun4go.Protect(raygun, func() (Order, error) {
+//		return placeOrder(ctx, cart)
+//	})
+//
+// Called with a nil *Client, Protect reports nothing, and re-panics with the
+// original value if NilClientRepanics is set.
+func Protect[T any](c *Client, fn func() (T, error)) (result T, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			var zero T
+			result, err = zero, c.recoverProtected(e)
+		}
+	}()
+
+	result, err = fn()
+	if err != nil && c != nil && (c.shouldReport == nil || c.shouldReport(err)) {
+		if sendErr := c.SendError(err); sendErr != nil {
+			c.errorf("Unable to report error returned to Protect (%s)", sendErr.Error())
+		}
+	}
+	return result, err
+}
+
+// recoverProtected reports the given value recovered by Protect and returns
+// the error Protect returns for it, or re-panics as described for Protect.
+func (c *Client) recoverProtected(e interface{}) error {
+	if c == nil {
+		if NilClientRepanics {
+			panic(e)
+		}
+		return &panicError{value: e}
+	}
+
+	c.reportPanic(e, currentStack())
+	if c.repanic {
+		c.reportedPanics.mark(e)
+		panic(e)
+	}
+	if r, ok := e.(*AlreadyReported); ok {
+		e = r.Value
+	}
+	return &panicError{value: e}
+}
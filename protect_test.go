@@ -0,0 +1,122 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type protectedOrder struct {
+	ID    int
+	Total float64
+}
+
+// protectedPanic is a panic value of its own type.
+type protectedPanic struct {
+	code int
+}
+
+func TestProtect(t *testing.T) {
+	Convey("Protect", t, func() {
+		c := NewTestClient()
+
+		Convey("passes the result through", func() {
+			order, err := Protect(c.Client, func() (protectedOrder, error) {
+				return protectedOrder{ID: 7, Total: 9.5}, nil
+			})
+			So(err, ShouldBeNil)
+			So(order, ShouldResemble, protectedOrder{ID: 7, Total: 9.5})
+
+			names, err := Protect(c.Client, func() ([]string, error) {
+				return []string{"a", "b"}, nil
+			})
+			So(err, ShouldBeNil)
+			So(names, ShouldResemble, []string{"a", "b"})
+			So(c.Reports(), ShouldBeEmpty)
+		})
+
+		Convey("reports and passes returned errors through", func() {
+			failure := errors.New("Test Protect")
+			order, err := Protect(c.Client, func() (protectedOrder, error) {
+				return protectedOrder{ID: 7}, failure
+			})
+			So(err, ShouldEqual, failure)
+			So(order.ID, ShouldEqual, 7)
+			So(c.Reports(), ShouldHaveLength, 1)
+			So(c.Reports()[0].Details.Error.Message, ShouldEqual, "Test Protect")
+		})
+
+		Convey("skips errors rejected by ShouldReport", func() {
+			c.ShouldReport(func(err error) bool { return !errors.Is(err, context.Canceled) })
+			_, err := Protect(c.Client, func() (int, error) { return 0, context.Canceled })
+			So(err, ShouldEqual, context.Canceled)
+			So(c.Reports(), ShouldBeEmpty)
+		})
+
+		Convey("recovers and reports panics", func() {
+			failure := errors.New("Test Protect panic")
+			count, err := Protect(c.Client, func() (int, error) { panic(failure) })
+			So(count, ShouldEqual, 0)
+			So(errors.Is(err, ErrRecoveredPanic), ShouldBeTrue)
+			So(errors.Is(err, failure), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "recovered panic: Test Protect panic")
+			So(c.Reports(), ShouldHaveLength, 1)
+
+			order, err := Protect(c.Client, func() (*protectedOrder, error) { panic("boom") })
+			So(order, ShouldBeNil)
+			So(err.Error(), ShouldEqual, "recovered panic: boom")
+			So(c.Reports(), ShouldHaveLength, 2)
+		})
+
+		Convey("re-panics with the original value if Repanic is set", func() {
+			c.Repanic(true)
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				Protect(c.Client, func() (int, error) { panic("boom") })
+			}()
+			So(recovered, ShouldEqual, "boom")
+			So(c.Reports(), ShouldHaveLength, 1)
+		})
+
+		Convey("is not reported again by an outer HandleError", func() {
+			c.Repanic(true)
+			failure := &protectedPanic{code: 42}
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				Protect(c.Client, func() (int, error) { panic(failure) })
+			}()
+			So(recovered, ShouldEqual, failure)
+
+			failure = &protectedPanic{code: 43}
+			func() {
+				defer c.Clone().Repanic(false).HandleError()
+				Protect(c.Client, func() (int, error) { panic(failure) })
+			}()
+			So(c.Reports(), ShouldHaveLength, 2)
+
+			func() {
+				defer c.Clone().Repanic(false).HandleError()
+				panic(failure)
+			}()
+			So(c.Reports(), ShouldHaveLength, 3)
+		})
+
+		Convey("does not allocate on the happy path", func() {
+			fn := func() (protectedOrder, error) { return protectedOrder{ID: 7}, nil }
+			allocs := testing.AllocsPerRun(100, func() {
+				Protect(c.Client, fn)
+			})
+			So(allocs, ShouldEqual, 0)
+		})
+
+		Convey("recovers panics with a nil client", func() {
+			var nilClient *Client
+			_, err := Protect(nilClient, func() (int, error) { panic("boom") })
+			So(errors.Is(err, ErrRecoveredPanic), ShouldBeTrue)
+		})
+	})
+}
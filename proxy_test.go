@@ -0,0 +1,81 @@
+package raygun4go
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProxy(t *testing.T) {
+	Convey("Proxy", t, func() {
+		requests := make(chan *http.Request, 10)
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests <- r
+			if r.Method == http.MethodConnect {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		Reset(proxy.Close)
+		next := func() *http.Request {
+			select {
+			case r := <-requests:
+				return r
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		}
+
+		c, _ := New("app", "key")
+
+		Convey("forwards plain HTTP requests", func() {
+			c.Endpoint("http://collector.example").Proxy(proxy.URL)
+			So(c.Clone().SendError(errors.New("Test Proxy")), ShouldBeNil)
+			r := next()
+			So(r, ShouldNotBeNil)
+			So(r.Method, ShouldEqual, "POST")
+			So(r.RequestURI, ShouldEqual, "http://collector.example/entries")
+			So(r.Header.Get("X-ApiKey"), ShouldEqual, "key")
+		})
+
+		Convey("tunnels HTTPS requests with CONNECT", func() {
+			c.Endpoint("https://api.raygun.com").Proxy(proxy.URL)
+			So(c.SendError(errors.New("Test Proxy")), ShouldNotBeNil)
+			r := next()
+			So(r, ShouldNotBeNil)
+			So(r.Method, ShouldEqual, http.MethodConnect)
+			So(r.Host, ShouldEqual, "api.raygun.com:443")
+		})
+
+		Convey("returns and logs errors of unreachable proxies", func() {
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			Reset(func() { log.SetOutput(os.Stderr) })
+
+			unreachable := httptest.NewServer(nil)
+			unreachable.Close()
+			c.Endpoint("http://collector.example").Proxy("http://user:secret@" + unreachable.Listener.Addr().String()).LogToStdOut(true)
+			err := c.SendError(errors.New("Test Proxy"))
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(logged.String(), ShouldContainSubstring, "Unable to send report through proxy http://user:xxxxx@")
+			So(logged.String(), ShouldNotContainSubstring, "secret")
+		})
+
+		Convey("ignores URLs without host and can be reset", func() {
+			c.Proxy(proxy.URL).Proxy("proxy:3128")
+			So(c.postClient(), ShouldEqual, c.transportClient)
+			So(c.proxyURL.String(), ShouldEqual, proxy.URL)
+			c.Proxy("")
+			So(c.postClient(), ShouldEqual, defaultHTTPClient)
+		})
+	})
+}
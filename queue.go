@@ -0,0 +1,444 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultQueueSize is the number of reports the asynchronous queue holds
+// before further reports are rejected with ErrQueueFull, see QueueOverflow.
+const defaultQueueSize = 1000
+
+// defaultCloseTimeout is the time Close waits for queued reports to be
+// delivered before it gives up on them.
+const defaultCloseTimeout = 5 * time.Second
+
+// ErrQueueFull is returned by Submit in asynchronous mode if the queue cannot
+// take any more reports and its OverflowPolicy is DropNewest.
+var ErrQueueFull = errors.New("raygun4go queue is full")
+
+// ErrClientClosed is returned by Submit and the other methods sending reports
+// once Close has been called.
+var ErrClientClosed = errors.New("raygun4go client is closed")
+
+// queuedReport is a report waiting in the asynchronous queue.
+type queuedReport struct {
+	client   *Client  // the client whose configuration is used for delivery
+	post     PostData // the report itself
+	attempts int      // the number of delivery attempts made so far
+
+	result *submitResult // receives the result of the delivery, nil unless submitted by SubmitAsync
+}
+
+// asyncQueue delivers the reports submitted in asynchronous mode from a
+// single background worker, highest priority first. It is shared by a client
+// and all its clones.
+type asyncQueue struct {
+	ready   chan struct{} // signals the worker that a report was queued
+	ctx     context.Context
+	cancel  context.CancelFunc
+	start   sync.Once
+	stopped chan struct{}
+
+	mu          sync.Mutex
+	closed      bool
+	queued      reportQueue // the reports waiting for the worker
+	size        int         // the number of reports queued reports holds at most
+	undelivered []queuedReport
+	pending     int             // the number of reports queued or being delivered
+	idle        []chan struct{} // closed once no report is pending, see wait
+	policy      OverflowPolicy  // what happens to reports enqueued while the queue is full
+	room        chan struct{}   // closed and replaced whenever the worker takes a report
+}
+
+// newAsyncQueue returns an empty queue. Its worker is started with the first
+// report.
+func newAsyncQueue() *asyncQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &asyncQueue{
+		ready:   make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+		size:    defaultQueueSize,
+		room:    make(chan struct{}),
+	}
+}
+
+// setOverflow sets the size of the queue and what happens to reports
+// enqueued while it is full. The oldest reports of the lowest priority are
+// dropped while more reports than the new size are queued.
+func (q *asyncQueue) setOverflow(policy OverflowPolicy, size int) {
+	q.mu.Lock()
+	q.policy, q.size = policy, size
+	var dropped []queuedReport
+	for q.queued.len() > size {
+		r, _ := q.queued.popLowest(PriorityHigh)
+		dropped = append(dropped, r)
+		q.settle()
+	}
+	q.mu.Unlock()
+	dropReports(ErrQueueFull, dropped...)
+}
+
+// enqueue adds the given report to the queue. While the queue is full, a
+// report of a lower priority is dropped to make room; otherwise, the overflow
+// policy applies: it rejects the report, drops the oldest one of the same
+// priority or waits for room until ctx is done.
+func (q *asyncQueue) enqueue(ctx context.Context, r queuedReport) error {
+	priority := r.post.priority
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return ErrClientClosed
+		}
+
+		q.start.Do(func() { go q.run() })
+
+		if q.queued.len() < q.size {
+			q.push(r)
+			q.mu.Unlock()
+			return nil
+		}
+		if q.queued.holdsLower(priority) || q.policy == DropOldest {
+			if dropped, ok := q.queued.popLowest(priority); ok {
+				q.settle()
+				q.push(r)
+				q.mu.Unlock()
+				dropReports(ErrQueueFull, dropped)
+				return nil
+			}
+		}
+
+		if q.policy != Block {
+			q.mu.Unlock()
+			return ErrQueueFull
+		}
+		room := q.room
+		q.mu.Unlock()
+		select {
+		case <-room:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-q.ctx.Done():
+		}
+	}
+}
+
+// push adds the given report to the queue and wakes the worker. The caller
+// holds q.mu.
+func (q *asyncQueue) push(r queuedReport) {
+	q.queued.push(r)
+	q.pending++
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes and returns the next report to deliver and wakes the
+// submissions waiting for room in the queue.
+func (q *asyncQueue) pop() (queuedReport, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, ok := q.queued.pop()
+	if ok {
+		close(q.room)
+		q.room = make(chan struct{})
+	}
+	return r, ok
+}
+
+// done marks a pending report as delivered or given up on.
+func (q *asyncQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.settle()
+}
+
+// settle marks a pending report as done and wakes the waiters of waitIdle if
+// it was the last one. The caller holds q.mu.
+func (q *asyncQueue) settle() {
+	q.pending--
+	if q.pending == 0 {
+		for _, ch := range q.idle {
+			close(ch)
+		}
+		q.idle = nil
+	}
+}
+
+// counts returns the numbers of queued reports and of reports being
+// delivered.
+func (q *asyncQueue) counts() (pending, inFlight int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queued.len(), q.pending - q.queued.len()
+}
+
+// isClosed reports whether close was called.
+func (q *asyncQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// waitIdle blocks until no report is pending or ctx is done, and returns the
+// error of ctx in the latter case.
+func (q *asyncQueue) waitIdle(ctx context.Context) error {
+	q.mu.Lock()
+	if q.pending == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	q.idle = append(q.idle, idle)
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run delivers queued reports until the queue is stopped.
+func (q *asyncQueue) run() {
+	defer close(q.stopped)
+	for q.ctx.Err() == nil {
+		if r, ok := q.pop(); ok {
+			q.deliver(r)
+			continue
+		}
+		select {
+		case <-q.ready:
+		case <-q.ctx.Done():
+		}
+	}
+}
+
+// deliver submits a single report. While Raygun is rate limiting the client,
+// it waits and submits the report again. Reports that could not be delivered
+// because the queue was stopped are kept for persisting.
+func (q *asyncQueue) deliver(r queuedReport) {
+	defer q.done()
+
+	for q.ctx.Err() == nil {
+		r.attempts++
+		err := r.client.submitTimed(q.ctx, r.post)
+		if err == nil {
+			r.client.recordResult(r.post, nil)
+			r.result.resolve(nil)
+			return
+		}
+		var limited *RateLimitError
+		if errors.As(err, &limited) && !r.client.spilled(err) {
+			q.wait(limited.RetryAfter)
+			continue
+		}
+		if q.ctx.Err() == nil {
+			r.client.recordResult(r.post, err)
+			r.result.resolve(err)
+			if err != ErrClientDisabled {
+				r.client.errorf("Unable to send %s\n%s", r.post.Summary(), err.Error())
+			}
+			return
+		}
+	}
+
+	q.mu.Lock()
+	q.undelivered = append(q.undelivered, r)
+	q.mu.Unlock()
+}
+
+// wait blocks for the given duration or until the queue is stopped.
+func (q *asyncQueue) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-q.ctx.Done():
+	}
+}
+
+// close stops accepting reports, waits up to the given timeout for the queued
+// reports to be delivered and returns all reports that were not.
+func (q *asyncQueue) close(timeout time.Duration) []queuedReport {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	q.waitIdle(ctx)
+	cancel()
+
+	q.cancel()
+	q.start.Do(func() { close(q.stopped) })
+	<-q.stopped
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		r, ok := q.queued.pop()
+		if !ok {
+			break
+		}
+		q.settle()
+		q.undelivered = append(q.undelivered, r)
+	}
+	undelivered := q.undelivered
+	q.undelivered = nil
+	return undelivered
+}
+
+// PersistQueueOnClose is a chainable option-setting method to set a directory
+// that Close writes all undelivered reports of the asynchronous queue to.
+// Use Resume with the same directory to send them later on, e.g. on the next
+// start of the program.
+func (c *Client) PersistQueueOnClose(dir string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.queueDir = dir
+	return c
+}
+
+// Flush delivers the reports buffered in batching mode and waits until all
+// reports in the asynchronous queue were delivered or given up on, or until
+// ctx is done, e.g. before a serverless function is frozen. Reports
+// submitted meanwhile are waited for as well. It returns the error of ctx if
+// it is done first; batched reports not attempted by then stay buffered.
+// Otherwise, it returns an error wrapping the first failed delivery of a
+// batched report, if any. Occurrences suppressed by Deduplicate and not
+// reported yet are sent first. Unlike Close, Flush keeps the client usable.
+func (c *Client) Flush(ctx context.Context) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	c.sendDedupSummaries(ctx, c.dedup.flush())
+
+	var batchErr error
+	if c.batch != nil {
+		var undelivered []queuedReport
+		undelivered, batchErr = c.batch.flush(ctx)
+		if len(undelivered) > 0 {
+			c.batch.requeue(undelivered)
+		}
+	}
+	if err := c.queue.waitIdle(ctx); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return batchErr
+}
+
+// Close stops the asynchronous queue and the batching of the client and all
+// its clones, after sending the occurrences suppressed by Deduplicate that
+// were not reported yet. It waits a few seconds for queued and batched
+// reports to be delivered, then either writes the remaining ones to the
+// directory set by PersistQueueOnClose or OfflineStorage, or drops them. It
+// also stops the delivery of the offline storage. Reports submitted after Close are
+// rejected with ErrClientClosed; HandleError logs the reports of panics
+// recovered after Close instead. Use Flush to wait for the delivery without
+// closing the client.
+func (c *Client) Close() error {
+	if c == nil {
+		return ErrNoClient
+	}
+	return c.closeWithin(c.closeTimeout)
+}
+
+// closeWithin implements Close, waiting up to timeout for queued and batched
+// reports to be delivered.
+func (c *Client) closeWithin(timeout time.Duration) error {
+	if summaries := c.dedup.flush(); len(summaries) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		c.sendDedupSummaries(ctx, summaries)
+		cancel()
+	}
+
+	var undelivered []queuedReport
+	if c.batch != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		undelivered = c.batch.close(ctx)
+		cancel()
+	}
+	undelivered = append(undelivered, c.queue.close(timeout)...)
+	for _, r := range undelivered {
+		r.result.resolve(ErrClientClosed)
+	}
+	if c.offline != nil {
+		defer c.offline.close()
+		if len(undelivered) > 0 && c.queueDir == "" {
+			return c.offline.store(undelivered)
+		}
+	}
+	if len(undelivered) == 0 {
+		return nil
+	}
+
+	if c.queueDir == "" {
+		dropReports(ErrClientClosed, undelivered...)
+		return fmt.Errorf("Dropped %d undelivered reports", len(undelivered))
+	}
+	return persistReports(c.queueDir, undelivered)
+}
+
+// Resume queues all reports persisted to the given directory by Close for
+// asynchronous delivery with this client, keeping their original occurredOn.
+// Successfully queued reports are removed from the directory, reports that
+// fail verification are moved to its "corrupt" subdirectory.
+func (c *Client) Resume(dir string) error {
+	if c == nil {
+		return ErrNoClient
+	}
+
+	files, err := storedReportFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	corrupt := 0
+	defer func() {
+		if corrupt > 0 {
+			c.errorf("Moved %d corrupt reports to %s", corrupt, filepath.Join(dir, corruptReportDir))
+		}
+	}()
+
+	for _, file := range files {
+		stored, err := readStoredReport(file)
+		if errors.Is(err, errCorruptReport) {
+			if err := quarantineReport(file); err != nil {
+				c.errorf("%s", err.Error())
+			}
+			corrupt++
+			continue
+		}
+		if err != nil {
+			c.errorf("%s", err.Error())
+			continue
+		}
+
+		r := queuedReport{client: c, post: stored.Post, attempts: stored.Attempts}
+		if err := c.queue.enqueue(context.Background(), r); err != nil {
+			return err
+		}
+		if err := os.Remove(file); err != nil {
+			c.errorf("%s", err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package raygun4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueue(t *testing.T) {
+	Convey("Queue", t, func() {
+		dir, _ := os.MkdirTemp("", "raygun4go")
+		release := make(chan struct{})
+		received := make(chan PostData, 10)
+		var releaseOnce sync.Once
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defaultEndpoint := raygunEndpoint
+		raygunEndpoint = server.URL
+		Reset(func() {
+			raygunEndpoint = defaultEndpoint
+			releaseOnce.Do(func() { close(release) })
+			server.Close()
+			os.RemoveAll(dir)
+		})
+
+		c, _ := New("app", "key")
+		c.Asynchronous(true).PersistQueueOnClose(dir)
+		c.closeTimeout = 50 * time.Millisecond
+
+		occurredOn := []string{"2024-01-01T00:00:01Z", "2024-01-01T00:00:02Z", "2024-01-01T00:00:03Z"}
+		for _, o := range occurredOn {
+			post := c.createPost(errors.New("Test queue"), StackTrace{})
+			post.OccuredOn = o
+			So(c.Submit(post), ShouldBeNil)
+		}
+
+		Convey("#Close persists undelivered reports", func() {
+			So(c.Close(), ShouldBeNil)
+
+			files, err := storedReportFiles(dir)
+			So(err, ShouldBeNil)
+			So(len(files), ShouldEqual, 3)
+
+			first, _ := readStoredReport(files[0])
+			So(first.Attempts, ShouldEqual, 1)
+			So(first.Post.OccuredOn, ShouldEqual, occurredOn[0])
+			second, _ := readStoredReport(files[1])
+			So(second.Attempts, ShouldEqual, 0)
+			So(second.Post.OccuredOn, ShouldEqual, occurredOn[1])
+
+			Convey("and rejects further reports", func() {
+				So(c.Submit(PostData{}), ShouldEqual, ErrClientClosed)
+			})
+
+			Convey("#Resume delivers them with their original timestamps", func() {
+				recovered := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var post PostData
+					body, _ := io.ReadAll(r.Body)
+					json.Unmarshal(body, &post)
+					w.WriteHeader(http.StatusAccepted)
+					received <- post
+				}))
+				defer recovered.Close()
+				raygunEndpoint = recovered.URL
+
+				resumed, _ := New("app", "key")
+				So(resumed.Resume(dir), ShouldBeNil)
+				defer resumed.Close()
+
+				var delivered []string
+				for range occurredOn {
+					select {
+					case post := <-received:
+						delivered = append(delivered, post.OccuredOn)
+					case <-time.After(5 * time.Second):
+					}
+				}
+				So(delivered, ShouldResemble, occurredOn)
+
+				files, _ := storedReportFiles(dir)
+				So(files, ShouldBeEmpty)
+			})
+		})
+
+		Convey("#Close drops undelivered reports without a directory", func() {
+			c.PersistQueueOnClose("")
+			So(c.Close(), ShouldNotBeNil)
+
+			files, _ := storedReportFiles(dir)
+			So(files, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestFlush(t *testing.T) {
+	Convey("Flush", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Asynchronous(true)
+
+		Convey("waits for the queued reports", func() {
+			for i := 0; i < 5; i++ {
+				So(c.SendError(errors.New("Test flush")), ShouldBeNil)
+			}
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 5)
+
+			Convey("and keeps the client usable", func() {
+				So(c.SendError(errors.New("Test flush")), ShouldBeNil)
+				So(c.Flush(context.Background()), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 6)
+				So(c.Close(), ShouldBeNil)
+			})
+		})
+
+		Convey("returns once ctx is done", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			So(c.SendError(errors.New("Test flush")), ShouldBeNil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			So(c.Flush(ctx), ShouldEqual, context.DeadlineExceeded)
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+
+			c.closeTimeout = 10 * time.Millisecond
+			So(c.Close(), ShouldNotBeNil)
+		})
+
+		Convey("returns without queued reports", func() {
+			So(c.Flush(context.Background()), ShouldBeNil)
+		})
+	})
+}
+
+func TestCloseDrainsQueue(t *testing.T) {
+	Convey("Close", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Asynchronous(true)
+
+		Convey("delivers the reports queued right before", func() {
+			for i := 0; i < 10; i++ {
+				So(c.SendError(errors.New("Test close")), ShouldBeNil)
+			}
+			So(c.Close(), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 10)
+		})
+
+		Convey("rejects reports afterwards", func() {
+			So(c.Close(), ShouldBeNil)
+			So(c.SendError(errors.New("Test close")), ShouldEqual, ErrClientClosed)
+
+			c.Asynchronous(false)
+			So(c.SendError(errors.New("Test close")), ShouldEqual, ErrClientClosed)
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("makes HandleError log panics", func() {
+			So(c.Close(), ShouldBeNil)
+
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			func() {
+				defer c.HandleError()
+				panic("Test close")
+			}()
+			So(logged.String(), ShouldContainSubstring, ErrClientClosed.Error())
+			So(logged.String(), ShouldContainSubstring, "Test close")
+			So(server.Count(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,99 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter is the time submissions are held back after a 429
+// response without a valid Retry-After header.
+const defaultRetryAfter = time.Minute
+
+// minRetryAfter is the shortest time submissions are held back after a 429
+// response, so a Retry-After of 0 or in the past does not make the
+// asynchronous queue hammer the endpoint.
+const minRetryAfter = time.Second
+
+// ErrRateLimited matches the errors of submissions rejected because Raygun
+// is rate limiting the client, see RateLimitError.
+var ErrRateLimited = errors.New("Raygun is rate limiting submissions")
+
+// RateLimitError is returned by synchronous submissions Raygun answered with
+// 429 Too Many Requests, as well as by all submissions made before the time
+// it asked to wait has passed; those are not sent at all. In asynchronous
+// mode, the queue waits and delivers the report afterwards instead.
+// errors.Is(err, ErrRateLimited) reports whether err is a RateLimitError.
+type RateLimitError struct {
+	RetryAfter time.Duration // the time to wait before submitting again
+}
+
+// Error returns the message of the rejected submission.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("Rate limited by Raygun, retry after %s", e.RetryAfter)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// rateLimitWindow is the time until which Raygun asked the client not to
+// submit reports. It is shared by a client and all its clones.
+type rateLimitWindow struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// remaining returns the time left in the window at the given time, 0 if it
+// has passed.
+func (w *rateLimitWindow) remaining(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if d := w.until.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// extend makes the window last at least until the given time.
+func (w *rateLimitWindow) extend(until time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if until.After(w.until) {
+		w.until = until
+	}
+}
+
+// rateLimited opens the rate limit window of the client for the time asked
+// by the Retry-After header of the given 429 response and returns the error
+// of the submission.
+func (c *Client) rateLimited(resp *http.Response) *RateLimitError {
+	now := c.clock()
+	d := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	c.rateLimit.extend(now.Add(d))
+	c.errorf("Rate limited by Raygun for %s", d)
+	return &RateLimitError{RetryAfter: d}
+}
+
+// parseRetryAfter returns the time to wait given by a Retry-After header,
+// either in seconds or as an HTTP date, relative to the given time. It
+// returns defaultRetryAfter if the header is missing or invalid and never
+// less than minRetryAfter.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	d := defaultRetryAfter
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		d = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		d = date.Sub(now)
+	}
+	if d < minRetryAfter {
+		d = minRetryAfter
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRateLimit(t *testing.T) {
+	Convey("Rate limiting", t, func() {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		c.clock = func() time.Time { return now }
+
+		Convey("returns ErrRateLimited with a numeric Retry-After", func() {
+			server.Script(rayguntest.TooManyRequests("120"))
+			err := c.SendError(errors.New("Test RateLimit"))
+			So(errors.Is(err, ErrRateLimited), ShouldBeTrue)
+			var limited *RateLimitError
+			So(errors.As(err, &limited), ShouldBeTrue)
+			So(limited.RetryAfter, ShouldEqual, 2*time.Minute)
+			So(err.Error(), ShouldEqual, "Rate limited by Raygun, retry after 2m0s")
+
+			Convey("and holds back further reports until the window passes", func() {
+				now = now.Add(time.Minute)
+				err := c.Clone().SendError(errors.New("Test RateLimit"))
+				So(errors.As(err, &limited), ShouldBeTrue)
+				So(limited.RetryAfter, ShouldEqual, time.Minute)
+				So(server.Count(), ShouldEqual, 1)
+
+				now = now.Add(time.Minute)
+				So(c.SendError(errors.New("Test RateLimit")), ShouldBeNil)
+				So(server.Count(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("returns ErrRateLimited with an HTTP date Retry-After", func() {
+			server.Script(rayguntest.TooManyRequests(now.Add(90 * time.Second).Format(http.TimeFormat)))
+			err := c.SendError(errors.New("Test RateLimit"))
+			var limited *RateLimitError
+			So(errors.As(err, &limited), ShouldBeTrue)
+			So(limited.RetryAfter, ShouldEqual, 90*time.Second)
+		})
+
+		Convey("is not retried by Retries", func() {
+			c.Retries(3, time.Millisecond)
+			server.Script(rayguntest.TooManyRequests("1"))
+			So(errors.Is(c.SendError(errors.New("Test RateLimit")), ErrRateLimited), ShouldBeTrue)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("delays and retries in asynchronous mode", func() {
+			c.clock = time.Now
+			c.Asynchronous(true)
+			server.Script(rayguntest.TooManyRequests("1"))
+			start := time.Now()
+			So(c.SendError(errors.New("Test RateLimit")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, time.Second)
+			So(server.Count(), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	Convey("parseRetryAfter", t, func() {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		So(parseRetryAfter("30", now), ShouldEqual, 30*time.Second)
+		So(parseRetryAfter(now.Add(time.Hour).Format(http.TimeFormat), now), ShouldEqual, time.Hour)
+		So(parseRetryAfter("", now), ShouldEqual, defaultRetryAfter)
+		So(parseRetryAfter("soon", now), ShouldEqual, defaultRetryAfter)
+		So(parseRetryAfter("0", now), ShouldEqual, minRetryAfter)
+		So(parseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now), ShouldEqual, minRetryAfter)
+	})
+}
@@ -36,17 +36,25 @@
 //
 // Chainable configuration methods are available (see below) to set the
 // affected version, user, tags or custom data.
+//
+// All methods are safe to call on a nil *Client, so integrations can hold an
+// optional client without guarding every call. Sending methods then return
+// ErrNoClient without doing anything else, see HandleError for how panics are
+// treated.
 package raygun4go
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
-	goerrors "github.com/go-errors/errors"
 	"github.com/pborman/uuid"
 )
 
@@ -54,27 +62,166 @@ import (
 // information that is needed if an error occurs.
 type Client struct {
 	appName      string             // the name of the app
-	apiKey       string             // the api key for your raygun app
+	apiKey       *sharedAPIKey      // the api key for your raygun app, shared with all clones
 	context      contextInformation // optional context information
+	contextMu    sync.RWMutex       // guards context
 	silent       bool               // if true, the error is printed instead of sent to Raygun
 	logToStdOut  bool               // if true, the client will print debug messages
-	asynchronous bool               // if true, reports are sent to Raygun from a new go routine
+	asynchronous bool               // if true, reports are queued and sent to Raygun in the background
+	redaction    redactor           // the request data redaction rules
+
+	panicSubmitBudget time.Duration     // the maximum time HandleError waits for a synchronous submission
+	queue             *asyncQueue       // the queue of asynchronously submitted reports, shared with all clones
+	queueDir          string            // the directory Close persists undelivered reports to
+	closeTimeout      time.Duration     // the time Close waits for queued reports to be delivered
+	dedup             *deduplicator     // suppresses repeated reports if set, shared with all clones
+	clock             func() time.Time  // returns the current time, replaced in tests
+	diagnostics       bool              // if true, the connection phases of submissions are recorded
+	hooks             *hookRegistry     // the BeforeSend hooks, shared with all clones
+	response          *responseWriter   // the response of the request handled by Middleware, if its body is captured
+	normalizeTags     bool              // if true, the tags of every report are normalized
+	noRequestData     bool              // if true, reports never contain request data
+	envVars           map[string]string // the environment variables read by IncludeEnvVars
+	dynamicEnvVars    []string          // the environment variables read for every report
+	wireFormat        int               // the shape of the JSON sent to Raygun, see WireFormat
+	breadcrumbs       *breadcrumbTrail  // the most recent breadcrumbs, copied by clones
+	occurrence        *occurrenceClock  // computes occurredOn robust to wall clock steps, shared with all clones
+
+	frameClassifier  FrameClassifierFunc // decides how stack frames are reported, see FrameClassifier
+	incident         *incidentReference  // the incident reference added to reports, shared with all clones
+	strict           bool                // if true, problems building reports are returned, see Strict
+	headerTags       map[string]string   // request header names mapped to tag prefixes
+	headerCustomData map[string]string   // request header names mapped to custom data keys
+
+	serviceName       string // the service reports come from, see ServiceInfo
+	serviceListenAddr string // the address the service listens on, see ServiceInfo
+	memoryOnPanic     bool   // if true, panic reports include a memory snapshot
+
+	splitJoinedErrors     bool // if true, SendError sends joined errors as separate reports
+	maxJoinedErrorReports int  // the maximum number of reports sent for a joined error
+	repanic               bool // if true, HandleError re-panics after reporting
+	httpStatusThreshold   int  // the HTTP status up to which SendError drops errors
+
+	handlerFunc string // the name of the handler function serving the request, set by Middleware
+
+	archive    *archive       // passes a sample of the payloads to a sink, see ArchiveSink
+	bufferOnly bool           // if true, reports are buffered instead of sent, see BufferOnly
+	buffer     *payloadBuffer // the buffered reports, shared with all clones
+
+	newReportID func() string // generates the ids of reports, replaced in tests
+
+	flattenDepth int // the custom data depth beyond which it is flattened, see FlattenCustomDataDepth
+
+	httpClient  *http.Client // the client reports are posted with, see HTTPClient
+	relaySocket string       // the Unix domain socket of a relay reports are posted through, see RelaySocket
+	relayClient *http.Client // the client dialing the relay socket
+
+	endpoint string   // the URL of the Raygun API, see Endpoint
+	logTail  *logTail // the recent log lines attached to reports, see LogTailWriter
+
+	proxyURL        *url.URL     // the proxy reports are posted through, see Proxy
+	transportClient *http.Client // the client using the proxy and the TLS configuration
+
+	submitTimeout  time.Duration // the maximum time a submission request may take, see Timeout
+	maxRetries     int           // the number of times failed submissions are retried, see Retries
+	retryBaseDelay time.Duration // the delay before the first retry
+
+	capture *reportCapture // the reports of a TestClient, which are never sent
+
+	rateLimit *rateLimitWindow // the time Raygun asked not to submit reports before, shared with all clones
+
+	disabled *disabledFlag // set once Raygun rejected the API key, shared with all clones, see Enabled
+
+	circuit *circuitBreaker // suspends submissions after repeated failures, nil if disabled
+
+	transport Transport // delivers reports instead of posting them to Raygun, see Transport
+
+	maxTags int // the maximum number of tags sent with a report, see MaxTags
+
+	batch *reportBatch // the reports waiting for delivery in batching mode, nil if disabled
+
+	apiKeySelector func(PostData) string // chooses the API key of each report, see APIKeySelector
+
+	rawCustomDataValues bool // if true, durations, times and byte slices in custom data are not normalized
+
+	tlsConfig *tls.Config // the TLS configuration of the connection to Raygun, see TLSConfig
+
+	shouldReport func(error) bool // decides which errors returned to Protect are reported
+
+	compression bool // if true, reports are gzipped, see Compression
+
+	extraHeaders http.Header // the additional headers of submissions, see ExtraHeaders
+
+	connections *ConnectionOptions // the tuning of the connections to Raygun, see Connections
+
+	onResponse func(ResponseInfo) // called with the outcome of every request to Raygun, see OnResponse
+
+	maxPayloadSize int // the size of the largest payload sent, see MaxPayloadSize
+
+	offline              *offlineStore // keeps reports on disk during outages, nil if disabled, see OfflineStorage
+	offlineThreshold     int           // the number of network failures taking the client offline, see OfflineDetection
+	offlineProbeInterval time.Duration // the interval Raygun is probed at while offline
+
+	stats  *reportCounters // counts the reports handed over for delivery, shared with all clones
+	onDrop func(PostData)  // called with every discarded report, see OnDrop
+
+	reportedPanics *reportedPanics // the values Protect re-panicked with, shared with all clones
+
+	endpointErr error // the error of the last ignored Endpoint or Region call, reported in strict mode
+
+	deterministic bool // fixes the occurredOn and report ids of silent reports, see DeterministicEncoding
+
+	onSubmissionError func(PostData, error) // called with every report whose delivery failed, see OnSubmissionError
+
+	limiter *rateLimiter // limits the reports sent a minute, nil if disabled, see RateLimit
+
+	dedupKey func(PostData) string // identifies the errors of reports for Deduplicate, see DedupFingerprint
+
+	sampler      *sampler // keeps a fraction of the reports, nil if disabled, see SampleRate
+	samplePanics bool     // if true, sampling applies to recovered panics as well
+
+	metrics MetricsSink // receives the delivery metrics, see Metrics
+
+	drain drainPolicy // the delivery of the offline storage, see DrainPolicy
+
+	logger Logger // receives the diagnostic messages, the standard logger if nil, see Logger
 }
 
 // contextInformation holds optional information on the context the error
 // occured in.
 type contextInformation struct {
 	Request              *http.Request                // the request associated to the error
+	RequestRef           *requestRef                  // the request associated to the error, captured by RequestRef
 	Version              string                       // the version of the package
 	Tags                 []string                     // tags that you would like to use to filter this error
 	CustomData           interface{}                  // whatever you like Raygun to know about this error
 	User                 string                       // the user that saw the error
 	GetCustomGroupingKey func(error, PostData) string // A function that takes the original error and Raygun payload and returns a key for grouping errors together in Raygun.
 	identifier           string                       // a unique identifier for the running process, automatically set by New()
+	requestForm          *requestForm                 // guards the form of Request, shared with clones
 }
 
 // raygunAPIEndpoint  holds the REST - JSON API Endpoint address
-var raygunEndpoint = "https://api.raygun.com"
+var raygunEndpoint = EndpointDefault
+
+// defaultPanicSubmitBudget is the time HandleError waits for a synchronous
+// submission unless configured otherwise.
+const defaultPanicSubmitBudget = 30 * time.Second
+
+// ErrPanicSubmitBudgetExceeded is returned by HandleError if the submission
+// did not complete within the panic submit budget. The submission continues
+// in the background.
+var ErrPanicSubmitBudgetExceeded = errors.New("submission exceeded the panic submit budget and continues in the background")
+
+// ErrNoClient is returned by the sending methods when called on a nil *Client.
+// All exported methods are safe to call on a nil *Client: sending methods
+// return ErrNoClient, option-setting methods return nil.
+var ErrNoClient = errors.New("raygun4go client is nil")
+
+// NilClientRepanics determines whether HandleError re-panics with the
+// recovered value when called on a nil *Client. By default the panic is
+// swallowed, just as it would be if the client reported it.
+var NilClientRepanics = false
 
 // Identifier returns the otherwise private identifier property from the
 // Client's context. It is set by the New()-method and represents a unique
@@ -84,34 +231,192 @@ func (ci *contextInformation) Identifier() string {
 }
 
 // New creates and returns a Client, needing an appName and an apiKey. It also
-// creates a unique identifier for your program.
-func New(appName, apiKey string) (c *Client, err error) {
+// creates a unique identifier for your program. The given options are
+// applied in order; New returns the error of the first one that fails.
+func New(appName, apiKey string, opts ...Option) (c *Client, err error) {
 	context := contextInformation{identifier: uuid.New()}
 	if appName == "" || apiKey == "" {
 		return nil, errors.New("appName and apiKey are required")
 	}
-	c = &Client{appName, apiKey, context, false, false, false}
+	c = &Client{
+		appName:           appName,
+		apiKey:            newSharedAPIKey(apiKey),
+		context:           context,
+		redaction:         newDefaultRedactor(),
+		panicSubmitBudget: defaultPanicSubmitBudget,
+		queue:             newAsyncQueue(),
+		closeTimeout:      defaultCloseTimeout,
+		clock:             time.Now,
+		hooks:             &hookRegistry{},
+		wireFormat:        WireFormatV1,
+		breadcrumbs:       newBreadcrumbTrail(defaultMaxBreadcrumbs),
+		occurrence:        newOccurrenceClock(),
+		incident:          &incidentReference{},
+
+		maxJoinedErrorReports: defaultJoinedErrorReports,
+		httpStatusThreshold:   defaultHTTPStatusThreshold,
+		bufferOnly:            platformBuffersOnly,
+		buffer:                &payloadBuffer{},
+		newReportID:           uuid.New,
+		submitTimeout:         defaultSubmitTimeout,
+		rateLimit:             &rateLimitWindow{},
+		disabled:              &disabledFlag{},
+		maxTags:               defaultMaxTags,
+		maxPayloadSize:        defaultMaxPayloadSize,
+		stats:                 &reportCounters{},
+		reportedPanics:        &reportedPanics{},
+		metrics:               noMetrics{},
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
+// Clone returns a copy of the client sharing its queue, statistics and other
+// state of the delivery, e.g. to set the request or the user of a single
+// request. Its tags, its custom data and its request are copied, so that
+// changes to those of the client do not affect the reports of the clone, see
+// CustomDataCopier.
 func (c *Client) Clone() *Client {
+	if c == nil {
+		return nil
+	}
+	context := c.contextSnapshot()
 	contextInfoClone := contextInformation{
-		Request:              c.context.Request,
-		Version:              c.context.Version,
-		Tags:                 c.context.Tags,
-		CustomData:           c.context.CustomData,
-		User:                 c.context.User,
-		GetCustomGroupingKey: c.context.GetCustomGroupingKey,
-		identifier:           c.context.identifier,
+		Request:              cloneRequest(context.Request, context.requestForm),
+		RequestRef:           context.RequestRef,
+		Version:              context.Version,
+		Tags:                 copyStrings(context.Tags),
+		CustomData:           copyCustomData(context.CustomData),
+		User:                 context.User,
+		GetCustomGroupingKey: context.GetCustomGroupingKey,
+		identifier:           context.identifier,
+		requestForm:          context.requestForm,
 	}
 
 	clientClone := &Client{
-		appName:      c.appName,
-		apiKey:       c.apiKey,
-		context:      contextInfoClone,
-		silent:       c.silent,
-		logToStdOut:  c.logToStdOut,
-		asynchronous: c.asynchronous,
+		appName:           c.appName,
+		apiKey:            c.apiKey,
+		context:           contextInfoClone,
+		silent:            c.silent,
+		logToStdOut:       c.logToStdOut,
+		asynchronous:      c.asynchronous,
+		redaction:         c.redaction.clone(),
+		panicSubmitBudget: c.panicSubmitBudget,
+		queue:             c.queue,
+		queueDir:          c.queueDir,
+		closeTimeout:      c.closeTimeout,
+		dedup:             c.dedup,
+		clock:             c.clock,
+		diagnostics:       c.diagnostics,
+		hooks:             c.hooks,
+		response:          c.response,
+		normalizeTags:     c.normalizeTags,
+		noRequestData:     c.noRequestData,
+		envVars:           c.envVars,
+		dynamicEnvVars:    c.dynamicEnvVars,
+		wireFormat:        c.wireFormat,
+		breadcrumbs:       c.breadcrumbs.clone(),
+		occurrence:        c.occurrence,
+		frameClassifier:   c.frameClassifier,
+		incident:          c.incident,
+		strict:            c.strict,
+		headerTags:        c.headerTags,
+		headerCustomData:  c.headerCustomData,
+		serviceName:       c.serviceName,
+		serviceListenAddr: c.serviceListenAddr,
+		memoryOnPanic:     c.memoryOnPanic,
+
+		splitJoinedErrors:     c.splitJoinedErrors,
+		maxJoinedErrorReports: c.maxJoinedErrorReports,
+		repanic:               c.repanic,
+		httpStatusThreshold:   c.httpStatusThreshold,
+
+		handlerFunc: c.handlerFunc,
+		archive:     c.archive,
+		bufferOnly:  c.bufferOnly,
+		buffer:      c.buffer,
+		newReportID: c.newReportID,
+
+		flattenDepth: c.flattenDepth,
+
+		httpClient:  c.httpClient,
+		relaySocket: c.relaySocket,
+		relayClient: c.relayClient,
+
+		endpoint: c.endpoint,
+		logTail:  c.logTail,
+
+		proxyURL:        c.proxyURL,
+		transportClient: c.transportClient,
+
+		submitTimeout:  c.submitTimeout,
+		maxRetries:     c.maxRetries,
+		retryBaseDelay: c.retryBaseDelay,
+
+		capture: c.capture,
+
+		rateLimit: c.rateLimit,
+
+		disabled: c.disabled,
+
+		circuit: c.circuit,
+
+		transport: c.transport,
+
+		maxTags: c.maxTags,
+
+		batch: c.batch,
+
+		apiKeySelector: c.apiKeySelector,
+
+		rawCustomDataValues: c.rawCustomDataValues,
+
+		tlsConfig: c.tlsConfig,
+
+		shouldReport: c.shouldReport,
+
+		compression: c.compression,
+
+		extraHeaders: c.extraHeaders,
+
+		connections: c.connections,
+
+		onResponse: c.onResponse,
+
+		maxPayloadSize: c.maxPayloadSize,
+
+		offline:              c.offline,
+		offlineThreshold:     c.offlineThreshold,
+		offlineProbeInterval: c.offlineProbeInterval,
+
+		stats:  c.stats,
+		onDrop: c.onDrop,
+
+		reportedPanics: c.reportedPanics,
+
+		endpointErr: c.endpointErr,
+
+		deterministic: c.deterministic,
+
+		onSubmissionError: c.onSubmissionError,
+
+		limiter: c.limiter,
+
+		dedupKey: c.dedupKey,
+
+		sampler:      c.sampler,
+		samplePanics: c.samplePanics,
+
+		metrics: c.metrics,
+
+		drain: c.drain,
+
+		logger: c.logger,
 	}
 	return clientClone
 }
@@ -119,14 +424,21 @@ func (c *Client) Clone() *Client {
 // Silent sets the silent-property on the Client. If true, errors will not be
 // sent to Raygun but printed instead.
 func (c *Client) Silent(s bool) *Client {
+	if c == nil {
+		return nil
+	}
 	c.silent = s
 	return c
 }
 
 // LogToStdOut sets the logToStdOut-property on the Client.  If true, errors will
-// be printed to std out as they are submitted to raygun.  This will also log
-// any errors that occur when submiting to raygun to std out
+// be logged as they are submitted to raygun.  This will also log any errors
+// that occur when submiting to raygun.  Messages go to the standard logger of
+// the log package unless a Logger is set.
 func (c *Client) LogToStdOut(l bool) *Client {
+	if c == nil {
+		return nil
+	}
 	c.logToStdOut = l
 	return c
 }
@@ -134,26 +446,96 @@ func (c *Client) LogToStdOut(l bool) *Client {
 // Sets whether or not this client submits reports to Raygun asynchronously.
 // The default is false.
 func (c *Client) Asynchronous(a bool) *Client {
+	if c == nil {
+		return nil
+	}
 	c.asynchronous = a
 	return c
 }
 
+// PanicSubmitBudget is a chainable option-setting method to limit the time
+// HandleError blocks the panicking goroutine while submitting synchronously.
+// If the budget is exceeded, HandleError returns ErrPanicSubmitBudgetExceeded
+// and the submission continues in the background. A non-positive duration
+// restores the default of 30 seconds.
+func (c *Client) PanicSubmitBudget(d time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	if d <= 0 {
+		d = defaultPanicSubmitBudget
+	}
+	c.panicSubmitBudget = d
+	return c
+}
+
 // Request is a chainable option-setting method to add a request to the context.
 func (c *Client) Request(r *http.Request) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.Request = r
+	c.context.RequestRef = nil
+	c.context.requestForm = newRequestForm(r)
+	c.contextMu.Unlock()
+	return c
+}
+
+// RequestRef is a chainable option-setting method to add a request to the
+// context, splitting the work between now and the time an error occurs.
+//
+// Request keeps the live request and reads everything from it when an error
+// is reported. This costs nothing up front, but the handler may have mutated
+// or finished the request by then. RequestRef instead copies the cheap,
+// immutable parts (host, url, method, remote address, query string and
+// headers) right away, and only defers parsing the form until an error is
+// reported. If the request's context is done by then, the request may have
+// been recycled and the form is left out.
+func (c *Client) RequestRef(r *http.Request) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.Request = nil
+	c.context.RequestRef = newRequestRef(r)
+	c.context.requestForm = nil
+	c.contextMu.Unlock()
+	return c
+}
+
+// DisableRequestData is a chainable option-setting method to leave the request
+// section out of all reports, regardless of any request set with Request,
+// RequestRef or the middleware and of changes made by BeforeSend hooks. Error
+// messages, stack traces and all other details are still sent.
+func (c *Client) DisableRequestData(disable bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.noRequestData = disable
 	return c
 }
 
 // Version is a chainable option-setting method to add a version to the context.
 func (c *Client) Version(v string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.Version = v
+	c.contextMu.Unlock()
 	return c
 }
 
 // Tags is a chainable option-setting method to add tags to the context. You
 // can use tags to filter errors in Raygun.
 func (c *Client) Tags(tags []string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.Tags = tags
+	c.contextMu.Unlock()
 	return c
 }
 
@@ -161,14 +543,24 @@ func (c *Client) Tags(tags []string) *Client {
 // to the context. Note that the given type (or at least parts of it)
 // must implement the Marshaler-interface for this to work.
 func (c *Client) CustomData(data interface{}) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.CustomData = data
+	c.contextMu.Unlock()
 	return c
 }
 
 // User is a chainable option-setting method to add an affected Username to the
 // context.
 func (c *Client) User(u string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.User = u
+	c.contextMu.Unlock()
 	return c
 }
 
@@ -178,7 +570,12 @@ func (c *Client) User(u string) *Client {
 // are grouped in your Raygun account. Returning null will result in Raygun grouping the errors
 // for you. This allows you to pick and choose which errors you want to control the grouping for.
 func (c *Client) CustomGroupingKeyFunction(getCustomGroupingKey func(error, PostData) string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
 	c.context.GetCustomGroupingKey = getCustomGroupingKey
+	c.contextMu.Unlock()
 	return c
 }
 
@@ -189,36 +586,211 @@ func (c *Client) CustomGroupingKeyFunction(getCustomGroupingKey func(error, Post
 // to handle all panics inside the calling function and all calls made from it.
 // Be sure to call this in your main function or (if it is webserver) in your
 // request handler as soon as possible.
+//
+// Recovered writes to nil maps carry no hint on which map was written to, so
+// the location of the top in-app frame is appended to their message and, unless
+// a custom grouping key is set, used to group them in Raygun.
+//
+// If the recovered value is an error carrying the stack trace of where it was
+// created, see StackTraceProvider, that stack trace is reported. The top frames
+// of the recovery site are then added to the custom data as
+// "recoverySiteStack".
+//
+// If Repanic is set, HandleError re-panics after reporting, see Repanic.
+//
+// Called on a nil *Client, HandleError still recovers the panic. It then
+// re-panics with the recovered value if NilClientRepanics is set and returns
+// ErrNoClient otherwise.
 func (c *Client) HandleError() error {
 	e := recover()
 	if e == nil {
 		return nil
 	}
 
+	if c == nil {
+		if NilClientRepanics {
+			panic(e)
+		}
+		return ErrNoClient
+	}
+
+	err := c.reportPanic(e, currentStack())
+	if c.repanic {
+		panic(alreadyReported(e))
+	}
+	return err
+}
+
+// reportPanic reports the given recovered value with the given stack. Values
+// reported already, see Repanic and Protect, are skipped.
+func (c *Client) reportPanic(e interface{}, st StackTrace) error {
+	if _, ok := e.(*AlreadyReported); ok || c.reportedPanics.consume(e) {
+		return nil
+	}
+
 	err, ok := e.(error)
 	if !ok {
-		err = errors.New(e.(string))
+		err = errors.New(fmt.Sprint(e))
+	}
+
+	c.errorf("Recovering from: %s", err.Error())
+	if c.switchedOff() {
+		return nil
 	}
 
-	if c.logToStdOut {
-		log.Println("Recovering from:", err.Error())
+	var opts reportOptions
+	if embedded, ok := embeddedStack(err); ok {
+		opts.addCustomData(recoverySiteStackKey, topFrames(st))
+		st = embedded
 	}
 
-	post := c.createPost(err, currentStack())
-	err = c.Submit(post)
+	err, groupingKey := locateRuntimeError(err, st)
+	var memory memorySnapshot
+	if c.memoryOnPanic {
+		memory = newMemorySnapshot()
+		opts.addCustomData(memoryCustomDataKey, memory)
+	}
+	if memory.oomSuspect() {
+		opts.runtimeTags = append(opts.runtimeTags, oomSuspectTag)
+	}
+	opts.priority = PriorityHigh
+	post := c.createPostWithOptions(err, st, opts)
+	if post.Details.GroupingKey == nil && groupingKey != "" {
+		post.Details.GroupingKey = &groupingKey
+	}
+	if c.sampledOut(post, true) {
+		return nil
+	}
+	err = c.strictResult(post, c.submitWithinBudget(post))
 
-	if c.logToStdOut && err != nil {
-		log.Println(err.Error())
+	// A closed client cannot deliver the report anymore, so it is logged
+	// instead of being lost without a trace.
+	if err != nil && (c.logging() || err == ErrClientClosed) {
+		c.output().Errorf("Unable to send %s\n%s", post.Summary(), err.Error())
 	}
 	return err
 }
 
+// submitWithinBudget submits the given post like Submit, but gives up waiting
+// for a synchronous submission once the panic submit budget is exceeded.
+func (c *Client) submitWithinBudget(post PostData) error {
+	if c.silent || (c.asynchronous && c.batch == nil) || c.bufferOnly {
+		return c.submit(context.Background(), post)
+	}
+	if c.queue.isClosed() {
+		return ErrClientClosed
+	}
+	if ok, err := c.admit(&post, true); !ok {
+		return err
+	}
+	if err := c.throttle(post); err != nil {
+		return err
+	}
+
+	c.stats.submit()
+	done := make(chan error, 1)
+	go func() {
+		err := c.submitTimed(context.Background(), post)
+		c.recordResult(post, err)
+		done <- err
+	}()
+
+	timer := time.NewTimer(c.panicSubmitBudget)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrPanicSubmitBudgetExceeded
+	}
+}
+
 // createPost creates the data structure that will be sent to Raygun.
 func (c *Client) createPost(err error, stack StackTrace) PostData {
-	postData := newPostData(c.context, err, stack)
+	return c.createPostWithOptions(err, stack, reportOptions{})
+}
+
+// createPostWithOptions creates the data structure that will be sent to
+// Raygun, applying the given per-report options.
+func (c *Client) createPostWithOptions(err error, stack StackTrace, opts reportOptions) PostData {
+	scope := c.scopeSnapshot()
+	context := scope.context
+	if c.noRequestData {
+		context.Request, context.RequestRef = nil, nil
+	}
+	postData := newPostData(context, err, stack, c.wireFormat)
+	c.stripRequestData(&postData)
+	postData.Details.Error.StackTrace = classifyFrames(postData.Details.Error.StackTrace, c.frameClassifier)
+	tags := reportTags{context: postData.Details.Tags, report: opts.tags, breadcrumbs: scope.breadcrumbTags}
+	tags.headers = c.mapHeaders(postData.Details.Request, &opts)
+	occurredOn := opts.occurredOn
+	if occurredOn.IsZero() && c.deterministicReports() {
+		occurredOn = testClientTime
+	}
+	if occurredOn.IsZero() {
+		var skew time.Duration
+		occurredOn, skew = c.occurrence.now(c.clock())
+		if skew != 0 {
+			opts.addCustomData(clockSkewKey, skew.String())
+		}
+	}
+	postData.OccuredOn = formatOccurredOnFor(occurredOn, c.wireFormat)
+	postData.reportID = c.newReportID()
+	if c.deterministicReports() {
+		postData.reportID = deterministicReportID(postData)
+	}
+	opts.addCustomData(reportIDKey, postData.reportID)
+	incident := c.incident.get(c.clock())
+	if incident != "" {
+		opts.addCustomData(incidentRefKey, incident)
+	}
+	tags.service = c.addServiceInfo(&opts)
+	if c.handlerFunc != "" {
+		opts.addCustomData(handlerFuncKey, c.handlerFunc)
+	}
+	postData.priority = opts.priority
+	if opts.application != nil {
+		opts.addCustomData(hostAppKey, c.appName)
+		postData.appName, postData.apiKey = opts.application.name, opts.application.apiKey
+	}
+	if lines := c.logTailCustomData(); lines != nil {
+		opts.addCustomData(logTailKey, lines)
+	}
+	if env := c.envCustomData(); env != nil {
+		opts.addCustomData(envCustomDataKey, env)
+	}
+	if c.response != nil {
+		if body := c.response.capturedBody(); body != "" {
+			opts.addCustomData(responseBodyKey, body)
+		}
+	}
+	if incident != "" {
+		tags.runtime = append(tags.runtime, "incident:"+incident)
+	}
+	tags.runtime = append(tags.runtime, addHTTPStatus(&postData, opts)...)
+	tags.runtime = append(tags.runtime, timeoutTags(err)...)
+	tags.runtime = append(tags.runtime, addResourceExhaustion(err, &opts)...)
+	tags.runtime = append(tags.runtime, opts.runtimeTags...)
+	var omittedTags []string
+	postData.Details.Tags, omittedTags = assembleTags(tags, c.normalizeTags, c.maxTags)
+	if omittedTags != nil {
+		opts.addCustomData(omittedTagsKey, omittedTags)
+	}
+	customData := postData.Details.UserCustomData
+	postData.Details.UserCustomData = c.normalizeCustomData(mergeCustomData(customData, opts.customData))
+	var flattened bool
+	postData.Details.UserCustomData, flattened = c.flattenCustomData(postData.Details.UserCustomData)
+	postData.Details.Breadcrumbs = scope.breadcrumbs
+	c.redaction.redactRequest(&postData.Details.Request)
+	postData.redactPatterns = c.redaction.patterns
+	c.collectDegradations(&postData, customData, opts.customData)
+	if flattened {
+		c.degrade(&postData, fmt.Errorf("Custom data deeper than %d levels was flattened", c.flattenDepth))
+	}
 
-	if c.context.GetCustomGroupingKey != nil {
-		customGroupingKey := c.context.GetCustomGroupingKey(err, postData)
+	if context.GetCustomGroupingKey != nil {
+		customGroupingKey := context.GetCustomGroupingKey(err, postData)
 		if customGroupingKey != "" {
 			postData.Details.GroupingKey = &customGroupingKey
 		}
@@ -228,9 +800,25 @@ func (c *Client) createPost(err error, stack StackTrace) PostData {
 }
 
 // Manually send a new error with the given message to Raygun. This will use the current execution stacktrace.
-func (c *Client) CreateError(message string) error {
+//
+// The first frame of the stacktrace is the direct caller of CreateError. Its
+// location is also added to the custom data as "reportedFrom", so it is
+// available to the custom grouping key function as well.
+func (c *Client) CreateError(message string, opts ...ReportOption) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	if c.switchedOff() {
+		return nil
+	}
 	err := errors.New(message)
-	post := c.createPost(err, currentStack())
+	st := currentStack()
+
+	o := newReportOptions(opts)
+	if len(st) > 0 {
+		o.addCustomData("reportedFrom", st[0].location())
+	}
+	post := c.createPostWithOptions(err, st, o)
 
 	return c.Submit(post)
 }
@@ -246,82 +834,299 @@ func (c *Client) CreateError(message string) error {
 //
 //	st := make(raygun4go.StackTrace, 0)
 //	st.AddEntry(42, "main", "example.go", "exampleFunc")
-func (c *Client) CreateErrorWithStackTrace(message string, st StackTrace) error {
+func (c *Client) CreateErrorWithStackTrace(message string, st StackTrace, opts ...ReportOption) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	if c.switchedOff() {
+		return nil
+	}
 	err := errors.New(message)
-	post := c.createPost(err, st)
+	post := c.createPostWithOptions(err, st, newReportOptions(opts))
 
 	return c.Submit(post)
 }
 
 // Manually send the given error to Raygun.
-// If the given error is a "github.com/go-errors/errors".Error, then its stacktrace will be used in the Raygun report.
+// If the given error or an error it wraps carries a stacktrace, e.g. a "github.com/go-errors/errors".Error, then its
+// stacktrace will be used in the Raygun report, see StackTraceProvider.
 // For other errors, the current execution stacktrace is used in the Raygun report.
-func (c *Client) SendError(error error) error {
+// Errors joining several errors can be sent as separate reports, see
+// ReportJoinedErrors.
+func (c *Client) SendError(error error, opts ...ReportOption) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	return c.sendError(context.Background(), error, currentStack(), opts)
+}
+
+// SendErrorWithContext sends the given error like SendError, aborting the
+// submission once ctx is done, see SubmitWithContext.
+func (c *Client) SendErrorWithContext(ctx context.Context, error error, opts ...ReportOption) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	return c.sendError(ctx, error, currentStack(), opts)
+}
+
+// sendError implements SendError and SendErrorWithContext with the stack of
+// their caller.
+func (c *Client) sendError(ctx context.Context, error error, st StackTrace, opts []ReportOption) error {
+	if c.switchedOff() {
+		return nil
+	}
 	err := errors.New(error.Error())
+	o := newReportOptions(opts)
+	if c.dropHTTPError(error, &o) {
+		return nil
+	}
 
-	var st StackTrace = nil
-	if goerror, ok := error.(*goerrors.Error); ok {
-		st = make(StackTrace, 0)
-		LoadGoErrorStack(goerror.StackFrames(), &st)
-	} else {
-		st = currentStack()
+	if c.splitJoinedErrors {
+		if leaves := joinedErrors(error); leaves != nil {
+			return c.sendJoinedErrors(ctx, leaves, st, o)
+		}
 	}
 
-	post := c.createPost(err, st)
+	post := c.createPostWithOptions(err, stackOf(error, st), o)
 
-	return c.Submit(post)
+	return c.SubmitWithContext(ctx, post)
 }
 
 // Submit takes care of actually sending the error to Raygun unless the silent
-// option is set.
+// option is set. In asynchronous mode, the post is added to a queue that is
+// delivered in the background, see Close. See DeterministicEncoding for
+// comparing the JSON printed in silent mode against golden files.
 func (c *Client) Submit(post PostData) error {
-	if c.silent {
-		enc, _ := json.MarshalIndent(post, "", "\t")
-		fmt.Println(string(enc))
+	return c.SubmitWithContext(context.Background(), post)
+}
+
+// SubmitWithContext submits the given post like Submit, aborting a
+// synchronous submission once ctx is done. In asynchronous mode, ctx only
+// bounds adding the post to the queue.
+func (c *Client) SubmitWithContext(ctx context.Context, post PostData) (err error) {
+	if c == nil {
+		return ErrNoClient
+	}
+	defer c.recoverSubmission(post, &err)
+	if c.switchedOff() || c.sampledOut(post, false) {
 		return nil
 	}
+	return c.strictResult(post, c.submit(ctx, post))
+}
 
-	if c.asynchronous {
-		go c.submitCore(post)
-		return nil
+// submit implements SubmitWithContext.
+func (c *Client) submit(ctx context.Context, post PostData) error {
+	if ok, err := c.admit(&post, true); !ok {
+		return err
+	}
+	if err := c.throttle(post); err != nil {
+		return err
+	}
+	return c.dispatch(ctx, post, true)
+}
+
+// dispatch hands the given admitted post to the capture of a TestingClient,
+// prints it in silent mode or buffers it if configured, and otherwise
+// submits it. If deferrable is set, it is batched or queued for delivery in
+// the background as configured.
+func (c *Client) dispatch(ctx context.Context, post PostData, deferrable bool) error {
+	_, err := c.dispatchReport(ctx, queuedReport{client: c, post: post}, deferrable)
+	return err
+}
+
+// dispatchReport implements dispatch for the given report, and reports
+// whether it was batched or queued, in which case its result is resolved
+// once it is delivered or dropped.
+func (c *Client) dispatchReport(ctx context.Context, r queuedReport, deferrable bool) (bool, error) {
+	if c.capture != nil {
+		c.capture.record(r.post)
+		return false, nil
+	}
+	if c.silent {
+		return false, consoleTransport{}.Send(ctx, r.post)
+	}
+
+	if c.bufferOnly {
+		return false, c.bufferPost(r.post)
 	}
+	if c.queue.isClosed() {
+		return false, ErrClientClosed
+	}
+	c.stats.submit()
+
+	if deferrable && (c.batch != nil || c.asynchronous) {
+		var err error
+		if c.batch != nil {
+			err = c.batch.add(r)
+		} else {
+			err = c.queue.enqueue(ctx, r)
+		}
+		if err != nil {
+			dropReports(err, r)
+			return false, err
+		}
+		return true, nil
+	}
+
+	err := c.submitTimed(ctx, r.post)
+	c.recordResult(r.post, err)
+	return false, err
+}
+
+// admit applies the BeforeSend hooks and, if deduplicate is set,
+// deduplication to the given post and reports whether it should be
+// submitted. If not, the returned error is the one to hand to the caller.
+func (c *Client) admit(post *PostData, deduplicate bool) (bool, error) {
+	if !c.hooks.run(post) {
+		return false, ErrReportCancelled
+	}
+	c.stripRequestData(post)
+	if deduplicate && c.dedup != nil {
+		ok, summaries := c.dedup.admit(post)
+		c.sendDedupSummaries(context.Background(), summaries)
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-	return c.submitCore(post)
+// stripRequestData removes the request section from the given post if request
+// data collection is disabled.
+func (c *Client) stripRequestData(post *PostData) {
+	if c.noRequestData {
+		post.Details.Request = RequestData{}
+		post.Details.omitRequest = true
+	}
 }
 
-func (c *Client) submitCore(post PostData) error {
+// encodePost returns the JSON payload of the given post, passing it to the
+// archive sink if it belongs to the sample.
+func (c *Client) encodePost(post PostData) ([]byte, error) {
+	if post.raw != nil {
+		c.offerToArchive(post, post.raw)
+		return post.raw, nil
+	}
+	c.stripRequestData(&post)
 	json, err := json.Marshal(post)
 	if err != nil {
-		errMsg := fmt.Sprintf("Unable to convert to JSON (%s): %#v", err.Error(), post)
-		return errors.New(errMsg)
+		return nil, newKindError(ErrMarshalFailure, err, "Unable to convert to JSON (%s): %#v", err.Error(), post)
 	}
+	if json, err = c.fitPayload(post, json); err != nil {
+		return nil, err
+	}
+	c.offerToArchive(post, json)
+	return json, nil
+}
 
-	r, err := http.NewRequest("POST", raygunEndpoint+"/entries", bytes.NewBuffer(json))
+// submitCoreWithContext delivers the given post with the transport of the
+// client, aborting the delivery once ctx is done or the client Timeout has
+// passed. A panic is recovered and returned as an error, see
+// recoverSubmission.
+func (c *Client) submitCoreWithContext(ctx context.Context, post PostData) (err error) {
+	defer c.recoverSubmission(post, &err)
+	if c.capture != nil {
+		c.capture.record(post)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.submitTimeout)
+	defer cancel()
+	return c.deliveryTransport().Send(ctx, post)
+}
+
+// postReport posts the given post to Raygun, aborting the request once ctx
+// is done. A panic is recovered and returned as an error, see
+// recoverSubmission.
+func (c *Client) postReport(ctx context.Context, post PostData) (err error) {
+	defer c.recoverSubmission(post, &err)
+	if c.disabled.get() {
+		return ErrClientDisabled
+	}
+
+	if d := c.rateLimit.remaining(c.clock()); d > 0 {
+		return &RateLimitError{RetryAfter: d}
+	}
+
+	apiKey, err := c.reportAPIKey(post)
+	if err != nil {
+		return err
+	}
+	json, err := c.encodePost(post)
 	if err != nil {
-		errMsg := fmt.Sprintf("Unable to create request (%s)", err.Error())
-		return errors.New(errMsg)
+		return err
+	}
+
+	body, gzipped := c.compress(json)
+	if !c.circuit.allow(c.clock()) {
+		return c.circuitOpen(post)
+	}
+	err = c.postWithRetries(ctx, payload{
+		body:     body,
+		gzipped:  gzipped,
+		apiKey:   apiKey,
+		reportID: post.ReportID(),
+	})
+	if c.circuit.record(err, c.clock()) {
+		c.errorf("Suspending submissions to Raygun for %s after %d failures", c.circuit.cooldown, c.circuit.threshold)
 	}
-	r.Header.Add("X-ApiKey", c.apiKey)
-	httpClient := http.Client{}
-	resp, err := httpClient.Do(r)
+	c.disableOnInvalidKey(err, apiKey)
+	return err
+}
 
+// payload is an encoded report ready to be posted.
+type payload struct {
+	body     []byte // the JSON of the report, gzipped if gzipped is set
+	gzipped  bool   // if true, the body is sent with Content-Encoding gzip
+	apiKey   string // the API key the report is sent with
+	reportID string // the id of the report, sent as idempotency key
+}
+
+// postPayload posts the given payload to Raygun once.
+func (c *Client) postPayload(ctx context.Context, p payload) error {
+	r, err := http.NewRequestWithContext(ctx, "POST", c.entriesURL(), bytes.NewBuffer(p.body))
 	if err != nil {
-		errMsg := fmt.Sprintf("Failed to request (%s)", err.Error())
-		return errors.New(errMsg)
+		return newKindError(ErrRequestBuild, err, "Unable to create request (%s)", err.Error())
 	}
+	c.setHeaders(r, p)
 
-	defer resp.Body.Close()
-	if resp.StatusCode == 202 {
-		if c.logToStdOut {
-			log.Println("Successfully sent message to Raygun")
-		}
-		return nil
+	var tracer *phaseTracer
+	if c.diagnostics || c.logging() {
+		tracer = newPhaseTracer()
+		r = r.WithContext(tracer.withTrace(ctx))
 	}
 
-	errMsg := fmt.Sprintf("Unexpected answer from Raygun %d", resp.StatusCode)
+	sent := time.Now()
+	resp, err := c.postClient().Do(r)
+
 	if err != nil {
-		errMsg = fmt.Sprintf("%s: %s", errMsg, err.Error())
+		submitErr := c.newSubmitError(err)
+		c.notifyResponse(p, sent, nil, submitErr)
+		c.logProxyFailure(err)
+		if tracer != nil {
+			submitErr.Phases = tracer.result()
+			c.debugf("Submission phases: %s", submitErr.Phases.String())
+		}
+		return submitErr
 	}
 
-	return errors.New(errMsg)
+	defer closeResponse(resp)
+	err = c.answerError(resp)
+	c.notifyResponse(p, sent, resp, err)
+	if err == nil {
+		c.debugf("Successfully sent message to Raygun")
+	}
+	return err
+}
+
+// answerError returns the error of a submission Raygun answered with the
+// given response, or nil if it accepted the report.
+func (c *Client) answerError(resp *http.Response) error {
+	if resp.StatusCode == 202 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return c.rateLimited(resp)
+	}
+	return &SubmitError{StatusCode: resp.StatusCode, Body: readErrorBody(resp)}
 }
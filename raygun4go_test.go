@@ -1,11 +1,18 @@
 package raygun4go
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
 	"github.com/pborman/uuid"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -24,11 +31,77 @@ var apiKey = "key"
 //     be printed to the console for local validation.
 var integrationTest = false
 
+// recordingServer is a fake Raygun accepting every valid report and
+// recording it.
+type recordingServer struct {
+	*rayguntest.Server
+	posts    chan PostData
+	endpoint string
+}
+
+// newRecordingServer starts a recordingServer and points raygunEndpoint at it
+// until it is closed.
+func newRecordingServer() *recordingServer {
+	s := &recordingServer{Server: rayguntest.New(), posts: make(chan PostData, 100), endpoint: raygunEndpoint}
+	s.OnRequest(func(r rayguntest.Request) {
+		var post PostData
+		r.Decode(&post)
+		s.posts <- post
+	})
+	raygunEndpoint = s.URL
+	return s
+}
+
+// Close shuts the server down and restores raygunEndpoint.
+func (s *recordingServer) Close() {
+	raygunEndpoint = s.endpoint
+	s.Server.Close()
+}
+
+// next returns the next recorded report, waiting up to a few seconds for it.
+func (s *recordingServer) next() (PostData, bool) {
+	select {
+	case post := <-s.posts:
+		return post, true
+	case <-time.After(5 * time.Second):
+		return PostData{}, false
+	}
+}
+
+// withoutReportID returns the custom data of the given post without its
+// report id, or nil if there is nothing else.
+func withoutReportID(post PostData) interface{} {
+	data, ok := post.Details.UserCustomData.(map[string]interface{})
+	if !ok {
+		return post.Details.UserCustomData
+	}
+	rest := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != reportIDKey {
+			rest[k] = v
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	return rest
+}
+
+//go:noinline
+func createErrorFromHelperA(c *Client) error {
+	return c.CreateError("Test CreateError from helper A")
+}
+
+//go:noinline
+func createErrorFromHelperB(c *Client) error {
+	return c.CreateError("Test CreateError from helper B")
+}
+
 func TestClient(t *testing.T) {
 	Convey("Client", t, func() {
 		c, _ := New("app", apiKey)
 		So(c.appName, ShouldEqual, "app")
-		So(c.apiKey, ShouldEqual, apiKey)
+		So(c.apiKey.get(), ShouldEqual, apiKey)
 		So(c.context.Request, ShouldBeNil)
 		So(c.context.Identifier(), ShouldHaveSameTypeAs, uuid.New())
 
@@ -66,7 +139,7 @@ func TestClient(t *testing.T) {
 			clone := c.Clone()
 
 			So(clone.appName, ShouldResemble, c.appName)
-			So(clone.apiKey, ShouldResemble, c.apiKey)
+			So(clone.apiKey, ShouldEqual, c.apiKey)
 			So(clone.silent, ShouldResemble, c.silent)
 			So(clone.logToStdOut, ShouldResemble, c.logToStdOut)
 			So(clone.asynchronous, ShouldResemble, c.asynchronous)
@@ -155,6 +228,61 @@ func TestClient(t *testing.T) {
 			So(c.asynchronous, ShouldBeTrue)
 		})
 
+		Convey("#DisableRequestData", func() {
+			server := rayguntest.New()
+			Reset(server.Close)
+			c.Endpoint(server.URL)
+
+			r := httptest.NewRequest("POST", "http://www.example.com/path?foo=bar", strings.NewReader("a=b"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			c.Request(r).DisableRequestData(true)
+			c.BeforeSend(func(post *PostData) bool {
+				post.Details.Request.URL = "http://www.example.com/from-hook"
+				return true
+			})
+
+			So(c.SendError(errors.New("Test DisableRequestData")), ShouldBeNil)
+			var body map[string]interface{}
+			So(server.Requests()[0].Decode(&body), ShouldBeNil)
+			details := body["details"].(map[string]interface{})
+			So(details, ShouldNotContainKey, "request")
+			So(details["error"].(map[string]interface{})["message"], ShouldEqual, "Test DisableRequestData")
+
+			Convey("also for requests set by RequestRef", func() {
+				c.RequestRef(r)
+				post := c.createPost(errors.New("Test DisableRequestData"), StackTrace{})
+				So(post.Details.Request, ShouldResemble, RequestData{})
+
+				data, _ := json.Marshal(post)
+				So(string(data), ShouldNotContainSubstring, `"request"`)
+			})
+		})
+
+		Convey("#PanicSubmitBudget", func() {
+			So(c.panicSubmitBudget, ShouldEqual, defaultPanicSubmitBudget)
+			c.PanicSubmitBudget(time.Second)
+			So(c.panicSubmitBudget, ShouldEqual, time.Second)
+			c.PanicSubmitBudget(0)
+			So(c.panicSubmitBudget, ShouldEqual, defaultPanicSubmitBudget)
+
+			Convey("limits the time HandleError blocks on a stalled server", func() {
+				server := rayguntest.New()
+				Reset(server.Close)
+				server.Script(rayguntest.Slow(time.Second, rayguntest.Accepted))
+				c.Endpoint(server.URL)
+
+				c.PanicSubmitBudget(50 * time.Millisecond)
+				start := time.Now()
+				func() {
+					defer c.HandleError()
+					panic("Test stalled server")
+				}()
+				So(time.Since(start), ShouldBeLessThan, 500*time.Millisecond)
+
+				So(eventually(func() bool { return c.Stats().Succeeded == 1 }), ShouldBeTrue)
+			})
+		})
+
 		Convey("#HandleError", func() {
 			u := "http://www.example.com?foo=bar&fizz[]=buzz&fizz[]=buzz2"
 			r, _ := http.NewRequest("GET", u, nil)
@@ -187,6 +315,30 @@ func TestClient(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 
+		Convey("#CreateError reports its caller", func() {
+			var posts []PostData
+			c.Silent(true)
+			c.CustomData(map[string]string{"foo": "bar"})
+			c.CustomGroupingKeyFunction(func(err error, post PostData) string {
+				posts = append(posts, post)
+				return ""
+			})
+
+			So(createErrorFromHelperA(c), ShouldBeNil)
+			So(createErrorFromHelperB(c), ShouldBeNil)
+			So(len(posts), ShouldEqual, 2)
+
+			first := posts[0].Details.UserCustomData.(map[string]interface{})
+			second := posts[1].Details.UserCustomData.(map[string]interface{})
+			So(first["foo"], ShouldEqual, "bar")
+			So(first["reportedFrom"], ShouldStartWith, "github.com/MindscapeHQ/raygun4go.createErrorFromHelperA (raygun4go_test.go:")
+			So(second["reportedFrom"], ShouldStartWith, "github.com/MindscapeHQ/raygun4go.createErrorFromHelperB (raygun4go_test.go:")
+			So(first["reportedFrom"], ShouldNotEqual, second["reportedFrom"])
+
+			So(posts[0].Details.Error.StackTrace[0].MethodName, ShouldStartWith, "createErrorFromHelperA(")
+			So(posts[1].Details.Error.StackTrace[0].MethodName, ShouldStartWith, "createErrorFromHelperB(")
+		})
+
 		Convey("#CreateErrorWithStackTrace", func() {
 			c.Silent(!integrationTest)
 			c.context.Version = "goconvey"
@@ -213,7 +365,7 @@ func TestClient(t *testing.T) {
 			fmt.Println("   - URL: http://www.example.com?foo=bar&fizz[]=buzz&fizz[]=buzz2")
 			fmt.Println("   - Remote Address: 1.2.3.4")
 			fmt.Println("   - Post Form: foo=bar, fizz=buzz, fizz=buzz2")
-			fmt.Println("   - Headers: Cookie=cookie1=value1; cookie2=value2")
+			fmt.Println("   - Headers: Cookie=[REDACTED]")
 			fmt.Println("   - Custom Grouping Key: customGroupingKey")
 			fmt.Println("   - Version: goconvey")
 			fmt.Println("   - Tags: golang, test")
@@ -236,3 +388,218 @@ func TestClient(t *testing.T) {
 		})
 	})
 }
+
+func TestNilClient(t *testing.T) {
+	Convey("nil Client", t, func() {
+		var c *Client
+
+		Convey("option-setting methods return nil", func() {
+			So(c.Clone(), ShouldBeNil)
+			So(c.Silent(true), ShouldBeNil)
+			So(c.LogToStdOut(true), ShouldBeNil)
+			So(c.Asynchronous(true), ShouldBeNil)
+			So(c.PanicSubmitBudget(time.Second), ShouldBeNil)
+			So(c.Request(&http.Request{}), ShouldBeNil)
+			So(c.Version("1.0.0"), ShouldBeNil)
+			So(c.Tags([]string{"foo"}), ShouldBeNil)
+			So(c.CustomData("foo"), ShouldBeNil)
+			So(c.User("user"), ShouldBeNil)
+			So(c.CustomGroupingKeyFunction(func(error, PostData) string { return "" }), ShouldBeNil)
+			So(c.RedactHeaders("X-Internal"), ShouldBeNil)
+			So(c.RedactFields("pin"), ShouldBeNil)
+			So(c.RedactPatterns(`\d+`), ShouldBeNil)
+			So(c.ClearRedaction(), ShouldBeNil)
+			So(c.EffectiveRedactionConfig(), ShouldResemble, RedactionConfig{})
+			So(c.PersistQueueOnClose("dir"), ShouldBeNil)
+			So(c.RequestRef(&http.Request{}), ShouldBeNil)
+			So(c.Diagnostics(true), ShouldBeNil)
+			So(c.ClearBeforeSendHooks(), ShouldBeNil)
+			So(c.BeforeSend(func(*PostData) bool { return true }), ShouldNotBeNil)
+			So(c.TypedTags(NewTag("foo")), ShouldBeNil)
+			So(c.NormalizeTags(true), ShouldBeNil)
+			So(c.DisableRequestData(true), ShouldBeNil)
+			So(c.IncludeEnvVars("HOME"), ShouldBeNil)
+			So(c.IncludeDynamicEnvVars("HOME"), ShouldBeNil)
+			So(c.WireFormat(WireFormatV2), ShouldBeNil)
+			So(c.RecordBreadcrumb(Breadcrumb{}), ShouldBeNil)
+			So(c.MaxBreadcrumbs(1), ShouldBeNil)
+			So(c.ClearBreadcrumbs(), ShouldBeNil)
+			So(c.FrameClassifier(nil), ShouldBeNil)
+			So(c.SetIncidentReference("INC-1"), ShouldBeNil)
+			So(c.SetIncidentReferenceFor("INC-1", time.Hour), ShouldBeNil)
+			So(c.ClearIncidentReference(), ShouldBeNil)
+			So(c.Strict(true), ShouldBeNil)
+			So(c.HeaderTagMapping(map[string]string{"X-Tenant": "tenant"}), ShouldBeNil)
+			So(c.HeaderCustomDataMapping(map[string]string{"X-Request-Id": "requestId"}), ShouldBeNil)
+			So(c.ServiceInfo("billing", ":8080"), ShouldBeNil)
+			So(c.IncludeMemoryOnPanic(true), ShouldBeNil)
+			So(c.ReportJoinedErrors(true), ShouldBeNil)
+			So(c.MaxJoinedErrorReports(3), ShouldBeNil)
+			So(c.Repanic(true), ShouldBeNil)
+			So(c.ClearRequest(), ShouldBeNil)
+			So(c.ClearCustomData(), ShouldBeNil)
+			So(c.ClearTags(), ShouldBeNil)
+			So(c.ClearUser(), ShouldBeNil)
+			So(c.ResetContext(), ShouldBeNil)
+			So(c.ReportHTTPErrorsAbove(399), ShouldBeNil)
+			So(c.ArchiveSink(SinkFunc(func([]byte) error { return nil }), 0.01), ShouldBeNil)
+			So(c.BufferOnly(true), ShouldBeNil)
+			So(c.FlattenCustomDataDepth(3), ShouldBeNil)
+			So(c.HTTPClient(&http.Client{}), ShouldBeNil)
+			So(c.RelaySocket("/var/run/raygun-relay.sock"), ShouldBeNil)
+			So(c.Endpoint("https://api.raygun.com"), ShouldBeNil)
+			So(c.LogTailWriter(10), ShouldNotBeNil)
+			So(c.Proxy("http://proxy:3128"), ShouldBeNil)
+			So(c.AddTags("a"), ShouldBeNil)
+			So(c.Timeout(time.Second), ShouldBeNil)
+			So(c.Retries(3, time.Second), ShouldBeNil)
+			So(c.APIKeySelector(nil), ShouldBeNil)
+			So(c.RawCustomDataValues(true), ShouldBeNil)
+			So(c.TLSConfig(nil), ShouldBeNil)
+			So(c.ShouldReport(nil), ShouldBeNil)
+			So(c.Compression(true), ShouldBeNil)
+			So(c.ExtraHeaders(nil), ShouldBeNil)
+			So(c.HeaderPolicy(PolicyStrictPII), ShouldBeNil)
+			So(c.AllowHeaders("Accept"), ShouldBeNil)
+			So(c.Enabled(true), ShouldBeNil)
+			So(c.CircuitBreaker(3, time.Minute), ShouldBeNil)
+			So(c.MaxTags(10), ShouldBeNil)
+			So(c.Transport(nil), ShouldBeNil)
+			So(c.Batch(10, time.Second), ShouldBeNil)
+			So(c.Region("eu"), ShouldBeNil)
+			So(c.Connections(ConnectionOptions{}), ShouldBeNil)
+			So(c.OnResponse(nil), ShouldBeNil)
+			So(c.MaxPayloadSize(0), ShouldBeNil)
+			So(c.OfflineStorage("", 0), ShouldBeNil)
+			So(c.QueueOverflow(Block, 10), ShouldBeNil)
+			So(c.OnDrop(nil), ShouldBeNil)
+			So(c.SetCustomDataKey("a", 1), ShouldBeNil)
+			So(c.DrainPayloads(), ShouldBeNil)
+			_, err := c.SubmitWithResult(PostData{})
+			So(err, ShouldEqual, ErrNoClient)
+			So(c.InstrumentHTTPClient(http.DefaultTransport), ShouldEqual, http.DefaultTransport)
+		})
+
+		Convey("#Middleware returns the handler unchanged", func() {
+			handler := http.NewServeMux()
+			So(c.Middleware(handler), ShouldEqual, handler)
+		})
+
+		Convey("sending methods return ErrNoClient", func() {
+			So(c.CreateError("foo"), ShouldEqual, ErrNoClient)
+			So(c.CreateErrorWithStackTrace("foo", StackTrace{}), ShouldEqual, ErrNoClient)
+			So(c.SendError(errors.New("foo")), ShouldEqual, ErrNoClient)
+			So(c.Submit(PostData{}), ShouldEqual, ErrNoClient)
+			So(c.SendErrorWithContext(context.Background(), errors.New("foo")), ShouldEqual, ErrNoClient)
+			So(c.SubmitWithContext(context.Background(), PostData{}), ShouldEqual, ErrNoClient)
+			_, err := c.SendTestReport(context.Background())
+			So(err, ShouldEqual, ErrNoClient)
+			So(c.Close(), ShouldEqual, ErrNoClient)
+			So(c.Flush(context.Background()), ShouldEqual, ErrNoClient)
+			So(c.SetAPIKey("key"), ShouldEqual, ErrNoClient)
+			So(c.SetEndpoint("https://api.raygun.com"), ShouldEqual, ErrNoClient)
+			So(c.SetRegion("eu"), ShouldEqual, ErrNoClient)
+			So(c.SubmitBytes([]byte("{}")), ShouldEqual, ErrNoClient)
+			So(c.Ping(context.Background()), ShouldEqual, ErrNoClient)
+			So(c.Stats(), ShouldResemble, Stats{})
+			So(c.Resume("dir"), ShouldEqual, ErrNoClient)
+			So(c.ImportReports(context.Background(), []PostData{{}}).Items[0].Err, ShouldEqual, ErrNoClient)
+			So(NewAggregator(c, time.Minute).Add(errors.New("foo")), ShouldEqual, ErrNoClient)
+
+			data, err := c.Export()
+			So(data, ShouldBeNil)
+			So(err, ShouldEqual, ErrNoClient)
+			scope, err := c.ImportScope([]byte("{}"))
+			So(scope, ShouldBeNil)
+			So(err, ShouldEqual, ErrNoClient)
+		})
+
+		Convey("#HandleError", func() {
+			So(c.HandleError(), ShouldBeNil)
+
+			Convey("#Go still runs the function", func() {
+				done := make(chan struct{})
+				c.Go(func() { close(done) })
+				<-done
+			})
+
+			Convey("swallows the panic by default", func() {
+				So(func() {
+					defer c.HandleError()
+					panic("Test nil client")
+				}, ShouldNotPanic)
+			})
+
+			Convey("re-panics if configured", func() {
+				NilClientRepanics = true
+				Reset(func() { NilClientRepanics = false })
+
+				So(func() {
+					defer c.HandleError()
+					panic("Test nil client")
+				}, ShouldPanicWith, "Test nil client")
+			})
+		})
+	})
+}
+
+func TestSubmitWithContext(t *testing.T) {
+	Convey("SubmitWithContext", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("aborts the request once the context is canceled", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			start := time.Now()
+			err := c.SendErrorWithContext(ctx, errors.New("Test SubmitWithContext"))
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Canceled, ShouldBeTrue)
+		})
+
+		Convey("aborts the request at the deadline of the context", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := c.SubmitWithContext(ctx, c.createPost(errors.New("Test SubmitWithContext"), StackTrace{}))
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeTrue)
+		})
+
+		Convey("sends the report like SendError", func() {
+			So(c.SendErrorWithContext(context.Background(), errors.New("Test SubmitWithContext")), ShouldBeNil)
+			var post PostData
+			So(server.Requests()[0].Decode(&post), ShouldBeNil)
+			So(post.Details.Error.Message, ShouldEqual, "Test SubmitWithContext")
+			So(post.Details.Error.StackTrace[0].MethodName, ShouldStartWith, "TestSubmitWithContext")
+		})
+
+		Convey("delivers asynchronous reports after the context is canceled", func() {
+			c.Asynchronous(true)
+			ctx, cancel := context.WithCancel(context.Background())
+			So(c.SendErrorWithContext(ctx, errors.New("Test SubmitWithContext")), ShouldBeNil)
+			cancel()
+			_, delivered := server.WaitForRequests(1, 5*time.Second)
+			So(delivered, ShouldBeTrue)
+			So(c.Close(), ShouldBeNil)
+		})
+
+		Convey("limits asynchronous deliveries by the client timeout", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			c.Asynchronous(true).Timeout(20 * time.Millisecond)
+			So(c.SendErrorWithContext(context.Background(), errors.New("Test SubmitWithContext")), ShouldBeNil)
+			_, delivered := server.WaitForRequests(1, 5*time.Second)
+			So(delivered, ShouldBeTrue)
+			start := time.Now()
+			c.Close()
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+		})
+	})
+}
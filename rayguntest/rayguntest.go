@@ -0,0 +1,315 @@
+// Package rayguntest provides a fake of the Raygun entries API for tests.
+//
+// The fake accepts reports posted to /entries like Raygun does: it answers
+// 202 Accepted to valid reports, 403 Forbidden to requests without an API
+// key and 400 Bad Request to payloads violating the entries contract. Tests
+// can script the answers to the next requests, e.g. a 429 with Retry-After,
+// a slow answer or a dropped connection, and inspect the requests received.
+//
+//	server := rayguntest.New()
+//	defer server.Close()
+//	server.Script(rayguntest.TooManyRequests("30"), rayguntest.Accepted)
+//	// ... post reports to server.URL ...
+//	requests := server.Requests()
+package rayguntest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Response is an answer of the fake to a request.
+type Response struct {
+	Status     int           // the status code, 202 if 0
+	Body       string        // the response body
+	RetryAfter string        // the Retry-After header, if set
+	Delay      time.Duration // the time to wait before answering
+	Reset      bool          // if true, the connection is dropped instead of answered
+}
+
+// Accepted is the answer of Raygun to valid reports.
+var Accepted = Response{Status: http.StatusAccepted}
+
+// Unauthorized is the answer of Raygun to unknown API keys.
+var Unauthorized = Response{Status: http.StatusUnauthorized}
+
+// PayloadTooLarge is the answer of Raygun to reports exceeding its size
+// limit.
+var PayloadTooLarge = Response{Status: http.StatusRequestEntityTooLarge}
+
+// ConnectionReset drops the connection without answering.
+var ConnectionReset = Response{Reset: true}
+
+// BadRequest returns the answer of Raygun to invalid reports, with the given
+// body.
+func BadRequest(body string) Response {
+	return Response{Status: http.StatusBadRequest, Body: body}
+}
+
+// TooManyRequests returns the answer of Raygun while it is rate limiting,
+// with the given Retry-After header.
+func TooManyRequests(retryAfter string) Response {
+	return Response{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// Status returns an answer with the given status code.
+func Status(code int) Response {
+	return Response{Status: code}
+}
+
+// Slow returns the given answer, given after the given delay or once the
+// request is canceled.
+func Slow(delay time.Duration, r Response) Response {
+	r.Delay = delay
+	return r
+}
+
+// Request is a request received by the fake.
+type Request struct {
+	Method   string
+	Path     string
+	Header   http.Header
+	Body     []byte   // the body, decompressed if it was sent with gzip
+	Gzipped  bool     // if true, the body was sent with Content-Encoding gzip
+	Problems []string // the violations of the entries contract, answered with 400
+}
+
+// APIKey returns the API key the request was sent with.
+func (r Request) APIKey() string {
+	return r.Header.Get("X-ApiKey")
+}
+
+// Decode decodes the JSON body of the request into v.
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a fake of the Raygun entries API. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	script    []Response    // the answers to the next requests
+	fallback  Response      // the answer once the script is used up
+	requests  []Request     // the requests received so far
+	received  chan struct{} // closed and replaced whenever a request is received
+	onRequest func(Request) // called with every request before it is answered, if set
+}
+
+// New starts a fake accepting all valid reports over plain HTTP.
+func New() *Server {
+	s := newServer()
+	s.Start()
+	return s
+}
+
+// NewTLS starts a fake accepting all valid reports over TLS. Its Client
+// trusts its certificate.
+func NewTLS() *Server {
+	s := newServer()
+	s.StartTLS()
+	return s
+}
+
+// newServer returns an unstarted fake.
+func newServer() *Server {
+	s := &Server{fallback: Accepted, received: make(chan struct{})}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Script queues answers to the next requests, after the ones queued before.
+// Requests without an API key or violating the entries contract are answered
+// with 403 or 400 without using up a scripted answer.
+func (s *Server) Script(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// Fallback sets the answer to requests once the scripted answers are used
+// up, Accepted by default.
+func (s *Server) Fallback(r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = r
+}
+
+// OnRequest sets a function called with every request before it is
+// answered.
+func (s *Server) OnRequest(fn func(Request)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRequest = fn
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Count returns the number of requests received so far.
+func (s *Server) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// WaitForRequests waits up to the given timeout for n requests to be
+// received and returns the requests received so far. It reports whether
+// there were n of them in time.
+func (s *Server) WaitForRequests(n int, timeout time.Duration) ([]Request, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		requests, received := append([]Request(nil), s.requests...), s.received
+		s.mu.Unlock()
+		if len(requests) >= n {
+			return requests, true
+		}
+		select {
+		case <-received:
+		case <-timer.C:
+			return requests, false
+		}
+	}
+}
+
+// serve records and answers a request.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	req := readRequest(r)
+
+	forbidden := req.APIKey() == ""
+	s.mu.Lock()
+	answer := s.fallback
+	if !forbidden && len(req.Problems) == 0 && len(s.script) > 0 {
+		answer = s.script[0]
+		s.script = s.script[1:]
+	}
+	s.requests = append(s.requests, req)
+	close(s.received)
+	s.received = make(chan struct{})
+	onRequest := s.onRequest
+	s.mu.Unlock()
+
+	if onRequest != nil {
+		onRequest(req)
+	}
+
+	switch {
+	case forbidden:
+		http.Error(w, "Missing X-ApiKey header", http.StatusForbidden)
+		return
+	case len(req.Problems) > 0:
+		http.Error(w, strings.Join(req.Problems, "\n"), http.StatusBadRequest)
+		return
+	}
+
+	if answer.Delay > 0 {
+		timer := time.NewTimer(answer.Delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+		}
+	}
+	if answer.Reset {
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+		return
+	}
+	if answer.RetryAfter != "" {
+		w.Header().Set("Retry-After", answer.RetryAfter)
+	}
+	status := answer.Status
+	if status == 0 {
+		status = http.StatusAccepted
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, answer.Body)
+}
+
+// readRequest reads the given request and checks it against the entries
+// contract.
+func readRequest(r *http.Request) Request {
+	req := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		err = fmt.Errorf("unable to read body: %s", err.Error())
+	} else if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		req.Gzipped = true
+		if body, err = gunzip(body); err != nil {
+			err = fmt.Errorf("invalid gzip body: %s", err.Error())
+		}
+	}
+	req.Body = body
+	if err != nil {
+		req.Problems = append(req.Problems, err.Error())
+	}
+
+	if r.Method != http.MethodPost {
+		req.Problems = append(req.Problems, fmt.Sprintf("method %s instead of POST", r.Method))
+	}
+	if r.URL.Path != "/entries" {
+		req.Problems = append(req.Problems, fmt.Sprintf("path %s instead of /entries", r.URL.Path))
+	}
+	if err == nil {
+		req.Problems = append(req.Problems, validateEntry(body)...)
+	}
+	return req
+}
+
+// gunzip returns the decompressed data.
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// validateEntry returns the violations of the entries contract by the given
+// report: it has to be a JSON object with an occurredOn time in RFC 3339 and
+// details holding an error with a message.
+func validateEntry(body []byte) []string {
+	var entry struct {
+		OccurredOn *string `json:"occurredOn"`
+		Details    *struct {
+			Error *struct {
+				Message *string `json:"message"`
+			} `json:"error"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %s", err.Error())}
+	}
+
+	var problems []string
+	if entry.OccurredOn == nil {
+		problems = append(problems, "missing occurredOn")
+	} else if _, err := time.Parse(time.RFC3339, *entry.OccurredOn); err != nil {
+		problems = append(problems, fmt.Sprintf("occurredOn %q is not in RFC 3339", *entry.OccurredOn))
+	}
+	switch {
+	case entry.Details == nil:
+		problems = append(problems, "missing details")
+	case entry.Details.Error == nil:
+		problems = append(problems, "missing details.error")
+	case entry.Details.Error.Message == nil:
+		problems = append(problems, "missing details.error.message")
+	}
+	return problems
+}
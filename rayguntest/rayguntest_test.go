@@ -0,0 +1,125 @@
+package rayguntest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const validEntry = `{"occurredOn":"2024-01-02T03:04:05Z","details":{"error":{"message":"boom"}}}`
+
+func TestServer(t *testing.T) {
+	Convey("Server", t, func() {
+		server := New()
+		Reset(server.Close)
+
+		post := func(body string, header http.Header) (*http.Response, error) {
+			r, _ := http.NewRequest("POST", server.URL+"/entries", strings.NewReader(body))
+			r.Header.Set("X-ApiKey", "key")
+			for name, values := range header {
+				r.Header[name] = values
+			}
+			resp, err := server.Client().Do(r)
+			if err == nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			return resp, err
+		}
+
+		Convey("accepts valid reports", func() {
+			resp, err := post(validEntry, nil)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusAccepted)
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 1)
+			So(requests[0].APIKey(), ShouldEqual, "key")
+			So(requests[0].Problems, ShouldBeEmpty)
+			var entry map[string]interface{}
+			So(requests[0].Decode(&entry), ShouldBeNil)
+			So(entry["occurredOn"], ShouldEqual, "2024-01-02T03:04:05Z")
+		})
+
+		Convey("rejects requests without API key", func() {
+			resp, err := post(validEntry, http.Header{"X-Apikey": {""}})
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("rejects reports violating the entries contract", func() {
+			resp, err := post(`{"occurredOn":"yesterday","details":{}}`, nil)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(server.Requests()[0].Problems, ShouldResemble, []string{
+				`occurredOn "yesterday" is not in RFC 3339`,
+				"missing details.error",
+			})
+
+			resp, _ = post(`not json`, nil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("decompresses gzip bodies", func() {
+			var body bytes.Buffer
+			zw := gzip.NewWriter(&body)
+			io.WriteString(zw, validEntry)
+			zw.Close()
+			resp, err := post(body.String(), http.Header{"Content-Encoding": {"gzip"}})
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusAccepted)
+			request := server.Requests()[0]
+			So(request.Gzipped, ShouldBeTrue)
+			So(string(request.Body), ShouldEqual, validEntry)
+		})
+
+		Convey("answers with the script, then the fallback", func() {
+			server.Script(TooManyRequests("30"), BadRequest("invalid"), PayloadTooLarge)
+			server.Fallback(Unauthorized)
+			resp, _ := post(validEntry, nil)
+			So(resp.StatusCode, ShouldEqual, http.StatusTooManyRequests)
+			So(resp.Header.Get("Retry-After"), ShouldEqual, "30")
+			resp, _ = post(validEntry, nil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			resp, _ = post(validEntry, nil)
+			So(resp.StatusCode, ShouldEqual, http.StatusRequestEntityTooLarge)
+			resp, _ = post(validEntry, nil)
+			So(resp.StatusCode, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("drops connections", func() {
+			server.Script(ConnectionReset)
+			_, err := post(validEntry, nil)
+			So(err, ShouldNotBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("answers slowly until the request is canceled", func() {
+			server.Script(Slow(time.Minute, Accepted))
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			r, _ := http.NewRequestWithContext(ctx, "POST", server.URL+"/entries", strings.NewReader(validEntry))
+			r.Header.Set("X-ApiKey", "key")
+			start := time.Now()
+			_, err := server.Client().Do(r)
+			So(err, ShouldNotBeNil)
+			So(time.Since(start), ShouldBeLessThan, time.Minute)
+		})
+
+		Convey("waits for requests", func() {
+			go post(validEntry, nil)
+			requests, ok := server.WaitForRequests(1, 5*time.Second)
+			So(ok, ShouldBeTrue)
+			So(requests, ShouldHaveLength, 1)
+
+			_, ok = server.WaitForRequests(2, 10*time.Millisecond)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,312 @@
+package raygun4go
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces every value that is removed by the redaction rules.
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders are the request headers whose values are never sent
+// to Raygun unless the redaction rules are cleared.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-ApiKey",
+}
+
+// defaultRedactedFields are the query string and form field names whose values
+// are never sent to Raygun unless the redaction rules are cleared.
+var defaultRedactedFields = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"password",
+	"passwd",
+	"secret",
+	"token",
+}
+
+// defaultRedactionPatterns are regular expressions matching sensitive values
+// anywhere in the request data, regardless of the header or field name.
+var defaultRedactionPatterns = []string{
+	`(?i)bearer\s+[a-z0-9._~+/-]+=*`,
+	cardNumberPattern,
+}
+
+// cardNumberPattern matches runs of 13 to 16 digits, optionally separated by
+// spaces or dashes. Only runs passing the Luhn check are redacted, so that
+// timestamps and ids of the same length are kept.
+const cardNumberPattern = `\b(?:\d[ -]?){12,15}\d\b`
+
+// DefaultRedactedHeaders returns a copy of the header names redacted by
+// default.
+func DefaultRedactedHeaders() []string {
+	return copyStrings(defaultRedactedHeaders)
+}
+
+// DefaultRedactedFields returns a copy of the query string and form field
+// names redacted by default.
+func DefaultRedactedFields() []string {
+	return copyStrings(defaultRedactedFields)
+}
+
+// DefaultRedactionPatterns returns a copy of the regular expressions whose
+// matches are redacted by default.
+func DefaultRedactionPatterns() []string {
+	return copyStrings(defaultRedactionPatterns)
+}
+
+// RedactionConfig describes which parts of the request data are removed or
+// replaced before a report is sent to Raygun. Header and field names are
+// matched case-insensitively.
+type RedactionConfig struct {
+	Headers  []string // header names whose values are redacted
+	Fields   []string // query string and form field names whose values are redacted
+	Patterns []string // regular expressions whose matches are redacted from any value
+
+	Policy         HeaderPolicyPreset // the preset the rules started from, see HeaderPolicy
+	AllowedHeaders []string           // if not nil, the only header names captured
+	DropCookies    bool               // if true, cookies are not captured
+	DropIPAddress  bool               // if true, the client IP address is not captured
+}
+
+// redactor holds a RedactionConfig together with its compiled patterns.
+type redactor struct {
+	config   RedactionConfig
+	patterns []*regexp.Regexp
+	invalid  []error // the errors compiling ignored patterns, reported in strict mode
+}
+
+// newDefaultRedactor returns a redactor holding the default rules.
+func newDefaultRedactor() redactor {
+	r := redactor{config: RedactionConfig{
+		Headers: DefaultRedactedHeaders(),
+		Fields:  DefaultRedactedFields(),
+	}}
+	for _, p := range defaultRedactionPatterns {
+		r.addPattern(p)
+	}
+	return r
+}
+
+// clone returns a copy of the redactor that shares no slices with the
+// original.
+func (r redactor) clone() redactor {
+	return redactor{
+		config: RedactionConfig{
+			Headers:  copyStrings(r.config.Headers),
+			Fields:   copyStrings(r.config.Fields),
+			Patterns: copyStrings(r.config.Patterns),
+
+			Policy:         r.config.Policy,
+			AllowedHeaders: copyStrings(r.config.AllowedHeaders),
+			DropCookies:    r.config.DropCookies,
+			DropIPAddress:  r.config.DropIPAddress,
+		},
+		patterns: append([]*regexp.Regexp(nil), r.patterns...),
+		invalid:  append([]error(nil), r.invalid...),
+	}
+}
+
+// addPattern compiles and adds the given pattern. Invalid patterns are
+// ignored and reported as an error.
+func (r *redactor) addPattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	r.config.Patterns = append(r.config.Patterns, pattern)
+	r.patterns = append(r.patterns, re)
+	return nil
+}
+
+// redactRequest applies the redaction rules to the given request data.
+func (r redactor) redactRequest(d *RequestData) {
+	r.restrictRequest(d)
+	d.Headers = r.redactMap(d.Headers, r.config.Headers)
+	d.QueryString = r.redactMap(d.QueryString, r.config.Fields)
+	d.Form = r.redactMap(d.Form, r.config.Fields)
+	d.URL = r.redactURL(d.URL)
+	d.queryValues = r.redactValues(d.queryValues, r.config.Fields)
+
+	// The cookies are sent in the Cookie header, so they are redacted with it.
+	cookieNames := r.config.Fields
+	if containsFold(r.config.Headers, "Cookie") {
+		cookieNames = nil
+		for name := range d.cookies {
+			cookieNames = append(cookieNames, name)
+		}
+	}
+	d.cookies = r.redactMap(d.cookies, cookieNames)
+}
+
+// redactMap returns a copy of the given map with the values of all given
+// names and all pattern matches redacted.
+func (r redactor) redactMap(m map[string]string, names []string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(m))
+	for k, v := range m {
+		if containsFold(names, k) {
+			redacted[k] = redactedValue
+		} else {
+			redacted[k] = r.redactValue(v)
+		}
+	}
+	return redacted
+}
+
+// redactValues is like redactMap for maps holding several values per name.
+func (r redactor) redactValues(m map[string][]string, names []string) map[string][]string {
+	if m == nil {
+		return nil
+	}
+	redacted := make(map[string][]string, len(m))
+	for k, vs := range m {
+		values := make([]string, len(vs))
+		for i, v := range vs {
+			if containsFold(names, k) {
+				values[i] = redactedValue
+			} else {
+				values[i] = r.redactValue(v)
+			}
+		}
+		redacted[k] = values
+	}
+	return redacted
+}
+
+// redactURL redacts the query string fields of the given URL as well as all
+// pattern matches.
+func (r redactor) redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err == nil && u.RawQuery != "" {
+		query := u.Query()
+		changed := false
+		for k := range query {
+			if containsFold(r.config.Fields, k) {
+				query[k] = []string{redactedValue}
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = query.Encode()
+			rawURL = u.String()
+		}
+	}
+	return r.redactValue(rawURL)
+}
+
+// redactValue replaces all pattern matches in the given value.
+func (r redactor) redactValue(v string) string {
+	for _, re := range r.patterns {
+		if re.String() == cardNumberPattern {
+			v = re.ReplaceAllStringFunc(v, redactCardNumber)
+			continue
+		}
+		v = re.ReplaceAllString(v, redactedValue)
+	}
+	return v
+}
+
+// redactCardNumber redacts the given match of cardNumberPattern if it passes
+// the Luhn check.
+func redactCardNumber(match string) string {
+	if luhnValid(match) {
+		return redactedValue
+	}
+	return match
+}
+
+// luhnValid reports whether the digits in the given string pass the Luhn
+// check used by card numbers. Other characters are skipped.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// RedactHeaders is a chainable option-setting method to add header names whose
+// values are redacted before the report is sent.
+func (c *Client) RedactHeaders(names ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.redaction.config.Headers = append(c.redaction.config.Headers, names...)
+	return c
+}
+
+// RedactFields is a chainable option-setting method to add query string and
+// form field names whose values are redacted before the report is sent.
+func (c *Client) RedactFields(names ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.redaction.config.Fields = append(c.redaction.config.Fields, names...)
+	return c
+}
+
+// RedactPatterns is a chainable option-setting method to add regular
+// expressions whose matches are redacted from all request data. Patterns that
+// do not compile are ignored.
+func (c *Client) RedactPatterns(patterns ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	for _, p := range patterns {
+		if err := c.redaction.addPattern(p); err != nil {
+			c.redaction.invalid = append(c.redaction.invalid, fmt.Errorf("Ignored redaction pattern %q (%w)", p, err))
+			c.errorf("Ignoring redaction pattern: %s", err.Error())
+		}
+	}
+	return c
+}
+
+// ClearRedaction is a chainable option-setting method removing all redaction
+// rules, including the defaults.
+func (c *Client) ClearRedaction() *Client {
+	if c == nil {
+		return nil
+	}
+	c.redaction = redactor{}
+	return c
+}
+
+// EffectiveRedactionConfig returns a copy of the redaction rules the client
+// currently applies, i.e. the defaults merged with all added rules. Changing
+// the returned value does not affect the client.
+func (c *Client) EffectiveRedactionConfig() RedactionConfig {
+	if c == nil {
+		return RedactionConfig{}
+	}
+	return c.redaction.clone().config
+}
+
+// containsFold reports whether the given names contain name, ignoring case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedaction(t *testing.T) {
+	Convey("Redaction", t, func() {
+		c, _ := New("app", "key")
+
+		Convey("#DefaultRedactedHeaders returns a copy", func() {
+			h := DefaultRedactedHeaders()
+			So(h, ShouldContain, "Authorization")
+			h[0] = "changed"
+			So(DefaultRedactedHeaders(), ShouldNotContain, "changed")
+		})
+
+		Convey("#DefaultRedactedFields returns a copy", func() {
+			f := DefaultRedactedFields()
+			So(f, ShouldContain, "password")
+			f[0] = "changed"
+			So(DefaultRedactedFields(), ShouldNotContain, "changed")
+		})
+
+		Convey("#DefaultRedactionPatterns returns a copy", func() {
+			p := DefaultRedactionPatterns()
+			So(len(p), ShouldBeGreaterThan, 0)
+			p[0] = "changed"
+			So(DefaultRedactionPatterns(), ShouldNotContain, "changed")
+		})
+
+		Convey("#EffectiveRedactionConfig", func() {
+			Convey("holds the defaults", func() {
+				rc := c.EffectiveRedactionConfig()
+				So(rc.Headers, ShouldResemble, DefaultRedactedHeaders())
+				So(rc.Fields, ShouldResemble, DefaultRedactedFields())
+				So(rc.Patterns, ShouldResemble, DefaultRedactionPatterns())
+			})
+
+			Convey("reflects added entries", func() {
+				c.RedactHeaders("X-Internal").RedactFields("pin").RedactPatterns(`secret-\d+`)
+				rc := c.EffectiveRedactionConfig()
+				So(rc.Headers, ShouldContain, "X-Internal")
+				So(rc.Headers, ShouldContain, "Authorization")
+				So(rc.Fields, ShouldContain, "pin")
+				So(rc.Patterns, ShouldContain, `secret-\d+`)
+			})
+
+			Convey("ignores invalid patterns", func() {
+				c.RedactPatterns("(")
+				So(c.EffectiveRedactionConfig().Patterns, ShouldResemble, DefaultRedactionPatterns())
+			})
+
+			Convey("reflects cleared entries", func() {
+				c.ClearRedaction().RedactHeaders("X-Internal")
+				rc := c.EffectiveRedactionConfig()
+				So(rc.Headers, ShouldResemble, []string{"X-Internal"})
+				So(rc.Fields, ShouldBeEmpty)
+				So(rc.Patterns, ShouldBeEmpty)
+			})
+
+			Convey("returns a copy", func() {
+				rc := c.EffectiveRedactionConfig()
+				rc.Headers[0] = "changed"
+				rc.Fields = append(rc.Fields, "changed")
+				So(c.EffectiveRedactionConfig().Headers, ShouldNotContain, "changed")
+				So(c.EffectiveRedactionConfig().Fields, ShouldNotContain, "changed")
+			})
+		})
+
+		Convey("#Clone copies the redaction rules", func() {
+			clone := c.Clone()
+			c.RedactHeaders("X-Internal")
+			So(clone.EffectiveRedactionConfig().Headers, ShouldNotContain, "X-Internal")
+		})
+
+		Convey("redacts the request data", func() {
+			u := "http://www.example.com?foo=bar&password=hunter2"
+			r, _ := http.NewRequest("GET", u, nil)
+			r.PostForm = url.Values{"token": []string{"abc"}, "card": []string{"4111 1111 1111 1111"}}
+			r.Header.Add("Authorization", "Basic Zm9vOmJhcg==")
+			r.Header.Add("X-Internal", "internal")
+			r.Header.Add("X-Other", "Bearer abc.def")
+			c.Request(r).RedactHeaders("x-internal")
+
+			d := c.createPost(errors.New("test"), StackTrace{}).Details.Request
+			So(d.Headers["Authorization"], ShouldEqual, redactedValue)
+			So(d.Headers["X-Internal"], ShouldEqual, redactedValue)
+			So(d.Headers["X-Other"], ShouldEqual, redactedValue)
+			So(d.QueryString["password"], ShouldEqual, redactedValue)
+			So(d.QueryString["foo"], ShouldEqual, "bar")
+			So(d.Form["token"], ShouldEqual, redactedValue)
+			So(d.Form["card"], ShouldEqual, redactedValue)
+			So(d.URL, ShouldNotContainSubstring, "hunter2")
+			So(d.URL, ShouldContainSubstring, "foo=bar")
+		})
+
+		Convey("redacts card numbers only if they pass the Luhn check", func() {
+			r, _ := http.NewRequest("GET", "http://www.example.com/orders/1700000000123?since=1700000000000", nil)
+			r.PostForm = url.Values{"card": []string{"4111-1111-1111-1111"}, "order": []string{"4111 1111 1111 1112"}}
+			c.Request(r)
+
+			d := c.createPost(errors.New("test"), StackTrace{}).Details.Request
+			So(d.Form["card"], ShouldEqual, redactedValue)
+			So(d.Form["order"], ShouldEqual, "4111 1111 1111 1112")
+			So(d.URL, ShouldEqual, "http://www.example.com/orders/1700000000123?since=1700000000000")
+			So(d.QueryString["since"], ShouldEqual, "1700000000000")
+		})
+
+		Convey("redacts cookies by default", func() {
+			r, _ := http.NewRequest("GET", "http://www.example.com", nil)
+			r.Header.Add("Cookie", "theme=dark")
+			c.Request(r)
+
+			d := c.createPost(errors.New("test"), StackTrace{}).Details.Request
+			So(d.Headers["Cookie"], ShouldEqual, redactedValue)
+			So(DefaultRedactedHeaders(), ShouldContain, "Cookie")
+
+			c.ClearRedaction().RedactHeaders("Authorization")
+			d = c.createPost(errors.New("test"), StackTrace{}).Details.Request
+			So(d.Headers["Cookie"], ShouldEqual, "theme=dark")
+		})
+
+		Convey("keeps the request data if redaction is cleared", func() {
+			r, _ := http.NewRequest("GET", "http://www.example.com?password=hunter2", nil)
+			r.Header.Add("Authorization", "Basic Zm9vOmJhcg==")
+			c.Request(r).ClearRedaction()
+
+			d := c.createPost(errors.New("test"), StackTrace{}).Details.Request
+			So(d.Headers["Authorization"], ShouldEqual, "Basic Zm9vOmJhcg==")
+			So(d.QueryString["password"], ShouldEqual, "hunter2")
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package raygun4go
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RelaySocket is a chainable option-setting method to post reports through
+// a local relay agent listening on the Unix domain socket at the given path,
+// which may also be given as a "unix://" URL. The requests are sent in plain
+// HTTP over the socket with the same path, host and headers as they would be
+// sent to Raygun, so the relay can forward them verbatim. Failed submissions
+// return a *SubmitError holding the socket path. An HTTP client set with
+// HTTPClient takes precedence over the one dialing the socket, e.g. to use a
+// pre-configured transport. Passing "" posts reports directly again.
+func (c *Client) RelaySocket(path string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.refuseLiveMode("RelaySocket")
+	path = strings.TrimPrefix(path, "unix://")
+	c.relaySocket = path
+	c.relayClient = nil
+	if path != "" {
+		c.relayClient = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}}
+	}
+	return c
+}
+
+// entriesURL returns the URL reports are posted to. Reports posted through a
+// relay socket use plain HTTP, as the relay handles the connection to Raygun.
+func (c *Client) entriesURL() string {
+	endpoint := c.apiEndpoint()
+	if c.relaySocket != "" && strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + strings.TrimPrefix(endpoint, "https://")
+	}
+	return endpoint + "/entries"
+}
@@ -0,0 +1,73 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRelaySocket(t *testing.T) {
+	Convey("RelaySocket", t, func() {
+		dir, _ := os.MkdirTemp("", "relay")
+		socket := filepath.Join(dir, "raygun-relay.sock")
+		Reset(func() { os.RemoveAll(dir) })
+
+		c, _ := New("app", "key")
+		c.newReportID = func() string { return "report-1" }
+
+		Convey("posts reports through the socket", func() {
+			listener, err := net.Listen("unix", socket)
+			So(err, ShouldBeNil)
+			requests := make(chan *http.Request, 1)
+			bodies := make(chan PostData, 1)
+			server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var post PostData
+				json.NewDecoder(r.Body).Decode(&post)
+				requests <- r
+				bodies <- post
+				w.WriteHeader(http.StatusAccepted)
+			})}
+			go server.Serve(listener)
+			Reset(func() { server.Close() })
+
+			c.RelaySocket("unix://" + socket)
+			So(c.Clone().SendError(errors.New("Test RelaySocket")), ShouldBeNil)
+
+			var r *http.Request
+			select {
+			case r = <-requests:
+			case <-time.After(5 * time.Second):
+			}
+			So(r, ShouldNotBeNil)
+			So(r.Method, ShouldEqual, "POST")
+			So(r.URL.Path, ShouldEqual, "/entries")
+			So(r.Host, ShouldEqual, "api.raygun.com")
+			So(r.Header.Get("X-ApiKey"), ShouldEqual, "key")
+			So(r.Header.Get("Idempotency-Key"), ShouldEqual, "report-1")
+			post := <-bodies
+			So(post.Details.Error.Message, ShouldEqual, "Test RelaySocket")
+		})
+
+		Convey("returns the socket path with connection errors", func() {
+			c.RelaySocket(socket)
+			err := c.SendError(errors.New("Test RelaySocket"))
+			var submitErr *SubmitError
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Socket, ShouldEqual, socket)
+			So(err.Error(), ShouldContainSubstring, socket)
+		})
+
+		Convey("can be turned off", func() {
+			c.RelaySocket(socket).RelaySocket("")
+			So(c.postClient(), ShouldEqual, defaultHTTPClient)
+			So(c.entriesURL(), ShouldEqual, raygunEndpoint+"/entries")
+		})
+	})
+}
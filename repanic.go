@@ -0,0 +1,120 @@
+package raygun4go
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// reportedPanicTTL is the time a value Protect re-panicked with is
+// remembered as reported.
+const reportedPanicTTL = 5 * time.Second
+
+// maxReportedPanics is the number of values Protect re-panicked with that
+// are remembered at most.
+const maxReportedPanics = 64
+
+// AlreadyReported is the value HandleError re-panics with if Repanic is set.
+// It carries the recovered value and tells HandleError and the middleware
+// further up the stack that the panic was reported already, so they recover
+// it without reporting it again.
+type AlreadyReported struct {
+	Value interface{} // the value originally passed to panic
+}
+
+// Error returns the message of the original value.
+func (r *AlreadyReported) Error() string {
+	if err, ok := r.Value.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprint(r.Value)
+}
+
+// Unwrap returns the original value if it is an error.
+func (r *AlreadyReported) Unwrap() error {
+	err, _ := r.Value.(error)
+	return err
+}
+
+// Repanic is a chainable option-setting method to make HandleError re-panic
+// after reporting a panic, so it keeps unwinding the stack. The value is
+// wrapped in an *AlreadyReported, which outer calls of HandleError and the
+// middleware recognize and do not report again. They re-panic with it in turn
+// only if their client has Repanic set as well.
+func (c *Client) Repanic(r bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.repanic = r
+	return c
+}
+
+// reportedPanics remembers the values Protect re-panicked with after
+// reporting them, so HandleError and the middleware further up the stack
+// recognize them without the panic value being wrapped. It is shared by a
+// client and all its clones.
+type reportedPanics struct {
+	mu      sync.Mutex
+	entries []reportedPanic
+}
+
+// reportedPanic is a value Protect re-panicked with.
+type reportedPanic struct {
+	value  interface{}
+	expiry time.Time
+}
+
+// mark remembers the given value as reported.
+func (r *reportedPanics) mark(e interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(time.Now())
+	if len(r.entries) == maxReportedPanics {
+		r.entries = r.entries[1:]
+	}
+	r.entries = append(r.entries, reportedPanic{value: e, expiry: time.Now().Add(reportedPanicTTL)})
+}
+
+// consume reports whether the given value was marked as reported, and
+// forgets it.
+func (r *reportedPanics) consume(e interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(time.Now())
+	for i, entry := range r.entries {
+		if sameValue(entry.value, e) {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// prune forgets the values marked before the TTL. The caller holds r.mu.
+func (r *reportedPanics) prune(now time.Time) {
+	i := 0
+	for i < len(r.entries) && now.After(r.entries[i].expiry) {
+		i++
+	}
+	r.entries = r.entries[i:]
+}
+
+// sameValue reports whether the given recovered values are equal. Values
+// that cannot be compared are never the same.
+func sameValue(a, b interface{}) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// alreadyReported wraps the given recovered value in an *AlreadyReported,
+// unless it is one already.
+func alreadyReported(e interface{}) *AlreadyReported {
+	if r, ok := e.(*AlreadyReported); ok {
+		return r
+	}
+	return &AlreadyReported{Value: e}
+}
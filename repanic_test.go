@@ -0,0 +1,67 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRepanic(t *testing.T) {
+	Convey("#Repanic", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Repanic(true)
+
+		Convey("re-panics with the reported value", func() {
+			So(func() {
+				defer c.HandleError()
+				panic("Test Repanic")
+			}, ShouldPanicWith, &AlreadyReported{Value: "Test Repanic"})
+			So(len(server.posts), ShouldEqual, 1)
+		})
+
+		Convey("reports nested recoveries once", func() {
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				defer c.HandleError()
+				func() {
+					defer c.HandleError()
+					panic(errors.New("Test Repanic"))
+				}()
+			}()
+			So(len(server.posts), ShouldEqual, 1)
+			So(recovered, ShouldHaveSameTypeAs, &AlreadyReported{})
+			So(recovered.(error).Error(), ShouldEqual, "Test Repanic")
+			So(errors.Unwrap(recovered.(error)), ShouldNotBeNil)
+		})
+
+		Convey("lets an outer client without Repanic end the panic", func() {
+			outer, _ := New("app", "key")
+			So(func() {
+				defer outer.HandleError()
+				func() {
+					defer c.HandleError()
+					panic("Test Repanic")
+				}()
+			}, ShouldNotPanic)
+			So(len(server.posts), ShouldEqual, 1)
+		})
+
+		Convey("is recognized by the middleware", func() {
+			w := httptest.NewRecorder()
+			handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer c.HandleError()
+				panic("Test Repanic")
+			}))
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(len(server.posts), ShouldEqual, 1)
+		})
+	})
+}
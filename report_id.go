@@ -0,0 +1,38 @@
+package raygun4go
+
+// reportIDKey is the custom data key the id of a report is stored under.
+const reportIDKey = "reportId"
+
+// idempotencyKeyHeader is the header the id of a report is sent in, so
+// repeated submissions of a report can be recognized.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// SubmitResult describes a report that was submitted.
+type SubmitResult struct {
+	ReportID string // the id of the report, see PostData.ReportID
+}
+
+// ReportID returns the id generated for the report when it was created. The
+// id is sent in the custom data as "reportId" and stays the same however
+// often the report is submitted, including after it was persisted by Close
+// and resumed, so duplicates can be recognized and a report can be found in
+// Raygun. It returns "" for posts not created by this package.
+func (p PostData) ReportID() string {
+	if p.reportID != "" {
+		return p.reportID
+	}
+	if data, ok := p.Details.UserCustomData.(map[string]interface{}); ok {
+		id, _ := data[reportIDKey].(string)
+		return id
+	}
+	return ""
+}
+
+// SubmitWithResult submits the given post like Submit and returns its id
+// along with the error, e.g. for logging.
+func (c *Client) SubmitWithResult(post PostData) (SubmitResult, error) {
+	if c == nil {
+		return SubmitResult{}, ErrNoClient
+	}
+	return SubmitResult{ReportID: post.ReportID()}, c.Submit(post)
+}
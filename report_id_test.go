@@ -0,0 +1,91 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReportID(t *testing.T) {
+	Convey("Report ids", t, func() {
+		type delivery struct {
+			post PostData
+			key  string
+		}
+		deliveries := make(chan delivery, 10)
+		status := http.StatusAccepted
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var post PostData
+			json.NewDecoder(r.Body).Decode(&post)
+			w.WriteHeader(status)
+			deliveries <- delivery{post, r.Header.Get("Idempotency-Key")}
+		}))
+		defaultEndpoint := raygunEndpoint
+		raygunEndpoint = server.URL
+		Reset(func() {
+			raygunEndpoint = defaultEndpoint
+			server.Close()
+		})
+		next := func() delivery {
+			select {
+			case d := <-deliveries:
+				return d
+			case <-time.After(5 * time.Second):
+				return delivery{}
+			}
+		}
+
+		c, _ := New("app", "key")
+		post := c.createPost(errors.New("Test ReportID"), StackTrace{})
+
+		Convey("are generated per report", func() {
+			So(post.ReportID(), ShouldNotBeEmpty)
+			So(c.createPost(errors.New("Test ReportID"), StackTrace{}).ReportID(), ShouldNotEqual, post.ReportID())
+		})
+
+		Convey("are kept by user custom data", func() {
+			c.CustomData(map[string]interface{}{"foo": "bar"})
+			post := c.createPost(errors.New("Test ReportID"), StackTrace{})
+			data := post.Details.UserCustomData.(map[string]interface{})
+			So(data["foo"], ShouldEqual, "bar")
+			So(data["reportId"], ShouldEqual, post.ReportID())
+		})
+
+		Convey("stay the same across attempts", func() {
+			status = http.StatusInternalServerError
+			So(c.Submit(post), ShouldNotBeNil)
+			status = http.StatusAccepted
+			result, err := c.SubmitWithResult(post)
+			So(err, ShouldBeNil)
+			So(result.ReportID, ShouldEqual, post.ReportID())
+
+			for i := 0; i < 2; i++ {
+				d := next()
+				So(d.key, ShouldEqual, post.ReportID())
+				So(d.post.ReportID(), ShouldEqual, post.ReportID())
+			}
+		})
+
+		Convey("stay the same when persisted and resumed", func() {
+			dir := t.TempDir()
+			So(persistReports(dir, []queuedReport{{client: c, post: post}}), ShouldBeNil)
+
+			resumed, _ := New("app", "key")
+			So(resumed.Resume(dir), ShouldBeNil)
+			defer resumed.Close()
+
+			d := next()
+			So(d.key, ShouldEqual, post.ReportID())
+			So(d.post.ReportID(), ShouldEqual, post.ReportID())
+		})
+
+		Convey("are empty for posts not created by the client", func() {
+			So(PostData{}.ReportID(), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package raygun4go
+
+import "time"
+
+// OccurrenceWindowKey is the custom data key WithOccurrenceWindow stores the
+// occurrence window of a report under.
+const OccurrenceWindowKey = "occurrenceWindow"
+
+// ReportOption changes a single report, in addition to the configuration of
+// the client sending it.
+type ReportOption func(*reportOptions)
+
+// reportOptions holds the per-report settings applied by ReportOptions.
+type reportOptions struct {
+	customData map[string]interface{} // merged into the custom data from the context
+	occurredOn time.Time              // overrides the time of the report if set
+
+	httpStatusThreshold    int  // overrides the threshold of ReportHTTPErrorsAbove if set
+	hasHTTPStatusThreshold bool // if true, httpStatusThreshold is set
+	httpStatus             int  // the HTTP status carried by the reported error, if any
+
+	application *application // the application the report is sent to instead of the client's, if set
+
+	tags        []string // added to the tags of the report, see WithTags
+	runtimeTags []string // added by raygun4go for the reported error, e.g. oom-suspect
+
+	priority Priority // the priority in the asynchronous queue, see WithPriority
+}
+
+// newReportOptions applies the given options.
+func newReportOptions(opts []ReportOption) reportOptions {
+	var o reportOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// addCustomData adds an entry that is merged into the custom data.
+func (o *reportOptions) addCustomData(key string, value interface{}) {
+	if o.customData == nil {
+		o.customData = make(map[string]interface{})
+	}
+	o.customData[key] = value
+}
+
+// OccurrenceWindow describes how often an error occurred over which time when
+// it is reported once for many occurrences.
+type OccurrenceWindow struct {
+	FirstOccurredOn string `json:"firstOccurredOn"` // the time of the first occurrence, format 2006-01-02T15:04:05Z
+	LastOccurredOn  string `json:"lastOccurredOn"`  // the time of the last occurrence, format 2006-01-02T15:04:05Z
+	Count           int    `json:"count"`           // the number of occurrences
+}
+
+// WithOccurrenceWindow reports an error that occurred count times between
+// first and last. The window is stored in the custom data under
+// OccurrenceWindowKey and the report's occurredOn is set to last.
+func WithOccurrenceWindow(first, last time.Time, count int) ReportOption {
+	return func(o *reportOptions) {
+		o.addCustomData(OccurrenceWindowKey, OccurrenceWindow{
+			FirstOccurredOn: formatOccurredOn(first),
+			LastOccurredOn:  formatOccurredOn(last),
+			Count:           count,
+		})
+		o.occurredOn = last
+	}
+}
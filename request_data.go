@@ -1,8 +1,12 @@
 package raygun4go
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
+	"regexp"
+	"sync"
 	"time"
 )
 
@@ -10,16 +14,46 @@ import (
 type PostData struct {
 	OccuredOn string      `json:"occurredOn"` // the time the error occured on, format 2006-01-02T15:04:05Z
 	Details   DetailsData `json:"details"`    // all the details needed by the API
+
+	wireFormat   int     // the wire format the post is encoded in, see WireFormat
+	degradations []error // the problems building the post, reported in strict mode
+	reportID     string  // the id generated for the post, see ReportID
+
+	redactPatterns []*regexp.Regexp // the redaction patterns of the client, applied by Summary
+
+	appName string // the application the post is reported under instead of the client's, see WithApplication
+	apiKey  string // the API key of that application
+
+	priority Priority // the priority in the asynchronous queue, see WithPriority
+
+	raw []byte // the encoded post passed to SubmitBytes, sent as is
 }
 
 // newPostData triggers the creation of and returns a PostData-struct. It needs
 // the configured context from the Client, the error and the corresponding
 // stack trace.
-func newPostData(context contextInformation, err error, stack StackTrace) PostData {
+func newPostData(context contextInformation, err error, stack StackTrace, format int) PostData {
 	return PostData{
-		OccuredOn: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
-		Details:   newDetailsData(context, err, stack),
+		OccuredOn:  formatOccurredOnFor(time.Now(), format),
+		Details:    newDetailsData(context, err, stack, format),
+		wireFormat: format,
+	}
+}
+
+// formatOccurredOn formats the given time as expected for PostData.OccuredOn
+// in wire format v1.
+func formatOccurredOn(t time.Time) string {
+	return formatOccurredOnFor(t, WireFormatV1)
+}
+
+// formatOccurredOnFor formats the given time as expected for
+// PostData.OccuredOn in the given wire format. Wire format v2 includes
+// milliseconds.
+func formatOccurredOnFor(t time.Time, format int) string {
+	if format == WireFormatV2 {
+		return t.UTC().Format("2006-01-02T15:04:05.000Z")
 	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
 }
 
 // detailsData is the container holding all information regarding the more
@@ -35,23 +69,51 @@ type DetailsData struct {
 	Context        Context        `json:"context"`        // the identifier from the context
 	Client         ClientData     `json:"client"`         // information on this client
 	GroupingKey    *string        `json:"groupingKey"`    // a custom key that Raygun will use for grouping errors
+
+	// Breadcrumbs are the events recorded before the error. The field is
+	// left out if there are none, so the payload stays unchanged without.
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty"`
+
+	// Response is the response sent for the request, if known. It is left
+	// out otherwise.
+	Response *ResponseData `json:"response,omitempty"`
+
+	omitRequest bool // if true, the request section is left out of the JSON entirely
+}
+
+// MarshalJSON encodes the details, leaving out the request section if request
+// data collection is disabled.
+func (d DetailsData) MarshalJSON() ([]byte, error) {
+	type details DetailsData
+	if !d.omitRequest {
+		return json.Marshal(details(d))
+	}
+	return json.Marshal(struct {
+		details
+		Request *RequestData `json:"request,omitempty"`
+	}{details: details(d)})
 }
 
 // newDetailsData returns a struct with all known details. It needs the context,
 // the error and the stack trace.
-func newDetailsData(c contextInformation, err error, stack StackTrace) DetailsData {
+func newDetailsData(c contextInformation, err error, stack StackTrace, format int) DetailsData {
 	hostname, e := os.Hostname()
 	if e != nil {
 		hostname = "not available"
 	}
 
+	request := newRequestData(c.Request, c.requestForm, format)
+	if c.RequestRef != nil {
+		request = c.RequestRef.requestData(format)
+	}
+
 	return DetailsData{
 		MachineName:    hostname,
 		Version:        c.Version,
 		Error:          newErrorData(err, stack),
 		Tags:           c.Tags,
 		UserCustomData: c.CustomData,
-		Request:        newRequestData(c.Request),
+		Request:        request,
 		User:           User{c.User},
 		Context:        Context{c.Identifier()},
 		Client:         ClientData{"raygun4go", packageVersion, "https://github.com/MindscapeHQ/raygun4go"},
@@ -90,6 +152,16 @@ type StackTraceElement struct {
 	MethodName  string `json:"methodName"`
 }
 
+// location returns the element's function and source position in the form
+// "package.function (file:line)".
+func (e StackTraceElement) location() string {
+	function := trimArguments(e.MethodName)
+	if e.PackageName != "" {
+		function = e.PackageName + "." + function
+	}
+	return fmt.Sprintf("%s (%s:%d)", function, e.FileName, e.LineNumber)
+}
+
 // StackTrace represents a series of stack trace elements, each detailing a level of the call stack.
 // Users can manually build a StackTrace by appending StackTraceElement instances to it.
 type StackTrace []StackTraceElement
@@ -114,26 +186,146 @@ type RequestData struct {
 	QueryString map[string]string `json:"queryString"` // key-value-pairs from the URI parameters
 	Form        map[string]string `json:"form"`        // key-value-pairs from a given form (POST)
 	Headers     map[string]string `json:"headers"`     // key-value-pairs from the header
+
+	queryValues map[string][]string // all values of the URI parameters, wire format v2 only
+	cookies     map[string]string   // the cookies sent with the request, wire format v2 only
+	parseErr    error               // the error parsing the form, if any, reported in strict mode
 }
 
 // newRequestData parses all information from the request in the context to a
-// struct. The struct is empty if no request was set.
-func newRequestData(r *http.Request) RequestData {
+// struct, parsing its form through the given guard, which may be nil. The
+// struct is empty if no request was set.
+func newRequestData(r *http.Request, guard *requestForm, format int) RequestData {
 	if r == nil {
 		return RequestData{}
 	}
+	if guard == nil {
+		guard = newRequestForm(r)
+	}
 
-	r.ParseForm()
+	form, parseErr := guard.parse()
 
-	return RequestData{
+	d := RequestData{
 		HostName:    r.Host,
 		URL:         r.URL.String(),
 		HTTPMethod:  r.Method,
 		IPAddress:   r.RemoteAddr,
 		QueryString: arrayMapToStringMap(r.URL.Query()),
-		Form:        arrayMapToStringMap(r.PostForm),
+		Form:        form,
 		Headers:     arrayMapToStringMap(r.Header),
+		parseErr:    parseErr,
+	}
+	if format == WireFormatV2 {
+		d.queryValues = r.URL.Query()
+		d.cookies = requestCookies(r)
+	}
+	return d
+}
+
+// requestCookies returns the cookies sent with the given request, or nil if
+// there are none.
+func requestCookies(r *http.Request) map[string]string {
+	cookies := r.Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		m[cookie.Name] = cookie.Value
+	}
+	return m
+}
+
+// empty reports whether no request data is set.
+func (d RequestData) empty() bool {
+	return d.HostName == "" && d.URL == "" && d.HTTPMethod == "" && d.IPAddress == "" &&
+		len(d.QueryString) == 0 && len(d.Form) == 0 && len(d.Headers) == 0 &&
+		len(d.queryValues) == 0 && len(d.cookies) == 0
+}
+
+// requestRef holds the parts of a request copied by Client.RequestRef and the
+// request itself for the parts that are read when an error is reported.
+type requestRef struct {
+	request *http.Request // the request, read only while its context is not done, nil if data is complete
+	data    RequestData   // the parts copied eagerly
+	form    *requestForm  // guards the form of request, nil if data is complete
+}
+
+// newRequestRef copies the immutable parts of the given request. It returns
+// nil if no request is given.
+func newRequestRef(r *http.Request) *requestRef {
+	if r == nil {
+		return nil
+	}
+
+	return &requestRef{
+		request: r,
+		form:    newRequestForm(r),
+		data: RequestData{
+			HostName:    r.Host,
+			URL:         r.URL.String(),
+			HTTPMethod:  r.Method,
+			IPAddress:   r.RemoteAddr,
+			QueryString: arrayMapToStringMap(r.URL.Query()),
+			Headers:     arrayMapToStringMap(r.Header),
+			queryValues: r.URL.Query(),
+			cookies:     requestCookies(r),
+		},
+	}
+}
+
+// requestData returns the eagerly copied parts of the request for the given
+// wire format, completed by its form if the request is still active.
+func (ref *requestRef) requestData(format int) RequestData {
+	d := ref.data
+	if format != WireFormatV2 {
+		d.queryValues, d.cookies = nil, nil
+	}
+	if ref.request != nil && ref.request.Context().Err() == nil {
+		d.Form, d.parseErr = ref.form.parse()
+	}
+	return d
+}
+
+// requestForm guards the form of an attached request, which is parsed by the
+// first report of the request, as reports of the same request may be created
+// concurrently, e.g. by clones on other goroutines. Its lock is held while the
+// request body is read, so a slow upload only delays the reports of its own
+// request.
+type requestForm struct {
+	mu      sync.Mutex
+	request *http.Request
+}
+
+// newRequestForm returns the guard of the form of the given request, or nil if
+// no request is given.
+func newRequestForm(r *http.Request) *requestForm {
+	if r == nil {
+		return nil
+	}
+	return &requestForm{request: r}
+}
+
+// parse parses the form of the request and returns a copy of its body
+// parameters.
+func (f *requestForm) parse() (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.request.ParseForm()
+	return arrayMapToStringMap(f.request.PostForm), err
+}
+
+// parsed returns a copy of the body parameters of the request if its form was
+// parsed already. It does not wait for a report parsing it at the same time.
+func (f *requestForm) parsed() (map[string]string, bool) {
+	if f == nil || !f.mu.TryLock() {
+		return nil, false
+	}
+	defer f.mu.Unlock()
+	if f.request.PostForm == nil {
+		return nil, false
 	}
+	return arrayMapToStringMap(f.request.PostForm), true
 }
 
 // clientData is the struct holding information on this client.
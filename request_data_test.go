@@ -1,7 +1,10 @@
 package raygun4go
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,14 +19,14 @@ func TestRequestData(t *testing.T) {
 		r, _ := http.NewRequest("GET", u, nil)
 
 		Convey("empty if no request given", func() {
-			d := newRequestData(nil)
+			d := newRequestData(nil, nil, WireFormatV1)
 			So(d, ShouldResemble, RequestData{})
 		})
 
 		Convey("basic data", func() {
 			r.RemoteAddr = "1.2.3.4"
 
-			d := newRequestData(r)
+			d := newRequestData(r, nil, WireFormatV1)
 			So(d.HostName, ShouldEqual, "www.example.com")
 			So(d.URL, ShouldEqual, u)
 			So(d.HTTPMethod, ShouldEqual, "GET")
@@ -40,7 +43,7 @@ func TestRequestData(t *testing.T) {
 				"fizz": "[buzz; buzz2]",
 			}
 
-			d := newRequestData(r)
+			d := newRequestData(r, nil, WireFormatV1)
 			So(d.Form, ShouldResemble, expected)
 		})
 
@@ -50,7 +53,7 @@ func TestRequestData(t *testing.T) {
 				"fizz[]": "[buzz; buzz2]",
 			}
 
-			d := newRequestData(r)
+			d := newRequestData(r, nil, WireFormatV1)
 			So(d.QueryString, ShouldResemble, expected)
 		})
 
@@ -64,7 +67,7 @@ func TestRequestData(t *testing.T) {
 				"fizz": "buzz",
 			}
 
-			d := newRequestData(r)
+			d := newRequestData(r, nil, WireFormatV1)
 			So(d.Headers, ShouldResemble, expected)
 		})
 	})
@@ -107,3 +110,83 @@ func Test(t *testing.T) {
 	Convey("", t, func() {
 	})
 }
+
+func TestRequestRef(t *testing.T) {
+	Convey("#RequestRef", t, func() {
+		u := "http://www.example.com?foo=bar"
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r, _ := http.NewRequestWithContext(ctx, "POST", u, nil)
+		r.RemoteAddr = "1.2.3.4"
+		r.Header.Add("foo", "bar")
+		r.PostForm = url.Values{"fizz": []string{"buzz"}}
+
+		c, _ := New("app", "key")
+		c.RequestRef(r)
+		So(c.context.RequestRef, ShouldNotBeNil)
+
+		Convey("copies the immutable parts eagerly", func() {
+			r.URL, _ = url.Parse("http://www.example.org/recycled")
+			r.Header.Set("foo", "changed")
+			cancel()
+
+			d := c.context.RequestRef.requestData(WireFormatV1)
+			So(d.HostName, ShouldEqual, "www.example.com")
+			So(d.URL, ShouldEqual, u)
+			So(d.HTTPMethod, ShouldEqual, "POST")
+			So(d.IPAddress, ShouldEqual, "1.2.3.4")
+			So(d.QueryString, ShouldResemble, map[string]string{"foo": "bar"})
+			So(d.Headers["Foo"], ShouldEqual, "bar")
+		})
+
+		Convey("reads the form while the request is active", func() {
+			d := c.context.RequestRef.requestData(WireFormatV1)
+			So(d.Form, ShouldResemble, map[string]string{"fizz": "buzz"})
+		})
+
+		Convey("leaves the form out once the request is done", func() {
+			cancel()
+			d := c.context.RequestRef.requestData(WireFormatV1)
+			So(d.Form, ShouldBeNil)
+		})
+
+		Convey("is used for reports", func() {
+			cancel()
+			post := c.createPost(errors.New("test error"), StackTrace{})
+			So(post.Details.Request.URL, ShouldEqual, u)
+		})
+
+		Convey("is replaced by Request", func() {
+			c.Request(r)
+			So(c.context.RequestRef, ShouldBeNil)
+			So(c.context.Request, ShouldEqual, r)
+		})
+	})
+}
+
+func TestPostDataEncoding(t *testing.T) {
+	Convey("PostData encodes maps deterministically", t, func() {
+		values := url.Values{}
+		customData := map[string]interface{}{}
+		for i := 0; i < 25; i++ {
+			key := fmt.Sprintf("key%d", i)
+			values.Add(key, "value")
+			customData[key] = map[string]int{key: i}
+		}
+
+		u := "http://www.example.com?" + values.Encode()
+		r, _ := http.NewRequest("POST", u, nil)
+		r.PostForm = values
+		r.Header = http.Header(values)
+
+		c, _ := New("app", "key")
+		c.Request(r).CustomData(customData)
+		post := c.createPost(errors.New("test error"), StackTrace{})
+
+		first, _ := json.MarshalIndent(post, "", "\t")
+		for i := 0; i < 10; i++ {
+			again, _ := json.MarshalIndent(post, "", "\t")
+			So(string(again), ShouldEqual, string(first))
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package raygun4go
+
+// contextSnapshot returns a copy of the context information of the client.
+func (c *Client) contextSnapshot() contextInformation {
+	c.contextMu.RLock()
+	defer c.contextMu.RUnlock()
+	return c.context
+}
+
+// ClearRequest is a chainable option-setting method to remove the request set
+// with Request or RequestRef from the context, so following reports carry no
+// request data.
+func (c *Client) ClearRequest() *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.Request = nil
+	c.context.RequestRef = nil
+	c.context.requestForm = nil
+	c.contextMu.Unlock()
+	return c
+}
+
+// ClearCustomData is a chainable option-setting method to remove the custom
+// data set with CustomData from the context.
+func (c *Client) ClearCustomData() *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.CustomData = nil
+	c.contextMu.Unlock()
+	return c
+}
+
+// ClearTags is a chainable option-setting method to remove the tags set with
+// Tags or TypedTags from the context.
+func (c *Client) ClearTags() *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.Tags = nil
+	c.contextMu.Unlock()
+	return c
+}
+
+// ClearUser is a chainable option-setting method to remove the user set with
+// User from the context.
+func (c *Client) ClearUser() *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.User = ""
+	c.contextMu.Unlock()
+	return c
+}
+
+// ResetContext is a chainable option-setting method to remove the request,
+// custom data, tags and user from the context at once, e.g. between the
+// operations of a long-lived client. The identifier, the version and the
+// custom grouping key function are kept, as is the configuration of the
+// client.
+func (c *Client) ResetContext() *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context = contextInformation{
+		Version:              c.context.Version,
+		GetCustomGroupingKey: c.context.GetCustomGroupingKey,
+		identifier:           c.context.identifier,
+	}
+	c.contextMu.Unlock()
+	return c
+}
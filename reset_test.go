@@ -0,0 +1,87 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResetContext(t *testing.T) {
+	Convey("Clearing the context", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Version("1.2.3").
+			Request(httptest.NewRequest("GET", "http://www.example.com/old", nil)).
+			CustomData(map[string]interface{}{"order": 42}).
+			Tags([]string{"batch"}).
+			User("alice")
+		send := func() PostData {
+			c.SendError(errors.New("Test ResetContext"))
+			post, _ := server.next()
+			return post
+		}
+
+		Convey("#ClearRequest removes the request", func() {
+			So(send().Details.Request.URL, ShouldEqual, "http://www.example.com/old")
+			c.ClearRequest()
+			post := send()
+			So(post.Details.Request, ShouldResemble, RequestData{})
+			So(post.Details.User.Identifier, ShouldEqual, "alice")
+		})
+
+		Convey("#ClearRequest removes requests set by RequestRef", func() {
+			c.RequestRef(httptest.NewRequest("GET", "http://www.example.com/ref", nil)).ClearRequest()
+			So(send().Details.Request, ShouldResemble, RequestData{})
+		})
+
+		Convey("#ClearCustomData removes the custom data", func() {
+			c.ClearCustomData()
+			post := send()
+			So(withoutReportID(post), ShouldBeNil)
+			So(post.Details.Tags, ShouldResemble, []string{"batch"})
+		})
+
+		Convey("#ClearTags removes the tags", func() {
+			c.ClearTags()
+			So(send().Details.Tags, ShouldBeEmpty)
+		})
+
+		Convey("#ClearUser removes the user", func() {
+			c.ClearUser()
+			So(send().Details.User.Identifier, ShouldEqual, "")
+		})
+
+		Convey("#ResetContext removes everything but the identifier and version", func() {
+			identifier := c.context.Identifier()
+			c.ResetContext()
+			post := send()
+			So(post.Details.Request, ShouldResemble, RequestData{})
+			So(withoutReportID(post), ShouldBeNil)
+			So(post.Details.Tags, ShouldBeEmpty)
+			So(post.Details.User.Identifier, ShouldEqual, "")
+			So(post.Details.Version, ShouldEqual, "1.2.3")
+			So(post.Details.Context.Identifier, ShouldEqual, identifier)
+		})
+
+		Convey("is safe while reports are created", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					c.ResetContext().Tags([]string{"batch"}).User("alice")
+				}()
+				go func() {
+					defer wg.Done()
+					c.Clone().createPost(errors.New("Test ResetContext"), StackTrace{})
+				}()
+			}
+			wg.Wait()
+		})
+	})
+}
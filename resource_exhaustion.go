@@ -0,0 +1,125 @@
+package raygun4go
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// fileDescriptorsKey is the custom data key the file descriptor snapshot
+	// of reports on exhausted file descriptors is stored under.
+	fileDescriptorsKey = "fileDescriptors"
+
+	// resourceExhaustionTag is the tag added to reports on exhausted file
+	// descriptors.
+	resourceExhaustionTag = "resource-exhaustion"
+
+	// maxCountedFileDescriptors is the number of open file descriptors
+	// counted at most when the count is not available cheaply.
+	maxCountedFileDescriptors = 1024
+)
+
+// exhaustionMessages are the messages of errors caused by exhausted file
+// descriptors, for errors not wrapping the errno.
+var exhaustionMessages = []string{
+	"too many open files",
+	"file table overflow",
+}
+
+// fdDir is the directory listing the open file descriptors of the process.
+const fdDir = "/proc/self/fd"
+
+// probeFileDescriptors returns the file descriptor snapshot of the process
+// and whether any of it could be determined, it is replaced in tests.
+var probeFileDescriptors = newFileDescriptorSnapshot
+
+// fileDescriptorSnapshot is the file descriptor information added to reports
+// on exhausted file descriptors.
+type fileDescriptorSnapshot struct {
+	Open        int    `json:"open,omitempty"`        // the number of open file descriptors, if countable
+	OpenAtLeast bool   `json:"openAtLeast,omitempty"` // if true, counting stopped early and more are open
+	SoftLimit   uint64 `json:"softLimit,omitempty"`   // the soft RLIMIT_NOFILE, if any
+	HardLimit   uint64 `json:"hardLimit,omitempty"`   // the hard RLIMIT_NOFILE, if any
+}
+
+// isResourceExhaustion reports whether err was caused by exhausted file
+// descriptors: it wraps EMFILE or ENFILE, where the platform has them, or
+// its message says so.
+func isResourceExhaustion(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, errno := range exhaustionErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range exhaustionMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// addResourceExhaustion adds the file descriptor snapshot to the given
+// report options and returns the resource-exhaustion tag if err was caused
+// by exhausted file descriptors.
+func addResourceExhaustion(err error, opts *reportOptions) []string {
+	if !isResourceExhaustion(err) {
+		return nil
+	}
+	if snapshot, ok := probeFileDescriptors(); ok {
+		opts.addCustomData(fileDescriptorsKey, snapshot)
+	}
+	return []string{resourceExhaustionTag}
+}
+
+// newFileDescriptorSnapshot counts the open file descriptors and reads their
+// limit. Either is left out where the platform does not provide it.
+func newFileDescriptorSnapshot() (fileDescriptorSnapshot, bool) {
+	var s fileDescriptorSnapshot
+	open, atLeast, countOK := countFileDescriptors()
+	if countOK {
+		s.Open, s.OpenAtLeast = open, atLeast
+	}
+	soft, hard, limitOK := fileDescriptorLimit()
+	if limitOK {
+		s.SoftLimit, s.HardLimit = soft, hard
+	}
+	return s, countOK || limitOK
+}
+
+// countFileDescriptors returns the number of open file descriptors listed in
+// fdDir, and whether counting stopped at maxCountedFileDescriptors. Recent
+// Linux kernels report the number as the size of the directory, which needs
+// no file descriptor; otherwise the entries are counted. It returns false if
+// the directory does not exist, e.g. outside of Linux, or cannot be read,
+// which is likely with no file descriptor left.
+func countFileDescriptors() (int, bool, bool) {
+	info, err := os.Stat(fdDir)
+	if err != nil {
+		return 0, false, false
+	}
+	if info.Size() > 0 {
+		return int(info.Size()), false, true
+	}
+
+	dir, err := os.Open(fdDir)
+	if err != nil {
+		return 0, false, false
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(maxCountedFileDescriptors)
+	if err != nil && err != io.EOF {
+		return 0, false, false
+	}
+	if len(names) < maxCountedFileDescriptors {
+		// The directory itself holds one of the listed descriptors.
+		return len(names) - 1, false, true
+	}
+	return len(names), true, true
+}
@@ -0,0 +1,13 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package raygun4go
+
+// exhaustionErrnos are the errors of exhausted file descriptors. Other
+// platforms are covered by matching the message only.
+var exhaustionErrnos []error
+
+// fileDescriptorLimit returns false, as the limit on open file descriptors
+// cannot be read on this platform.
+func fileDescriptorLimit() (soft, hard uint64, ok bool) {
+	return 0, 0, false
+}
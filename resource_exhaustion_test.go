@@ -0,0 +1,82 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResourceExhaustion(t *testing.T) {
+	Convey("Resource exhaustion", t, func() {
+		defaultProbe := probeFileDescriptors
+		Reset(func() { probeFileDescriptors = defaultProbe })
+		probes := 0
+		probeFileDescriptors = func() (fileDescriptorSnapshot, bool) {
+			probes++
+			return fileDescriptorSnapshot{Open: 1021, SoftLimit: 1024, HardLimit: 4096}, true
+		}
+
+		c, _ := New("app", "key")
+
+		Convey("is detected by the message", func() {
+			So(isResourceExhaustion(errors.New("accept tcp [::]:8080: accept4: too many open files")), ShouldBeTrue)
+			So(isResourceExhaustion(errors.New("open /tmp/x: Too many open files in system")), ShouldBeTrue)
+			So(isResourceExhaustion(errors.New("file table overflow")), ShouldBeTrue)
+			So(isResourceExhaustion(errors.New("connection refused")), ShouldBeFalse)
+			So(isResourceExhaustion(nil), ShouldBeFalse)
+		})
+
+		Convey("is detected by the errno", func() {
+			if len(exhaustionErrnos) == 0 {
+				return
+			}
+			err := &os.SyscallError{Syscall: "accept4", Err: exhaustionErrnos[0]}
+			So(isResourceExhaustion(fmt.Errorf("serve: %w", err)), ShouldBeTrue)
+		})
+
+		Convey("adds the file descriptor snapshot and the tag to matching reports", func() {
+			post := c.createPost(fmt.Errorf("open config: %w", errors.New("too many open files")), StackTrace{})
+			So(post.Details.Tags, ShouldContain, resourceExhaustionTag)
+			custom := post.Details.UserCustomData.(map[string]interface{})
+			So(custom[fileDescriptorsKey], ShouldResemble, fileDescriptorSnapshot{Open: 1021, SoftLimit: 1024, HardLimit: 4096})
+			So(probes, ShouldEqual, 1)
+		})
+
+		Convey("adds the tag only if the probe finds nothing", func() {
+			probeFileDescriptors = func() (fileDescriptorSnapshot, bool) { return fileDescriptorSnapshot{}, false }
+			post := c.createPost(errors.New("too many open files"), StackTrace{})
+			So(post.Details.Tags, ShouldContain, resourceExhaustionTag)
+			So(post.Details.UserCustomData, ShouldNotContainKey, fileDescriptorsKey)
+		})
+
+		Convey("leaves other reports alone", func() {
+			post := c.createPost(errors.New("connection refused"), StackTrace{})
+			So(post.Details.Tags, ShouldNotContain, resourceExhaustionTag)
+			So(post.Details.UserCustomData, ShouldNotContainKey, fileDescriptorsKey)
+			So(probes, ShouldEqual, 0)
+		})
+
+		Convey("enriches panic reports", func() {
+			c.Silent(true)
+			func() {
+				defer c.HandleError()
+				panic(errors.New("pipe: too many open files"))
+			}()
+			So(probes, ShouldEqual, 1)
+		})
+	})
+
+	Convey("newFileDescriptorSnapshot", t, func() {
+		snapshot, ok := newFileDescriptorSnapshot()
+		if runtime.GOOS != "linux" {
+			return
+		}
+		So(ok, ShouldBeTrue)
+		So(snapshot.Open, ShouldBeGreaterThan, 0)
+		So(snapshot.SoftLimit, ShouldBeGreaterThanOrEqualTo, uint64(snapshot.Open))
+	})
+}
@@ -0,0 +1,26 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package raygun4go
+
+import "syscall"
+
+// exhaustionErrnos are the errors of exhausted file descriptors.
+var exhaustionErrnos = []error{syscall.EMFILE, syscall.ENFILE}
+
+// fileDescriptorLimit returns the soft and hard limit on open file
+// descriptors, 0 if unlimited. It returns false if they cannot be read.
+func fileDescriptorLimit() (soft, hard uint64, ok bool) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, 0, false
+	}
+	return finiteLimit(uint64(limit.Cur)), finiteLimit(uint64(limit.Max)), true
+}
+
+// finiteLimit returns the given rlimit, or 0 if it is unlimited.
+func finiteLimit(v uint64) uint64 {
+	if v >= 1<<62 {
+		return 0
+	}
+	return v
+}
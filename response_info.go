@@ -0,0 +1,83 @@
+package raygun4go
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ResponseInfo describes the answer of Raygun to a request posting a report,
+// see OnResponse.
+type ResponseInfo struct {
+	ReportID   string        // the id of the report, see PostData.ReportID
+	StatusCode int           // the status of the response, 0 if none was received
+	Header     http.Header   // the rate limit headers, Retry-After and X-Request-Id of the response, if any
+	Latency    time.Duration // the time from sending the request to receiving the response headers or failing
+	Err        error         // the error the request failed with, nil if the report was accepted
+}
+
+// responseHeaderPrefix is the prefix of the rate limit headers passed to the
+// OnResponse hook.
+const responseHeaderPrefix = "X-Ratelimit-"
+
+// responseHeaders are the headers passed to the OnResponse hook besides the
+// rate limit headers.
+var responseHeaders = []string{"Retry-After", "X-Request-Id"}
+
+// OnResponse is a chainable option-setting method to set a function called
+// after every request posting a report to Raygun, including retries, in
+// synchronous and asynchronous mode, e.g. to feed the remaining rate limit
+// into a dashboard. It is called on its own goroutine, so it can neither
+// delay nor break submissions; it may thus run concurrently with itself and
+// after the sending method returned. Panics in it are recovered and logged
+// if LogToStdOut is set. Reports delivered with another Transport do not
+// call it. Passing nil removes the function.
+func (c *Client) OnResponse(fn func(ResponseInfo)) *Client {
+	if c == nil {
+		return nil
+	}
+	c.onResponse = fn
+	return c
+}
+
+// notifyResponse passes the outcome of a request posting the given payload,
+// sent at the given time, to the OnResponse hook, if any. resp is nil if the
+// request failed without a response.
+func (c *Client) notifyResponse(p payload, sent time.Time, resp *http.Response, err error) {
+	fn := c.onResponse
+	if fn == nil {
+		return
+	}
+
+	info := ResponseInfo{ReportID: p.reportID, Latency: time.Since(sent), Err: err}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+		info.Header = selectResponseHeaders(resp.Header)
+	}
+
+	logging, logger := c.logging(), c.output()
+	go func() {
+		defer func() {
+			if e := recover(); e != nil && logging {
+				logger.Errorf("Recovered panic in OnResponse hook: %v", e)
+			}
+		}()
+		fn(info)
+	}()
+}
+
+// selectResponseHeaders returns the headers of the given response passed to
+// the OnResponse hook, or nil if there are none.
+func selectResponseHeaders(header http.Header) http.Header {
+	var selected http.Header
+	for name, values := range header {
+		if !strings.HasPrefix(name, responseHeaderPrefix) && !containsFold(responseHeaders, name) {
+			continue
+		}
+		if selected == nil {
+			selected = make(http.Header)
+		}
+		selected[name] = append([]string(nil), values...)
+	}
+	return selected
+}
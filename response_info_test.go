@@ -0,0 +1,124 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOnResponse(t *testing.T) {
+	Convey("OnResponse", t, func() {
+		status := http.StatusAccepted
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "41")
+			w.Header().Set("X-RateLimit-Limit", "50")
+			w.Header().Set("X-Request-Id", "req-1")
+			w.Header().Set("Server", "raygun")
+			w.WriteHeader(status)
+		}))
+		Reset(server.Close)
+
+		infos := make(chan ResponseInfo, 10)
+		next := func() ResponseInfo {
+			select {
+			case info := <-infos:
+				return info
+			case <-time.After(5 * time.Second):
+				return ResponseInfo{}
+			}
+		}
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).OnResponse(func(info ResponseInfo) { infos <- info })
+
+		Convey("receives the status, headers and latency of accepted reports", func() {
+			post := c.createPost(errors.New("Test OnResponse"), StackTrace{})
+			So(c.Submit(post), ShouldBeNil)
+			info := next()
+			So(info.ReportID, ShouldEqual, post.ReportID())
+			So(info.StatusCode, ShouldEqual, http.StatusAccepted)
+			So(info.Header, ShouldResemble, http.Header{
+				"X-Ratelimit-Remaining": {"41"},
+				"X-Ratelimit-Limit":     {"50"},
+				"X-Request-Id":          {"req-1"},
+			})
+			So(info.Latency, ShouldBeGreaterThan, 0)
+			So(info.Err, ShouldBeNil)
+		})
+
+		Convey("receives failed submissions", func() {
+			status = http.StatusBadRequest
+			So(c.SendError(errors.New("Test OnResponse")), ShouldNotBeNil)
+			info := next()
+			So(info.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(errors.Is(info.Err, ErrSubmission), ShouldBeTrue)
+		})
+
+		Convey("receives submissions without a response", func() {
+			server.Close()
+			So(c.SendError(errors.New("Test OnResponse")), ShouldNotBeNil)
+			info := next()
+			So(info.StatusCode, ShouldEqual, 0)
+			So(info.Header, ShouldBeNil)
+			So(info.Err, ShouldNotBeNil)
+		})
+
+		Convey("is called in asynchronous mode and by clones", func() {
+			c.Asynchronous(true)
+			So(c.Clone().SendError(errors.New("Test OnResponse")), ShouldBeNil)
+			So(next().StatusCode, ShouldEqual, http.StatusAccepted)
+			So(c.Close(), ShouldBeNil)
+		})
+
+		Convey("does not block or break submissions", func() {
+			release := make(chan struct{})
+			Reset(func() { close(release) })
+			c.OnResponse(func(ResponseInfo) {
+				<-release
+				panic("Test OnResponse")
+			})
+			done := make(chan error, 1)
+			go func() { done <- c.SendError(errors.New("Test OnResponse")) }()
+			select {
+			case err := <-done:
+				So(err, ShouldBeNil)
+			case <-time.After(5 * time.Second):
+				So("submission blocked", ShouldBeEmpty)
+			}
+		})
+
+		Convey("is not called without requests", func() {
+			c.OnResponse(nil)
+			So(c.SendError(errors.New("Test OnResponse")), ShouldBeNil)
+			c.Transport(NewRecordingTransport()).OnResponse(func(info ResponseInfo) { infos <- info })
+			So(c.SendError(errors.New("Test OnResponse")), ShouldBeNil)
+			So(infos, ShouldBeEmpty)
+		})
+	})
+
+	Convey("OnResponse with retries", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+		infos := make(chan ResponseInfo, 10)
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Retries(1, time.Millisecond).OnResponse(func(info ResponseInfo) { infos <- info })
+
+		server.Script(rayguntest.TooManyRequests("30"))
+		So(c.SendError(errors.New("Test OnResponse")), ShouldNotBeNil)
+		info := <-infos
+		So(info.StatusCode, ShouldEqual, http.StatusTooManyRequests)
+		So(info.Header.Get("Retry-After"), ShouldEqual, "30")
+		So(errors.Is(info.Err, ErrRateLimited), ShouldBeTrue)
+
+		server.Script(rayguntest.Status(http.StatusBadGateway))
+		c.clock = func() time.Time { return time.Now().Add(time.Minute) }
+		So(c.SendError(errors.New("Test OnResponse")), ShouldBeNil)
+		So((<-infos).StatusCode, ShouldEqual, http.StatusBadGateway)
+		So((<-infos).StatusCode, ShouldEqual, http.StatusAccepted)
+	})
+}
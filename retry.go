@@ -0,0 +1,74 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Retries is a chainable option-setting method to retry submissions failing
+// because of a network error or a 5xx response up to max times. The n-th
+// retry waits a random delay between half of and the full baseDelay times
+// 2^(n-1). Other 4xx responses are not retried, as they mean the report or
+// the API key is invalid; 429 responses are handled as described for
+// RateLimitError. All attempts of a submission count towards the Timeout; in
+// asynchronous mode they are made by the background worker. By default,
+// submissions are not retried.
+func (c *Client) Retries(max int, baseDelay time.Duration) *Client {
+	if c == nil {
+		return nil
+	}
+	if max < 0 {
+		max = 0
+	}
+	c.maxRetries = max
+	c.retryBaseDelay = baseDelay
+	return c
+}
+
+// postWithRetries posts the given payload, retrying transient failures as
+// configured by Retries until ctx is done.
+func (c *Client) postWithRetries(ctx context.Context, p payload) error {
+	for attempt := 0; ; attempt++ {
+		err := c.postPayload(ctx, p)
+		if err == nil || attempt == c.maxRetries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(c.retryBaseDelay, attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// retryable reports whether a submission failing with err may succeed when
+// repeated.
+func retryable(err error) bool {
+	var submitErr *SubmitError
+	if !errors.As(err, &submitErr) {
+		return false
+	}
+	if submitErr.StatusCode != 0 {
+		return submitErr.StatusCode >= 500
+	}
+	return !submitErr.Canceled && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoff returns the delay before the retry following the given attempt,
+// counted from 0: a random duration between half of and the full base delay
+// times 2^attempt.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if attempt > 30 {
+		attempt = 30
+	}
+	delay := base << uint(attempt)
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,81 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetries(t *testing.T) {
+	Convey("Retries", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Retries(3, time.Millisecond)
+
+		Convey("repeat transient failures until the report lands once", func() {
+			server.Script(rayguntest.Status(http.StatusBadGateway), rayguntest.ConnectionReset, rayguntest.Status(http.StatusGatewayTimeout))
+			So(c.SendError(errors.New("Test Retries")), ShouldBeNil)
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 4)
+			keys := map[string]bool{}
+			for _, r := range requests {
+				keys[r.Header.Get(idempotencyKeyHeader)] = true
+			}
+			So(keys, ShouldHaveLength, 1)
+		})
+
+		Convey("give up after the maximum", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			err := c.SendError(errors.New("Test Retries"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Unexpected answer from Raygun 503")
+			So(server.Count(), ShouldEqual, 4)
+		})
+
+		Convey("skip client errors", func() {
+			server.Script(rayguntest.BadRequest("invalid report"))
+			So(c.SendError(errors.New("Test Retries")), ShouldNotBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("are off by default", func() {
+			c.Retries(0, 0)
+			server.Script(rayguntest.Status(http.StatusBadGateway))
+			So(c.SendError(errors.New("Test Retries")), ShouldNotBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("respect the timeout", func() {
+			c.Retries(10, time.Second).Timeout(100 * time.Millisecond)
+			server.Script(rayguntest.Status(http.StatusServiceUnavailable), rayguntest.Status(http.StatusServiceUnavailable))
+			start := time.Now()
+			So(c.SendError(errors.New("Test Retries")), ShouldNotBeNil)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		})
+
+		Convey("happen in the background in asynchronous mode", func() {
+			c.Asynchronous(true)
+			server.Script(rayguntest.Status(http.StatusBadGateway), rayguntest.Status(http.StatusServiceUnavailable))
+			So(c.SendError(errors.New("Test Retries")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 3)
+		})
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	Convey("backoff", t, func() {
+		for attempt := 0; attempt < 5; attempt++ {
+			full := 10 * time.Millisecond << uint(attempt)
+			delay := backoff(10*time.Millisecond, attempt)
+			So(delay, ShouldBeBetweenOrEqual, full/2, full)
+		}
+		So(backoff(0, 3), ShouldEqual, 0)
+	})
+}
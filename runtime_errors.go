@@ -0,0 +1,61 @@
+package raygun4go
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// locatedRuntimeErrors are the messages of recoverable runtime panics that
+// give no clue on where they occurred. Distinct bugs causing them would all be
+// grouped together in Raygun, so their location is added to the report.
+var locatedRuntimeErrors = []string{
+	"assignment to entry in nil map",
+}
+
+// locateRuntimeError appends the location of the top in-app frame to runtime
+// errors listed in locatedRuntimeErrors and returns a grouping key derived
+// from the resulting message. Other errors are returned unchanged together
+// with an empty grouping key.
+func locateRuntimeError(err error, stack StackTrace) (error, string) {
+	if _, ok := err.(runtime.Error); !ok {
+		return err, ""
+	}
+
+	located := false
+	for _, msg := range locatedRuntimeErrors {
+		if strings.Contains(err.Error(), msg) {
+			located = true
+			break
+		}
+	}
+	if !located {
+		return err, ""
+	}
+
+	frame, ok := topInAppFrame(stack)
+	if !ok {
+		return err, ""
+	}
+
+	err = fmt.Errorf("%w at %s:%d", err, frame.FileName, frame.LineNumber)
+	return err, fmt.Sprintf("%x", sha1.Sum([]byte(err.Error())))
+}
+
+// topInAppFrame returns the first frame of the given stack that belongs
+// neither to the go runtime nor to the panic machinery.
+func topInAppFrame(stack StackTrace) (StackTraceElement, bool) {
+	for _, frame := range stack {
+		if inAppFrame(frame) {
+			return frame, true
+		}
+	}
+	return StackTraceElement{}, false
+}
+
+// inAppFrame reports whether the given frame belongs neither to the go
+// runtime nor to the panic machinery, nor stands for collapsed frames.
+func inAppFrame(frame StackTraceElement) bool {
+	return frame.PackageName != "" && frame.PackageName != "runtime" && !strings.HasPrefix(frame.PackageName, "runtime/")
+}
@@ -0,0 +1,68 @@
+package raygun4go
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//go:noinline
+func writeToNilMapA(c *Client) {
+	defer c.HandleError()
+	var m map[string]int
+	m["a"] = 1
+}
+
+//go:noinline
+func writeToNilMapB(c *Client) {
+	defer c.HandleError()
+	var m map[string]string
+	m["b"] = "b"
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	Convey("Runtime errors", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+
+		Convey("nil map writes are located", func() {
+			writeToNilMapA(c)
+			writeToNilMapB(c)
+
+			first, ok := server.next()
+			So(ok, ShouldBeTrue)
+			second, ok := server.next()
+			So(ok, ShouldBeTrue)
+
+			So(first.Details.Error.Message, ShouldStartWith, "assignment to entry in nil map at runtime_errors_test.go:")
+			So(second.Details.Error.Message, ShouldStartWith, "assignment to entry in nil map at runtime_errors_test.go:")
+			So(first.Details.Error.Message, ShouldNotEqual, second.Details.Error.Message)
+
+			So(first.Details.GroupingKey, ShouldNotBeNil)
+			So(second.Details.GroupingKey, ShouldNotBeNil)
+			So(*first.Details.GroupingKey, ShouldNotEqual, *second.Details.GroupingKey)
+		})
+
+		Convey("a custom grouping key takes precedence", func() {
+			c.CustomGroupingKeyFunction(func(error, PostData) string { return "customGroupingKey" })
+			writeToNilMapA(c)
+
+			post, _ := server.next()
+			So(*post.Details.GroupingKey, ShouldEqual, "customGroupingKey")
+			So(post.Details.Error.Message, ShouldStartWith, "assignment to entry in nil map at ")
+		})
+
+		Convey("other panics are unchanged", func() {
+			func() {
+				defer c.HandleError()
+				panic("Test other panic")
+			}()
+
+			post, _ := server.next()
+			So(post.Details.Error.Message, ShouldEqual, "Test other panic")
+			So(post.Details.GroupingKey, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package raygun4go
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// sampler keeps a random fraction of the reports, see SampleRate.
+type sampler struct {
+	fraction float64        // the fraction of reports kept
+	random   func() float64 // returns a random number in [0, 1), replaced in tests
+}
+
+// SampleRate is a chainable option-setting method to send only the given
+// fraction of the reports, e.g. 0.1 to keep one in ten. The other reports
+// are counted by Stats as sampled and their sending methods return nil.
+// Recovered panics are always sent unless SamplePanics is set. With
+// Deduplicate, all reports of an error within a window are either kept or
+// sampled alike, and the decision changes with every window. A fraction of 1
+// or more, the default, sends all reports.
+func (c *Client) SampleRate(fraction float64) *Client {
+	if c == nil {
+		return nil
+	}
+	c.sampler = nil
+	if fraction < 1 {
+		c.sampler = &sampler{fraction: math.Max(0, fraction), random: rand.Float64}
+	}
+	return c
+}
+
+// SamplePanics is a chainable option-setting method to apply SampleRate to
+// recovered panics as well, which are always sent by default.
+func (c *Client) SamplePanics(sample bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.samplePanics = sample
+	return c
+}
+
+// sampledOut reports whether the given post is left out by sampling, and
+// counts it if so. panicked is set for the reports of recovered panics.
+func (c *Client) sampledOut(post PostData, panicked bool) bool {
+	s := c.sampler
+	if s == nil || (panicked && !c.samplePanics) {
+		return false
+	}
+
+	var x float64
+	if c.dedup != nil {
+		x = c.dedup.sample(post)
+	} else {
+		x = s.random()
+	}
+	if x < s.fraction {
+		return false
+	}
+	c.stats.sample()
+	return true
+}
+
+// sample returns a number in [0, 1) that is the same for all posts of an
+// error within a window, and differs between windows.
+func (d *deduplicator) sample(post PostData) float64 {
+	d.mu.Lock()
+	fingerprint := d.fingerprint(post)
+	window := d.now().Truncate(d.window).UnixNano()
+	d.mu.Unlock()
+
+	h := sha1.Sum([]byte(fmt.Sprintf("%s %d", fingerprint, window)))
+	return float64(binary.BigEndian.Uint64(h[:8])>>11) / (1 << 53)
+}
@@ -0,0 +1,101 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSampleRate(t *testing.T) {
+	Convey("#SampleRate", t, func() {
+		transport := NewRecordingTransport()
+		c, _ := New("app", "key")
+		c.Transport(transport).SampleRate(0.1)
+		c.sampler.random = rand.New(rand.NewSource(1)).Float64
+		send := func(n int, message string) {
+			for i := 0; i < n; i++ {
+				So(c.SendError(errors.New(message)), ShouldBeNil)
+			}
+		}
+		panicked := func() {
+			defer c.HandleError()
+			panic("Test SampleRate")
+		}
+
+		Convey("keeps the given fraction of the reports", func() {
+			send(1000, "Test SampleRate")
+			sent := len(transport.Posts())
+			So(sent, ShouldBeBetween, 70, 130)
+			stats := c.Stats()
+			So(stats.Sampled, ShouldEqual, 1000-sent)
+			So(stats.Submitted, ShouldEqual, sent)
+		})
+
+		Convey("sends all panics by default", func() {
+			c.SampleRate(0)
+			for i := 0; i < 10; i++ {
+				panicked()
+			}
+			So(transport.Posts(), ShouldHaveLength, 10)
+
+			Convey("unless SamplePanics is set", func() {
+				c.SamplePanics(true)
+				panicked()
+				So(transport.Posts(), ShouldHaveLength, 10)
+				So(c.Stats().Sampled, ShouldEqual, 1)
+			})
+		})
+
+		Convey("decides per error and window with Deduplicate", func() {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			c.clock = func() time.Time { return now }
+			c.SampleRate(0.5).Deduplicate(time.Minute)
+
+			kept := 0
+			for i := 0; i < 100; i++ {
+				message := fmt.Sprintf("Test SampleRate %d", i)
+				first := c.sampledOut(c.createPost(errors.New(message), StackTrace{}), false)
+				for j := 0; j < 5; j++ {
+					So(c.sampledOut(c.createPost(errors.New(message), StackTrace{}), false), ShouldEqual, first)
+				}
+				if !first {
+					kept++
+				}
+			}
+			So(kept, ShouldBeBetween, 30, 70)
+
+			changed := 0
+			for i := 0; i < 100; i++ {
+				post := c.createPost(errors.New("Test SampleRate"), StackTrace{})
+				before := c.sampledOut(post, false)
+				now = now.Add(time.Minute)
+				if c.sampledOut(post, false) != before {
+					changed++
+				}
+			}
+			So(changed, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("is disabled by a fraction of 1", func() {
+			c.SampleRate(1)
+			send(10, "Test SampleRate")
+			So(transport.Posts(), ShouldHaveLength, 10)
+			So(c.Stats().Sampled, ShouldEqual, 0)
+		})
+
+		Convey("is kept by clones", func() {
+			So(c.SamplePanics(true).Clone().sampler, ShouldEqual, c.sampler)
+			So(c.Clone().samplePanics, ShouldBeTrue)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.SampleRate(0.5), ShouldBeNil)
+			So(nilClient.SamplePanics(true), ShouldBeNil)
+		})
+	})
+}
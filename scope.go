@@ -0,0 +1,97 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// scopeSnapshotVersion is the version of the format written by Export.
+const scopeSnapshotVersion = 1
+
+// scopeSnapshot is the JSON encoding of the context of a client written by
+// Export.
+type scopeSnapshot struct {
+	Format      int                 `json:"format"`
+	Version     string              `json:"version,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	CustomData  interface{}         `json:"customData,omitempty"`
+	User        string              `json:"user,omitempty"`
+	Request     *RequestData        `json:"request,omitempty"`
+	QueryValues map[string][]string `json:"queryValues,omitempty"`
+	Cookies     map[string]string   `json:"cookies,omitempty"`
+	Breadcrumbs []Breadcrumb        `json:"breadcrumbs,omitempty"`
+}
+
+// Export returns a snapshot of the context of the client, i.e. its version,
+// tags, custom data, user, request and breadcrumbs, to be passed to
+// ImportScope in another process. The request data is copied with the
+// client's redaction rules applied; its form is only included if it was
+// parsed already. The custom data must be convertible to JSON and is
+// imported as generic maps and slices.
+func (c *Client) Export() ([]byte, error) {
+	if c == nil {
+		return nil, ErrNoClient
+	}
+
+	context := c.contextSnapshot()
+	snapshot := scopeSnapshot{
+		Format:      scopeSnapshotVersion,
+		Version:     context.Version,
+		Tags:        context.Tags,
+		CustomData:  context.CustomData,
+		User:        context.User,
+		Breadcrumbs: c.breadcrumbs.snapshot(),
+	}
+
+	if ref := detachedRequest(context); ref != nil && !c.noRequestData {
+		request := ref.data
+		c.redaction.redactRequest(&request)
+		snapshot.Request = &request
+		snapshot.QueryValues = request.queryValues
+		snapshot.Cookies = request.cookies
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, newKindError(ErrMarshalFailure, err, "Unable to convert to JSON (%s)", err.Error())
+	}
+	return data, nil
+}
+
+// ImportScope returns a clone of the client with the context exported by
+// Export, e.g. to report errors of work that originated from a request
+// handled by another process. The exported version, tags, custom data, user
+// and request replace those of the client; the exported breadcrumbs are
+// added to its own.
+func (c *Client) ImportScope(data []byte) (*Client, error) {
+	if c == nil {
+		return nil, ErrNoClient
+	}
+
+	var snapshot scopeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("Unable to read scope snapshot (%w)", err)
+	}
+	if snapshot.Format != scopeSnapshotVersion {
+		return nil, fmt.Errorf("Unsupported scope snapshot format %d", snapshot.Format)
+	}
+
+	scope := c.Clone()
+	scope.context.Version = snapshot.Version
+	scope.context.Tags = snapshot.Tags
+	scope.context.CustomData = snapshot.CustomData
+	scope.context.User = snapshot.User
+	scope.context.Request = nil
+	scope.context.RequestRef = nil
+	scope.context.requestForm = nil
+	if snapshot.Request != nil {
+		request := *snapshot.Request
+		request.queryValues = snapshot.QueryValues
+		request.cookies = snapshot.Cookies
+		scope.context.RequestRef = &requestRef{data: request}
+	}
+	for _, b := range snapshot.Breadcrumbs {
+		scope.breadcrumbs.record(b)
+	}
+	return scope, nil
+}
@@ -0,0 +1,52 @@
+package raygun4go
+
+// AddTags is a chainable option-setting method to add the given tags to
+// those of the context. Unlike Tags, it can be called from several
+// goroutines sharing one client, e.g. the scoped client of a request, while
+// reports are created.
+func (c *Client) AddTags(tags ...string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	c.context.Tags = append(copyStrings(c.context.Tags), tags...)
+	c.contextMu.Unlock()
+	return c
+}
+
+// SetCustomDataKey is a chainable option-setting method to set a single
+// entry of the custom data of the context. Custom data set before that is not
+// a map with string keys is kept under "value". Like AddTags, it can be
+// called from several goroutines sharing one client while reports are
+// created.
+func (c *Client) SetCustomDataKey(key string, value interface{}) *Client {
+	if c == nil {
+		return nil
+	}
+	c.contextMu.Lock()
+	data := mergeCustomData(c.context.CustomData, map[string]interface{}{key: value})
+	data.(map[string]interface{})[key] = value
+	c.context.CustomData = data
+	c.contextMu.Unlock()
+	return c
+}
+
+// scopeState is a consistent snapshot of what a client attaches to reports.
+type scopeState struct {
+	context        contextInformation
+	breadcrumbs    []Breadcrumb
+	breadcrumbTags []string
+}
+
+// scopeSnapshot returns a consistent snapshot of the context and breadcrumbs
+// of the client: a breadcrumb is only included along with the context
+// changes made before it was recorded.
+func (c *Client) scopeSnapshot() scopeState {
+	c.contextMu.RLock()
+	defer c.contextMu.RUnlock()
+	return scopeState{
+		context:        c.context,
+		breadcrumbs:    c.breadcrumbs.snapshot(),
+		breadcrumbTags: c.breadcrumbs.tagSnapshot(),
+	}
+}
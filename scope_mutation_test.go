@@ -0,0 +1,75 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScopeMutation(t *testing.T) {
+	Convey("Scope mutation", t, func() {
+		c, _ := New("app", "key")
+
+		Convey("AddTags adds to the tags", func() {
+			c.Tags([]string{"a"}).AddTags("b", "c")
+			So(c.createPost(errors.New("Test AddTags"), StackTrace{}).Details.Tags, ShouldResemble, []string{"a", "b", "c"})
+		})
+
+		Convey("SetCustomDataKey sets a single entry", func() {
+			c.CustomData(map[string]interface{}{"a": 1, "b": 2}).SetCustomDataKey("b", 3)
+			So(withoutReportID(c.createPost(errors.New("Test SetCustomDataKey"), StackTrace{})), ShouldResemble, map[string]interface{}{"a": 1, "b": 3})
+		})
+
+		Convey("SetCustomDataKey keeps other custom data under value", func() {
+			c.CustomData("text").SetCustomDataKey("b", 3)
+			So(withoutReportID(c.createPost(errors.New("Test SetCustomDataKey"), StackTrace{})), ShouldResemble, map[string]interface{}{"value": "text", "b": 3})
+		})
+
+		Convey("is safe from several goroutines while reports are created", func() {
+			scope := c.Clone().MaxTags(200)
+			var wg sync.WaitGroup
+			posts := make(chan PostData, 100)
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 20; i++ {
+						step := fmt.Sprintf("%d-%d", g, i)
+						scope.AddTags("tag-"+step).SetCustomDataKey("key-"+step, i)
+						scope.RecordBreadcrumb(Breadcrumb{Message: step})
+					}
+				}(g)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					posts <- scope.createPost(errors.New("Test Scope"), StackTrace{})
+				}
+			}()
+			wg.Wait()
+			close(posts)
+
+			for post := range posts {
+				tags := map[string]bool{}
+				for _, tag := range post.Details.Tags {
+					tags[tag] = true
+				}
+				data := post.Details.UserCustomData.(map[string]interface{})
+				// Every breadcrumb comes with the tag and custom data added
+				// before it was recorded.
+				for _, b := range post.Details.Breadcrumbs {
+					So(tags["tag-"+b.Message], ShouldBeTrue)
+					So(data, ShouldContainKey, "key-"+b.Message)
+				}
+			}
+
+			post := scope.createPost(errors.New("Test Scope"), StackTrace{})
+			So(post.Details.Tags, ShouldHaveLength, 160)
+			So(post.Details.UserCustomData, ShouldHaveLength, 161)
+		})
+	})
+}
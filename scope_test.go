@@ -0,0 +1,71 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestScopeSnapshot(t *testing.T) {
+	Convey("Scope snapshots", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		origin, _ := New("origin", "key")
+		r := httptest.NewRequest("GET", "http://www.example.com/orders?id=7&token=secret", nil)
+		r.Header.Set("Authorization", "Bearer abc")
+		origin.Request(r).
+			Version("1.2.3").
+			Tags([]string{"api", "orders"}).
+			User("jane").
+			CustomData(map[string]interface{}{"tenant": "acme"}).
+			RecordBreadcrumb(Breadcrumb{Message: "queued job", Timestamp: 1})
+
+		executor, _ := New("executor", "key")
+
+		Convey("carry the context to another client", func() {
+			data, err := origin.Export()
+			So(err, ShouldBeNil)
+
+			scope, err := executor.ImportScope(data)
+			So(err, ShouldBeNil)
+			So(scope.SendError(errors.New("Test ImportScope")), ShouldBeNil)
+
+			post, _ := server.next()
+			expected := origin.createPost(errors.New("Test ImportScope"), StackTrace{})
+			So(post.Details.Version, ShouldEqual, "1.2.3")
+			So(post.Details.Tags, ShouldResemble, expected.Details.Tags)
+			So(post.Details.User, ShouldResemble, expected.Details.User)
+			So(withoutReportID(post), ShouldResemble, withoutReportID(expected))
+			So(post.Details.Breadcrumbs, ShouldResemble, expected.Details.Breadcrumbs)
+			So(post.Details.Request.URL, ShouldEqual, "http://www.example.com/orders?id=7&token=%5BREDACTED%5D")
+			So(post.Details.Request.Headers, ShouldResemble, map[string]string{"Authorization": "[REDACTED]"})
+			So(post.Details.Request.HTTPMethod, ShouldEqual, "GET")
+			So(post.Details.Context, ShouldResemble, Context{executor.context.Identifier()})
+		})
+
+		Convey("leave the importing client unchanged", func() {
+			data, _ := origin.Export()
+			executor.ImportScope(data)
+			So(executor.context.Tags, ShouldBeNil)
+			So(executor.context.RequestRef, ShouldBeNil)
+			So(executor.breadcrumbs.snapshot(), ShouldBeNil)
+		})
+
+		Convey("keep the request out if request data is disabled", func() {
+			data, _ := origin.DisableRequestData(true).Export()
+			So(string(data), ShouldNotContainSubstring, "request")
+		})
+
+		Convey("are versioned", func() {
+			_, err := executor.ImportScope([]byte(`{"format":2}`))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Unsupported scope snapshot format 2")
+
+			_, err = executor.ImportScope([]byte(`not json`))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
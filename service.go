@@ -0,0 +1,54 @@
+package raygun4go
+
+import (
+	"net"
+	"net/http"
+)
+
+// The custom data keys the service information is stored under.
+const (
+	serviceNameKey       = "service.name"
+	serviceListenAddrKey = "service.listenAddr"
+)
+
+// ServiceInfo is a chainable option-setting method to record which service
+// and listener of the host reports come from. Both are added to the custom
+// data, under "service.name" and "service.listenAddr", and reports are tagged
+// "service:<name>". Empty values are left out. This is independent of the
+// application name given to New, which selects the Raygun application.
+//
+// If listenAddr is empty, the scoped clients of the middleware use the local
+// address the request was received on, when the server provides it.
+func (c *Client) ServiceInfo(name, listenAddr string) *Client {
+	if c == nil {
+		return nil
+	}
+	c.serviceName = name
+	c.serviceListenAddr = listenAddr
+	return c
+}
+
+// defaultListenAddr sets the listen address of the service to the local
+// address the given request was received on, unless it is set already or no
+// service name is set.
+func (c *Client) defaultListenAddr(r *http.Request) {
+	if c.serviceName == "" || c.serviceListenAddr != "" {
+		return
+	}
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		c.serviceListenAddr = addr.String()
+	}
+}
+
+// addServiceInfo adds the service information to the given report options
+// and returns the service tag, if any.
+func (c *Client) addServiceInfo(opts *reportOptions) []string {
+	if c.serviceListenAddr != "" {
+		opts.addCustomData(serviceListenAddrKey, c.serviceListenAddr)
+	}
+	if c.serviceName == "" {
+		return nil
+	}
+	opts.addCustomData(serviceNameKey, c.serviceName)
+	return []string{"service:" + c.serviceName}
+}
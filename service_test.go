@@ -0,0 +1,77 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestServiceInfo(t *testing.T) {
+	Convey("#ServiceInfo", t, func() {
+		c, _ := New("app", "key")
+		post := func(c *Client) PostData {
+			return c.createPost(errors.New("Test ServiceInfo"), StackTrace{})
+		}
+		customData := func(c *Client) map[string]interface{} {
+			data, _ := post(c).Details.UserCustomData.(map[string]interface{})
+			return data
+		}
+
+		Convey("adds nothing by default", func() {
+			So(withoutReportID(post(c)), ShouldBeNil)
+			So(post(c).Details.Tags, ShouldBeEmpty)
+		})
+
+		Convey("adds the service to the custom data and tags", func() {
+			c.Tags([]string{"api"}).ServiceInfo("billing", ":8080")
+			So(customData(c)["service.name"], ShouldEqual, "billing")
+			So(customData(c)["service.listenAddr"], ShouldEqual, ":8080")
+			So(post(c).Details.Tags, ShouldResemble, []string{"api", "service:billing"})
+			So(c.context.Tags, ShouldResemble, []string{"api"})
+		})
+
+		Convey("leaves out empty values", func() {
+			c.ServiceInfo("billing", "")
+			So(customData(c), ShouldNotContainKey, "service.listenAddr")
+			So(customData(c)["service.name"], ShouldEqual, "billing")
+		})
+
+		Convey("leaves the app name unchanged", func() {
+			c.ServiceInfo("billing", ":8080")
+			So(c.appName, ShouldEqual, "app")
+		})
+
+		Convey("is used by the middleware", func() {
+			var scoped *Client
+			handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				scoped = FromContext(r.Context())
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+			r = r.WithContext(context.WithValue(r.Context(), http.LocalAddrContextKey, addr))
+
+			Convey("defaulting the listen address to the local address", func() {
+				c.ServiceInfo("billing", "")
+				handler.ServeHTTP(httptest.NewRecorder(), r)
+				So(customData(scoped)["service.listenAddr"], ShouldEqual, "127.0.0.1:8080")
+				So(c.serviceListenAddr, ShouldEqual, "")
+			})
+
+			Convey("keeping a given listen address", func() {
+				c.ServiceInfo("billing", ":9090")
+				handler.ServeHTTP(httptest.NewRecorder(), r)
+				So(customData(scoped)["service.listenAddr"], ShouldEqual, ":9090")
+			})
+
+			Convey("not defaulting without a service name", func() {
+				handler.ServeHTTP(httptest.NewRecorder(), r)
+				So(customData(scoped), ShouldNotContainKey, "service.listenAddr")
+			})
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package raygun4go
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// NotifyShutdown installs a handler for the given signals, SIGINT and SIGTERM
+// by default, that closes the client like Close once the process receives one
+// of them, waiting up to timeout for queued and batched reports to be
+// delivered; zero waits as long as Close. The handler then uninstalls itself
+// and raises the signal again, so that the process terminates as it would
+// have without the handler. An application trapping the signals itself, e.g.
+// with signal.Notify, thus receives them twice: as usual, and again once the
+// reports were delivered.
+//
+// The returned function uninstalls the handler unless it ran already; it is
+// safe to call more than once.
+//
+//	stop := raygun.NotifyShutdown(10 * time.Second)
+//	defer stop()
+func (c *Client) NotifyShutdown(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	if c == nil {
+		return func() {}
+	}
+	if timeout <= 0 {
+		timeout = c.closeTimeout
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	received := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(stopped)
+		})
+	}
+
+	signal.Notify(received, signals...)
+	go func() {
+		select {
+		case sig := <-received:
+			c.shutdown(sig, timeout)
+			stop()
+			c.raise(sig)
+		case <-stopped:
+		}
+	}()
+	return stop
+}
+
+// shutdown closes the client after the given signal was received.
+func (c *Client) shutdown(sig os.Signal, timeout time.Duration) {
+	c.debugf("Received %s, delivering queued reports", sig)
+	if err := c.closeWithin(timeout); err != nil {
+		c.errorf("%s", err.Error())
+	}
+}
+
+// raise sends the given signal to the current process.
+func (c *Client) raise(sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		c.errorf("Unable to raise %s again (%s)", sig, err.Error())
+	}
+}
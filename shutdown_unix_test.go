@@ -0,0 +1,65 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package raygun4go
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNotifyShutdown(t *testing.T) {
+	Convey("#NotifyShutdown", t, func() {
+		// The test traps the signal as well, so that raising it again does
+		// not terminate the test process.
+		trapped := make(chan os.Signal, 2)
+		signal.Notify(trapped, syscall.SIGUSR1)
+		transport := NewRecordingTransport()
+		c, _ := New("app", "key")
+		c.Asynchronous(true).Transport(transport)
+		stop := c.NotifyShutdown(time.Second, syscall.SIGUSR1)
+		Reset(func() {
+			stop()
+			signal.Stop(trapped)
+			c.Close()
+		})
+		raise := func() {
+			So(syscall.Kill(os.Getpid(), syscall.SIGUSR1), ShouldBeNil)
+			<-trapped
+		}
+		raisedAgain := func() bool {
+			select {
+			case <-trapped:
+				return true
+			case <-time.After(time.Second):
+				return false
+			}
+		}
+
+		Convey("delivers queued reports and closes the client on the signal", func() {
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			raise()
+			So(raisedAgain(), ShouldBeTrue)
+			So(messages(transport.Posts()), ShouldResemble, []string{"report 1"})
+			So(c.SendError(errors.New("report 2")), ShouldEqual, ErrClientClosed)
+		})
+
+		Convey("does nothing once stopped", func() {
+			stop()
+			stop()
+			raise()
+			So(raisedAgain(), ShouldBeFalse)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+		})
+
+		Convey("does nothing for a nil client", func() {
+			var nilClient *Client
+			nilClient.NotifyShutdown(0)()
+		})
+	})
+}
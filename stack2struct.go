@@ -32,34 +32,55 @@ type stackTrace interface {
 	AddEntry(lineNumber int, packageName string, fileName string, methodName string)
 }
 
+// stackTruncatedMarker is added as the last entry of a stack trace that did
+// not fit into the buffer of Current.
+const stackTruncatedMarker = "...remaining frames truncated..."
+
 // Current loads the current stacktrace into a given stack
 func Current(stack stackTrace) {
 	rawStack := make([]byte, 1<<16)
-	rawStack = rawStack[:runtime.Stack(rawStack, false)]
-	Parse(rawStack, stack)
+	n := runtime.Stack(rawStack, false)
+	Parse(rawStack[:n], stack)
+	if n == len(rawStack) {
+		stack.AddEntry(0, "", "", stackTruncatedMarker)
+	}
 }
 
 // Parse loads the stack trace (given as trace) into the given stack.
-// See Current() on how to obtain a stack trace.
+// See Current() on how to obtain a stack trace. Lines such as
+// "...12 frames elided..." that the runtime prints instead of frames of deep
+// stacks are added as entries holding the line as method name only.
 func Parse(trace []byte, stack stackTrace) {
 	lines := strings.Split(strings.ReplaceAll(string(trace), "\r\n", "\n"), "\n")
 
 	var lineNumber int
 	var fileName, packageName, methodName string
 
-	for index, line := range lines[1:] {
+	expectFile := false
+	for _, line := range lines[1:] {
 		if len(line) == 0 {
 			continue
 		}
-		if index%2 == 0 {
+		if !expectFile && isTruncationMarker(line) {
+			stack.AddEntry(0, "", "", line)
+			continue
+		}
+		if !expectFile {
 			packageName, methodName = extractPackageName(line)
 		} else {
 			lineNumber, fileName = extractLineNumberAndFile(line)
 			stack.AddEntry(lineNumber, packageName, fileName, methodName)
 		}
+		expectFile = !expectFile
 	}
 }
 
+// isTruncationMarker reports whether the given line of a stack trace stands
+// for frames left out of it.
+func isTruncationMarker(line string) bool {
+	return len(line) > 6 && strings.HasPrefix(line, "...") && strings.HasSuffix(line, "...")
+}
+
 // LoadGoErrorStack loads the strack trace (given as frames) into the given stack.
 func LoadGoErrorStack(frames []goerrors.StackFrame, stack stackTrace) {
 	for _, frame := range frames {
@@ -87,22 +108,29 @@ func extractPackageName(line string) (packageName, methodName string) {
 }
 
 // extractLineNumberAndFile receives a trace line and extracts lineNumber and
-// fileName.
+// fileName. Paths may use slashes, backslashes or both, as in traces of
+// Windows builds, and start with a drive letter or a UNC host; the line
+// number is the numeric token after the last colon.
 func extractLineNumberAndFile(line string) (lineNumber int, fileName string) {
-	_, fileAndLine := splitAtLastSlash(line)
+	_, fileAndLine := splitAtLastSeparator(line)
 	fileAndLine = removeSpaceAndSuffix(fileAndLine)
-	parts := strings.Split(fileAndLine, ":")
-
-	lineNumber = 0
 
-	if len(parts) >= 2 {
-		numberAsString := parts[1]
-		number, _ := strconv.ParseUint(numberAsString, 10, 32)
-		lineNumber = int(number)
+	i := strings.LastIndex(fileAndLine, ":")
+	if i < 0 {
+		return 0, fileAndLine
 	}
+	number, _ := strconv.ParseUint(fileAndLine[i+1:], 10, 32)
+	return int(number), fileAndLine[:i]
+}
 
-	fileName = parts[0]
-	return lineNumber, fileName
+// splitAtLastSeparator splits a path at the last slash or backslash and
+// returns the respective strings left and right of it.
+func splitAtLastSeparator(path string) (left, right string) {
+	i := strings.LastIndexAny(path, `/\`)
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
 }
 
 // splitAtLastSlash splits a string at the last found slash and returns the
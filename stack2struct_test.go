@@ -118,6 +118,48 @@ func TestStack2Struct(t *testing.T) {
 		So(stack[0], ShouldResemble, expected[0])
 	})
 
+	Convey("#ParseWithWindowsPaths", t, func() {
+		buf, _ := os.ReadFile("_fixtures/stack_trace_windows")
+
+		stack := make(testStack, 0)
+		Parse(buf, &stack)
+
+		So(stack, ShouldResemble, testStack{
+			testElement{42, "main", "main.go", "handler(0xc000012345)"},
+			testElement{118, "github.com/acme/app/internal/store", "db.go", "(*DB).Get(...)"},
+			testElement{7, "main", "cmd.go", "main()"},
+		})
+	})
+
+	Convey("#extractLineNumberAndFile", t, func() {
+		for line, expected := range map[string]testElement{
+			`	C:\build\main.go:42 +0x1d`:    {lineNumber: 42, fileName: "main.go"},
+			`	\\host\share\src\main.go:9`:   {lineNumber: 9, fileName: "main.go"},
+			`	D:/src\app/main.go:3 +0x5`:    {lineNumber: 3, fileName: "main.go"},
+			`	/usr/src/app/main.go:12 +0x4`: {lineNumber: 12, fileName: "main.go"},
+			`	C:\build\main.go`:             {lineNumber: 0, fileName: "main.go"},
+		} {
+			lineNumber, fileName := extractLineNumberAndFile(line)
+			So(testElement{lineNumber: lineNumber, fileName: fileName}, ShouldResemble, expected)
+		}
+	})
+
+	Convey("#ParseWithElidedFrames", t, func() {
+		buf := []byte("goroutine 1 [running]:\n" +
+			"main.rec(0x1)\n\t/tmp/main.go:15 +0x65\n" +
+			"...52 frames elided...\n" +
+			"main.main()\n\t/tmp/main.go:19 +0x25\n")
+
+		stack := make(testStack, 0)
+		Parse(buf, &stack)
+
+		So(stack, ShouldResemble, testStack{
+			testElement{15, "main", "main.go", "rec(0x1)"},
+			testElement{0, "", "", "...52 frames elided..."},
+			testElement{19, "main", "main.go", "main()"},
+		})
+	})
+
 	Convey("#LoadGoErrorStack", t, func() {
 		testFrames := []goerrors.StackFrame{
 			{File: "stack2struct_test.go",
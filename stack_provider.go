@@ -0,0 +1,104 @@
+package raygun4go
+
+import (
+	"reflect"
+
+	goerrors "github.com/go-errors/errors"
+)
+
+const (
+	// recoverySiteStackKey is the custom data key the top frames of the
+	// recovery site are stored under when a panic value carries its own
+	// stack trace.
+	recoverySiteStackKey = "recoverySiteStack"
+
+	// recoverySiteFrames is the number of recovery site frames kept.
+	recoverySiteFrames = 5
+)
+
+// StackTraceProvider is implemented by errors carrying the stack trace of
+// where they were created. When such an error is reported, its stack trace is
+// used instead of the one of the reporting call.
+type StackTraceProvider interface {
+	RaygunStackTrace() StackTrace
+}
+
+// embeddedStack returns the stack trace carried by err or an error it wraps.
+// Errors of github.com/go-errors/errors, github.com/pkg/errors and errors
+// implementing StackTraceProvider are recognized. If several errors in the
+// chain carry a stack trace, the innermost one is used, which is the closest
+// to where the error originated.
+func embeddedStack(err error) (StackTrace, bool) {
+	var found StackTrace
+	for err != nil {
+		if st, ok := stackOfError(err); ok {
+			found = st
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = wrapper.Unwrap()
+	}
+	return found, found != nil
+}
+
+// stackOfError returns the stack trace carried by err itself, not considering
+// the errors it wraps.
+func stackOfError(err error) (StackTrace, bool) {
+	switch e := err.(type) {
+	case StackTraceProvider:
+		st := e.RaygunStackTrace()
+		return st, len(st) > 0
+	case *goerrors.Error:
+		st := make(StackTrace, 0)
+		LoadGoErrorStack(e.StackFrames(), &st)
+		return st, len(st) > 0
+	}
+
+	pcs := pkgErrorsCallers(err)
+	if len(pcs) == 0 {
+		return nil, false
+	}
+	frames := make([]goerrors.StackFrame, len(pcs))
+	for i, pc := range pcs {
+		frames[i] = goerrors.NewStackFrame(pc)
+	}
+	st := make(StackTrace, 0, len(frames))
+	LoadGoErrorStack(frames, &st)
+	return st, true
+}
+
+// pkgErrorsCallers returns the program counters of the stack trace of an
+// error created by github.com/pkg/errors. Such errors have a StackTrace method
+// returning a slice of frames, which are program counters. It is matched by
+// its shape so the package need not be imported.
+func pkgErrorsCallers(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+// topFrames returns the locations of the first frames of the given stack
+// trace, see recoverySiteFrames.
+func topFrames(st StackTrace) []string {
+	if len(st) > recoverySiteFrames {
+		st = st[:recoverySiteFrames]
+	}
+	locations := make([]string, len(st))
+	for i, frame := range st {
+		locations[i] = frame.location()
+	}
+	return locations
+}
@@ -0,0 +1,92 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	goerrors "github.com/go-errors/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// providedStackError implements StackTraceProvider.
+type providedStackError struct{ st StackTrace }
+
+func (e providedStackError) Error() string                { return "Test StackTraceProvider" }
+func (e providedStackError) RaygunStackTrace() StackTrace { return e.st }
+
+// pkgErrorsFrame and pkgErrorsError mimic the shape of the errors of
+// github.com/pkg/errors.
+type pkgErrorsFrame uintptr
+
+type pkgErrorsError struct{ frames []pkgErrorsFrame }
+
+func (e pkgErrorsError) Error() string                { return "Test pkg/errors" }
+func (e pkgErrorsError) StackTrace() []pkgErrorsFrame { return e.frames }
+
+func TestEmbeddedStack(t *testing.T) {
+	Convey("Panics with errors carrying a stack trace", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		panicWith := func(e interface{}) {
+			defer c.HandleError()
+			panic(e)
+		}
+
+		Convey("report the creation site of go-errors errors", func() {
+			err := goerrors.Wrap(errors.New("Test go-errors"), 0)
+			creation := err.StackFrames()[0]
+
+			panicWith(err)
+			post, _ := server.next()
+			So(post.Details.Error.Message, ShouldEqual, "Test go-errors")
+			So(post.Details.Error.StackTrace[0].LineNumber, ShouldEqual, creation.LineNumber)
+			So(post.Details.Error.StackTrace[0].MethodName, ShouldEqual, creation.Name)
+
+			data, _ := post.Details.UserCustomData.(map[string]interface{})
+			recoverySite, _ := data["recoverySiteStack"].([]interface{})
+			So(recoverySite, ShouldNotBeEmpty)
+			So(len(recoverySite), ShouldBeLessThanOrEqualTo, 5)
+			So(fmt.Sprint(recoverySite...), ShouldContainSubstring, "stack_provider_test.go")
+		})
+
+		Convey("report the innermost stack trace of wrapped errors", func() {
+			inner := goerrors.New("Test go-errors")
+			outer := goerrors.Wrap(fmt.Errorf("Test wrapped (%w)", inner), 0)
+
+			panicWith(outer)
+			post, _ := server.next()
+			So(post.Details.Error.StackTrace[0].LineNumber, ShouldEqual, inner.StackFrames()[0].LineNumber)
+		})
+
+		Convey("report the stack trace of StackTraceProviders", func() {
+			st := StackTrace{}
+			st.AddEntry(42, "main", "main.go", "create")
+
+			panicWith(providedStackError{st})
+			post, _ := server.next()
+			So(post.Details.Error.StackTrace, ShouldResemble, st)
+		})
+
+		Convey("report the stack trace of pkg/errors errors", func() {
+			goerror := goerrors.New("Test go-errors")
+			frames := make([]pkgErrorsFrame, len(goerror.Callers()))
+			for i, pc := range goerror.Callers() {
+				frames[i] = pkgErrorsFrame(pc)
+			}
+
+			panicWith(pkgErrorsError{frames})
+			post, _ := server.next()
+			So(post.Details.Error.StackTrace[0].LineNumber, ShouldEqual, goerror.StackFrames()[0].LineNumber)
+		})
+
+		Convey("report the recovery site for other values", func() {
+			panicWith(errors.New("Test plain error"))
+			post, _ := server.next()
+			So(post.Details.Error.StackTrace[0].FileName, ShouldEqual, "panic.go")
+			So(withoutReportID(post), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package raygun4go
+
+import "sync/atomic"
+
+// Stats are the numbers of reports a client and its clones handed over for
+// delivery to Raygun, and what became of them, see Client.Stats.
+type Stats struct {
+	Submitted uint64 // the reports handed over for delivery
+	Succeeded uint64 // the reports Raygun accepted
+	Failed    uint64 // the reports whose delivery failed
+	Dropped   uint64 // the reports discarded because a queue was full or closed
+	Sampled   uint64 // the reports left out by SampleRate, not counted as submitted
+
+	Pending  int // the reports queued or batched and not attempted yet, see Pending
+	InFlight int // the queued or batched reports being delivered, see InFlight
+}
+
+// reportCounters counts the reports of a client. It is shared by a client
+// and all its clones.
+type reportCounters struct {
+	submitted uint64
+	succeeded uint64
+	failed    uint64
+	dropped   uint64
+	sampled   uint64
+}
+
+// submit counts a report handed over for delivery.
+func (s *reportCounters) submit() {
+	atomic.AddUint64(&s.submitted, 1)
+}
+
+// record counts the outcome of the delivery of a report, given the error it
+// failed with, if any.
+func (s *reportCounters) record(err error) {
+	if err == nil {
+		atomic.AddUint64(&s.succeeded, 1)
+	} else {
+		atomic.AddUint64(&s.failed, 1)
+	}
+}
+
+// drop counts a discarded report.
+func (s *reportCounters) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+}
+
+// sample counts a report left out by sampling.
+func (s *reportCounters) sample() {
+	atomic.AddUint64(&s.sampled, 1)
+}
+
+// Stats returns the numbers of reports the client and its clones handed over
+// for delivery since New, and of the ones that were delivered, failed or
+// were dropped by the asynchronous queue or batching, see QueueOverflow.
+// Reports still queued, buffered or written to disk by Close count as
+// submitted only, so Submitted minus the other counters is the number of
+// reports in flight, of which Pending and InFlight are the queued and
+// batched ones. Reports kept by OfflineStorage count as failed, and
+// their later delivery is not counted again. Reports printed by a Silent
+// client, cancelled by a BeforeSend hook, suppressed as duplicates or
+// rejected after Close are not counted at all, and the ones left out by
+// SampleRate only as Sampled.
+func (c *Client) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	pending, inFlight := c.queueCounts()
+	return Stats{
+		Submitted: atomic.LoadUint64(&c.stats.submitted),
+		Succeeded: atomic.LoadUint64(&c.stats.succeeded),
+		Failed:    atomic.LoadUint64(&c.stats.failed),
+		Dropped:   atomic.LoadUint64(&c.stats.dropped),
+		Sampled:   atomic.LoadUint64(&c.stats.sampled),
+		Pending:   pending,
+		InFlight:  inFlight,
+	}
+}
+
+// Pending returns the number of reports waiting in the asynchronous queue or
+// the batch of the client and its clones that were not attempted yet. Unlike
+// Stats, it is a snapshot of the moment, e.g. to alert on a growing queue.
+func (c *Client) Pending() int {
+	pending, _ := c.queueCounts()
+	return pending
+}
+
+// InFlight returns the number of reports of the asynchronous queue or the
+// batch of the client and its clones being delivered at the moment.
+func (c *Client) InFlight() int {
+	_, inFlight := c.queueCounts()
+	return inFlight
+}
+
+// queueCounts returns the sums of the pending and in-flight reports of the
+// asynchronous queue and the batch.
+func (c *Client) queueCounts() (pending, inFlight int) {
+	if c == nil {
+		return 0, 0
+	}
+	pending, inFlight = c.queue.counts()
+	if c.batch != nil {
+		p, f := c.batch.counts()
+		pending, inFlight = pending+p, inFlight+f
+	}
+	return pending, inFlight
+}
@@ -0,0 +1,213 @@
+package raygun4go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reports are stored on disk in a small envelope that allows detecting
+// truncated or otherwise corrupted files before they are submitted:
+//
+//	1 byte    format version (storedReportVersion)
+//	8 bytes   payload length, big endian
+//	32 bytes  SHA-256 of the payload
+//	n bytes   payload, the JSON encoded storedReport
+
+// storedReportVersion is the version of the envelope format written to disk.
+const storedReportVersion byte = 1
+
+// storedReportHeaderSize is the size of the envelope preceding the payload.
+const storedReportHeaderSize = 1 + 8 + sha256.Size
+
+// storedReportExtension is the file extension of reports persisted to disk.
+const storedReportExtension = ".report"
+
+// corruptReportDir is the subdirectory corrupted reports are moved to.
+const corruptReportDir = "corrupt"
+
+// errCorruptReport is returned when a stored report fails verification.
+var errCorruptReport = errors.New("stored report is corrupt")
+
+// storedReport is the on-disk representation of a report that has not been
+// delivered yet.
+type storedReport struct {
+	Attempts int
+	Post     PostData
+}
+
+// storedReportJSON is the JSON encoding of a storedReport. The post is always
+// stored in wire format v1, together with the parts v1 leaves out, so it is
+// sent in its original wire format once resumed. The API key of WithApplication
+// is not stored, so it never lands on disk in plain text; the resuming client
+// resolves it from the application name, see APIKeySelector.
+type storedReportJSON struct {
+	Attempts    int                 `json:"attempts"`
+	Post        storedPost          `json:"post"`
+	WireFormat  int                 `json:"wireFormat,omitempty"`
+	QueryValues map[string][]string `json:"queryValues,omitempty"`
+	Cookies     map[string]string   `json:"cookies,omitempty"`
+	AppName     string              `json:"appName,omitempty"`
+	Raw         json.RawMessage     `json:"raw,omitempty"`
+}
+
+// storedPost is PostData without its wire format dependent encoding.
+type storedPost PostData
+
+// MarshalJSON encodes the report as storedReportJSON.
+func (r storedReport) MarshalJSON() ([]byte, error) {
+	request := r.Post.Details.Request
+	return json.Marshal(storedReportJSON{
+		Attempts:    r.Attempts,
+		Post:        storedPost(r.Post),
+		WireFormat:  r.Post.wireFormat,
+		QueryValues: request.queryValues,
+		Cookies:     request.cookies,
+		AppName:     r.Post.appName,
+		Raw:         r.Post.raw,
+	})
+}
+
+// UnmarshalJSON decodes a report encoded by MarshalJSON.
+func (r *storedReport) UnmarshalJSON(data []byte) error {
+	var stored storedReportJSON
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	r.Attempts = stored.Attempts
+	r.Post = PostData(stored.Post)
+	r.Post.wireFormat = stored.WireFormat
+	r.Post.Details.Request.queryValues = stored.QueryValues
+	r.Post.Details.Request.cookies = stored.Cookies
+	r.Post.appName = stored.AppName
+	r.Post.raw = stored.Raw
+	return nil
+}
+
+// encodeStoredReport wraps the JSON encoded report in the envelope.
+func encodeStoredReport(stored storedReport) ([]byte, error) {
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(payload)
+	buf := bytes.NewBuffer(make([]byte, 0, storedReportHeaderSize+len(payload)))
+	buf.WriteByte(storedReportVersion)
+	binary.Write(buf, binary.BigEndian, uint64(len(payload)))
+	buf.Write(sum[:])
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+// decodeStoredReport verifies the envelope and decodes the report. It returns
+// an error wrapping errCorruptReport if the data fails verification.
+func decodeStoredReport(data []byte) (storedReport, error) {
+	var stored storedReport
+	if len(data) < storedReportHeaderSize {
+		return stored, fmt.Errorf("%w: truncated header", errCorruptReport)
+	}
+	if data[0] != storedReportVersion {
+		return stored, fmt.Errorf("%w: unknown version %d", errCorruptReport, data[0])
+	}
+
+	length := binary.BigEndian.Uint64(data[1:9])
+	payload := data[storedReportHeaderSize:]
+	if uint64(len(payload)) != length {
+		return stored, fmt.Errorf("%w: expected %d bytes, found %d", errCorruptReport, length, len(payload))
+	}
+
+	sum := sha256.Sum256(payload)
+	if !bytes.Equal(sum[:], data[9:storedReportHeaderSize]) {
+		return stored, fmt.Errorf("%w: checksum mismatch", errCorruptReport)
+	}
+
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return stored, fmt.Errorf("%w: %s", errCorruptReport, err.Error())
+	}
+	return stored, nil
+}
+
+// persistReports writes the given reports to the given directory, one file
+// per report. The file names preserve the order of the reports.
+func persistReports(dir string, reports []queuedReport) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("Unable to create directory (%w)", err)
+	}
+
+	prefix := time.Now().UTC().Format("20060102T150405.000000000Z")
+	for i, r := range reports {
+		name := fmt.Sprintf("%s-%06d%s", prefix, i, storedReportExtension)
+		if err := writeStoredReport(dir, name, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStoredReport writes the given report to the file with the given name
+// in the given directory. The file is written under a temporary name first,
+// so a report being written is never read.
+func writeStoredReport(dir, name string, r queuedReport) error {
+	data, err := encodeStoredReport(storedReport{Attempts: r.attempts, Post: r.post})
+	if err != nil {
+		return newKindError(ErrMarshalFailure, err, "Unable to convert to JSON (%s)", err.Error())
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("Unable to write report (%w)", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("Unable to write report (%w)", err)
+	}
+	return nil
+}
+
+// storedReportFiles returns the paths of all reports persisted to the given
+// directory, oldest first. A missing directory holds no reports.
+func storedReportFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read directory (%w)", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), storedReportExtension) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readStoredReport reads and verifies a single persisted report.
+func readStoredReport(file string) (storedReport, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return storedReport{}, fmt.Errorf("Unable to read report (%w)", err)
+	}
+	return decodeStoredReport(data)
+}
+
+// quarantineReport moves a corrupt report into the corrupt subdirectory of the
+// directory it was stored in, so it is not read again.
+func quarantineReport(file string) error {
+	dir := filepath.Join(filepath.Dir(file), corruptReportDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.Rename(file, filepath.Join(dir, filepath.Base(file)))
+}
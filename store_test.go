@@ -0,0 +1,89 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStore(t *testing.T) {
+	Convey("Store", t, func() {
+		stored := storedReport{Attempts: 2, Post: PostData{OccuredOn: "2024-01-01T00:00:01Z"}}
+		data, err := encodeStoredReport(stored)
+		So(err, ShouldBeNil)
+
+		Convey("#encodeStoredReport writes the envelope", func() {
+			So(data[0], ShouldEqual, storedReportVersion)
+			So(len(data), ShouldBeGreaterThan, storedReportHeaderSize)
+		})
+
+		Convey("#decodeStoredReport", func() {
+			Convey("round-trips the report", func() {
+				decoded, err := decodeStoredReport(data)
+				So(err, ShouldBeNil)
+				So(decoded.Attempts, ShouldEqual, 2)
+				So(decoded.Post.OccuredOn, ShouldEqual, "2024-01-01T00:00:01Z")
+			})
+
+			Convey("detects truncation", func() {
+				_, err := decodeStoredReport(data[:len(data)/2])
+				So(errors.Is(err, errCorruptReport), ShouldBeTrue)
+
+				_, err = decodeStoredReport(data[:10])
+				So(errors.Is(err, errCorruptReport), ShouldBeTrue)
+			})
+
+			Convey("detects changed bytes", func() {
+				data[len(data)-5] ^= 0xff
+				_, err := decodeStoredReport(data)
+				So(errors.Is(err, errCorruptReport), ShouldBeTrue)
+			})
+
+			Convey("detects unknown versions", func() {
+				data[0] = storedReportVersion + 1
+				_, err := decodeStoredReport(data)
+				So(errors.Is(err, errCorruptReport), ShouldBeTrue)
+			})
+		})
+
+		Convey("#Resume quarantines corrupt reports", func() {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defaultEndpoint := raygunEndpoint
+			raygunEndpoint = server.URL
+			dir, _ := os.MkdirTemp("", "raygun4go")
+			Reset(func() {
+				raygunEndpoint = defaultEndpoint
+				server.Close()
+				os.RemoveAll(dir)
+			})
+
+			reports := []queuedReport{{post: stored.Post}, {post: stored.Post}}
+			So(persistReports(dir, reports), ShouldBeNil)
+			files, _ := storedReportFiles(dir)
+			content, _ := os.ReadFile(files[0])
+			os.WriteFile(files[0], content[:len(content)-3], 0600)
+
+			for i := 0; i < 2; i++ {
+				c, _ := New("app", "key")
+				So(c.Resume(dir), ShouldBeNil)
+				So(c.Close(), ShouldBeNil)
+			}
+
+			So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+			files, _ = storedReportFiles(dir)
+			So(files, ShouldBeEmpty)
+			quarantined, _ := storedReportFiles(filepath.Join(dir, corruptReportDir))
+			So(len(quarantined), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,105 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DegradationError is returned by the sending methods in strict mode if the
+// report could only be built in a degraded way, see Strict.
+type DegradationError struct {
+	Err          error   // the result of sending the report, nil if it was sent
+	Degradations []error // the problems building the report
+}
+
+// Error lists the degradations, preceded by the error sending the report if
+// there was one.
+func (e *DegradationError) Error() string {
+	problems := make([]string, len(e.Degradations))
+	for i, d := range e.Degradations {
+		problems[i] = d.Error()
+	}
+	msg := fmt.Sprintf("Degraded report (%s)", strings.Join(problems, "; "))
+	if e.Err != nil {
+		return e.Err.Error() + ", " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the error sending the report.
+func (e *DegradationError) Unwrap() error {
+	return e.Err
+}
+
+// Strict is a chainable option-setting method to notice problems that are
+// silently worked around otherwise, e.g. during development. In strict mode,
+// the sending methods and HandleError return a *DegradationError, which also
+// holds the result of sending, and log it if the report was degraded by
+//
+//   - a request form that could not be parsed
+//   - custom data that is not a map but had to be merged with internal
+//     entries, so it was kept under "value"
+//   - custom data that cannot be converted to JSON
+//   - a stack trace that was truncated
+//   - redaction patterns that were ignored because they do not compile
+//   - an Endpoint or Region that was ignored because it is invalid
+//
+// Reports are still sent in all of these cases.
+func (c *Client) Strict(strict bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.strict = strict
+	return c
+}
+
+// collectDegradations records the problems building the given post in strict
+// mode. data and extra are the custom data before they were merged.
+func (c *Client) collectDegradations(post *PostData, data interface{}, extra map[string]interface{}) {
+	if !c.strict {
+		return
+	}
+
+	var degradations []error
+	if err := post.Details.Request.parseErr; err != nil {
+		degradations = append(degradations, fmt.Errorf("Unable to parse request form (%w)", err))
+	}
+	if len(extra) > 0 && data != nil && !isStringKeyedMap(data) {
+		degradations = append(degradations, fmt.Errorf("Custom data of type %T was kept under \"value\"", data))
+	}
+	if _, err := json.Marshal(post.Details.UserCustomData); err != nil {
+		degradations = append(degradations, newKindError(ErrMarshalFailure, err, "Unable to convert custom data to JSON (%s)", err.Error()))
+	}
+	for _, frame := range post.Details.Error.StackTrace {
+		if frame.FileName == "" && isTruncationMarker(frame.MethodName) {
+			degradations = append(degradations, fmt.Errorf("Stack trace was truncated (%s)", frame.MethodName))
+			break
+		}
+	}
+	degradations = append(degradations, c.redaction.invalid...)
+	if c.endpointErr != nil {
+		degradations = append(degradations, c.endpointErr)
+	}
+	post.degradations = degradations
+}
+
+// strictResult returns err, wrapped in a *DegradationError if the given post
+// was degraded in strict mode.
+func (c *Client) strictResult(post PostData, err error) error {
+	if !c.strict || len(post.degradations) == 0 {
+		return err
+	}
+	degraded := &DegradationError{Err: err, Degradations: post.degradations}
+	c.output().Errorf("%s", degraded.Error())
+	return degraded
+}
+
+// degrade records the given problem building the post, which is reported in
+// strict mode and logged if logging is enabled.
+func (c *Client) degrade(post *PostData, problem error) {
+	c.errorf("%s", problem.Error())
+	if c.strict {
+		post.degradations = append(post.degradations, problem)
+	}
+}
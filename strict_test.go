@@ -0,0 +1,111 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+//go:noinline
+func sendErrorFromDepth(c *Client, depth int) error {
+	if depth == 0 {
+		return c.SendError(errors.New("Test deep stack"))
+	}
+	return sendErrorFromDepth(c, depth-1)
+}
+
+func TestStrict(t *testing.T) {
+	Convey("Strict", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		degradations := func(err error) []string {
+			var degraded *DegradationError
+			if !errors.As(err, &degraded) {
+				return nil
+			}
+			var msgs []string
+			for _, d := range degraded.Degradations {
+				msgs = append(msgs, d.Error())
+			}
+			return msgs
+		}
+
+		cases := []struct {
+			name     string
+			setup    func()
+			send     func() error
+			expected string
+		}{
+			{
+				name: "request parse failures",
+				setup: func() {
+					r := httptest.NewRequest("POST", "/", strings.NewReader("a=%zz"))
+					r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					c.Request(r)
+				},
+				expected: "Unable to parse request form",
+			},
+			{
+				name:     "the custom data fallback",
+				setup:    func() { c.CustomData("plain") },
+				send:     func() error { return c.CreateError("Test Strict") },
+				expected: `Custom data of type string was kept under "value"`,
+			},
+			{
+				name:     "stack truncation",
+				setup:    func() {},
+				send:     func() error { return sendErrorFromDepth(c, 150) },
+				expected: "Stack trace was truncated (...",
+			},
+			{
+				name:     "redaction pattern errors",
+				setup:    func() { c.RedactPatterns("(") },
+				expected: `Ignored redaction pattern "("`,
+			},
+		}
+
+		for _, tc := range cases {
+			tc := tc
+			send := tc.send
+			if send == nil {
+				send = func() error { return c.SendError(errors.New("Test Strict")) }
+			}
+
+			Convey("reports "+tc.name, func() {
+				tc.setup()
+				c.Strict(true)
+				err := send()
+				So(err, ShouldNotBeNil)
+				So(strings.Join(degradations(err), "\n"), ShouldContainSubstring, tc.expected)
+				So(errors.Unwrap(err), ShouldBeNil)
+
+				post, ok := server.next()
+				So(ok, ShouldBeTrue)
+				So(post.Details.Error.Message, ShouldStartWith, "Test")
+			})
+
+			Convey("ignores "+tc.name+" by default", func() {
+				tc.setup()
+				So(send(), ShouldBeNil)
+			})
+		}
+
+		Convey("reports custom data that cannot be converted to JSON alongside the result", func() {
+			c.CustomData(map[string]interface{}{"f": func() {}}).Strict(true)
+			err := c.SendError(errors.New("Test Strict"))
+			So(degradations(err)[0], ShouldStartWith, "Unable to convert custom data to JSON")
+			So(errors.Unwrap(err), ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "Unable to convert to JSON")
+		})
+
+		Convey("returns nothing extra for intact reports", func() {
+			c.Strict(true)
+			So(c.SendError(errors.New("Test Strict")), ShouldBeNil)
+		})
+	})
+}
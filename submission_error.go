@@ -0,0 +1,37 @@
+package raygun4go
+
+// OnSubmissionError is a chainable option-setting method to set a function
+// called with every report whose delivery ultimately failed, after retries,
+// and the error it failed with, in synchronous and asynchronous mode alike.
+// It is called from the goroutine that submitted the report, e.g. the
+// background queue, and a panic in it is recovered and logged. Reports
+// dropped without a delivery attempt are passed to OnDrop instead. Passing
+// nil removes the function.
+func (c *Client) OnSubmissionError(fn func(post PostData, err error)) *Client {
+	if c == nil {
+		return nil
+	}
+	c.onSubmissionError = fn
+	return c
+}
+
+// recordResult counts the outcome of the delivery of the given post and
+// passes it to the OnSubmissionError function if the delivery failed.
+func (c *Client) recordResult(post PostData, err error) {
+	c.stats.record(err)
+	if err == nil {
+		c.metrics.IncSent()
+		return
+	}
+	c.metrics.IncFailed(failureReason(err))
+	if c.onSubmissionError == nil {
+		return
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			c.output().Errorf("Recovered from panic in OnSubmissionError: %v", e)
+		}
+	}()
+	c.onSubmissionError(post, err)
+}
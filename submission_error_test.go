@@ -0,0 +1,95 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOnSubmissionError(t *testing.T) {
+	Convey("#OnSubmissionError", t, func() {
+		server := rayguntest.New()
+		server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+		var mu sync.Mutex
+		var failed []PostData
+		var failures []error
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Retries(2, time.Millisecond).OnSubmissionError(func(post PostData, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, post)
+			failures = append(failures, err)
+		})
+		Reset(func() {
+			c.Close()
+			server.Close()
+		})
+		calls := func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(failed)
+		}
+
+		Convey("is called once per failed report after the retries", func() {
+			err := c.SendError(errors.New("report 1"))
+			So(err, ShouldNotBeNil)
+			So(server.Count(), ShouldEqual, 3)
+			So(calls(), ShouldEqual, 1)
+			So(failed[0].Details.Error.Message, ShouldEqual, "report 1")
+			So(failures[0], ShouldEqual, err)
+		})
+
+		Convey("is called in asynchronous mode", func() {
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			So(c.SendError(errors.New("report 2")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(calls(), ShouldEqual, 2)
+			So(messages(failed), ShouldResemble, []string{"report 1", "report 2"})
+			var submitErr *SubmitError
+			So(errors.As(failures[0], &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+		})
+
+		Convey("is called for batched reports", func() {
+			c.Batch(10, time.Hour)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldNotBeNil)
+			So(calls(), ShouldEqual, 1)
+		})
+
+		Convey("is not called for delivered reports", func() {
+			server.Fallback(rayguntest.Accepted)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			So(calls(), ShouldEqual, 0)
+		})
+
+		Convey("recovers panics of the function", func() {
+			c.Asynchronous(true).OnSubmissionError(func(PostData, error) {
+				panic("Test OnSubmissionError")
+			})
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldBeNil)
+
+			server.Fallback(rayguntest.Accepted)
+			So(c.SendError(errors.New("report 2")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(c.Stats(), ShouldResemble, Stats{Submitted: 2, Succeeded: 1, Failed: 1})
+		})
+
+		Convey("is kept by clones", func() {
+			So(c.Clone().onSubmissionError, ShouldNotBeNil)
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(nilClient.OnSubmissionError(nil), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,20 @@
+package raygun4go
+
+import "fmt"
+
+// recoverSubmission recovers a panic raised while submitting the given post,
+// e.g. by a MarshalJSON method of its CustomData, and stores it in err, so
+// that the panic neither kills the goroutine delivering the post nor escapes
+// from Submit. It must be deferred.
+func (c *Client) recoverSubmission(post PostData, err *error) {
+	if e := recover(); e != nil {
+		*err = c.submissionPanic(post, e)
+	}
+}
+
+// submissionPanic logs the given value recovered while submitting post and
+// returns an error for it matching ErrRecoveredPanic.
+func (c *Client) submissionPanic(post PostData, e interface{}) error {
+	c.errorf("Recovered from panic while submitting %s: %v", post.Summary(), e)
+	return fmt.Errorf("Unable to submit report (%w)", &panicError{value: e})
+}
@@ -0,0 +1,78 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// panickingData is custom data whose encoding panics.
+type panickingData struct{}
+
+// MarshalJSON panics.
+func (panickingData) MarshalJSON() ([]byte, error) {
+	panic("Test MarshalJSON")
+}
+
+func TestSubmissionPanic(t *testing.T) {
+	Convey("Panics while submitting", t, func() {
+		server := rayguntest.New()
+		var mu sync.Mutex
+		var failures []error
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).CustomData(panickingData{}).OnSubmissionError(func(_ PostData, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, err)
+		})
+		Reset(func() {
+			c.Close()
+			server.Close()
+		})
+		failed := func() []error {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]error(nil), failures...)
+		}
+
+		Convey("are returned as errors by Submit", func() {
+			err := c.SendError(errors.New("report 1"))
+			So(errors.Is(err, ErrRecoveredPanic), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "Test MarshalJSON")
+			So(failed(), ShouldResemble, []error{err})
+			So(c.Stats().Failed, ShouldEqual, 1)
+		})
+
+		Convey("do not kill the goroutine delivering asynchronously", func() {
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("report 1")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(failed(), ShouldHaveLength, 1)
+			So(errors.Is(failed()[0], ErrRecoveredPanic), ShouldBeTrue)
+			So(c.Stats().Failed, ShouldEqual, 1)
+
+			c.CustomData(nil)
+			So(c.SendError(errors.New("report 2")), ShouldBeNil)
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("are resolved as errors by SubmitAsync", func() {
+			err := <-c.SubmitAsync(c.createPost(errors.New("report 1"), nil))
+			So(errors.Is(err, ErrRecoveredPanic), ShouldBeTrue)
+		})
+
+		Convey("are recovered while reporting a panic", func() {
+			func() {
+				defer c.Repanic(false).HandleError()
+				panic("report 1")
+			}()
+			So(failed(), ShouldHaveLength, 1)
+			So(errors.Is(failed()[0], ErrRecoveredPanic), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package raygun4go
+
+import (
+	"context"
+	"sync"
+)
+
+// submitResult receives the result of the delivery of a report submitted by
+// SubmitAsync. It is resolved exactly once, the first result wins.
+type submitResult struct {
+	once sync.Once
+	ch   chan error
+}
+
+// newSubmitResult returns an unresolved result.
+func newSubmitResult() *submitResult {
+	return &submitResult{ch: make(chan error, 1)}
+}
+
+// resolve sends err to the channel of the result unless it was resolved
+// before. A nil result is ignored.
+func (r *submitResult) resolve(err error) {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		r.ch <- err
+	})
+}
+
+// SubmitAsync submits the given post like Submit without waiting for its
+// delivery, and returns a channel receiving the result once it is known: nil
+// if Raygun accepted the report, and the error of the delivery otherwise. In
+// asynchronous or batching mode, the post is queued by the calling goroutine
+// and its result is the one of the delivery in the background, or the reason
+// it was dropped, e.g. ErrQueueFull or ErrClientClosed. The channel is
+// buffered and receives exactly one value, so it may be ignored without
+// leaking a goroutine.
+func (c *Client) SubmitAsync(post PostData) <-chan error {
+	result := newSubmitResult()
+	if c == nil {
+		result.resolve(ErrNoClient)
+		return result.ch
+	}
+
+	if c.asynchronous || c.batch != nil {
+		c.submitAsync(result, post)
+	} else {
+		go c.submitAsync(result, post)
+	}
+	return result.ch
+}
+
+// submitAsync submits the given post and resolves result unless the post was
+// queued, in which case the queue resolves it. A panic resolves result with
+// an error instead of escaping.
+func (c *Client) submitAsync(result *submitResult, post PostData) {
+	defer func() {
+		if e := recover(); e != nil {
+			result.resolve(c.submissionPanic(post, e))
+		}
+	}()
+
+	if c.switchedOff() || c.sampledOut(post, false) {
+		result.resolve(nil)
+		return
+	}
+	if ok, err := c.admit(&post, true); !ok {
+		result.resolve(c.strictResult(post, err))
+		return
+	}
+	if err := c.throttle(post); err != nil {
+		result.resolve(err)
+		return
+	}
+	r := queuedReport{client: c, post: post, result: result}
+	queued, err := c.dispatchReport(context.Background(), r, true)
+	if !queued {
+		result.resolve(c.strictResult(post, err))
+	}
+}
@@ -0,0 +1,96 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubmitAsync(t *testing.T) {
+	Convey("#SubmitAsync", t, func() {
+		server := rayguntest.New()
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		Reset(func() {
+			c.Close()
+			server.Close()
+		})
+		post := func(message string) PostData {
+			return c.createPost(errors.New(message), StackTrace{})
+		}
+		// result waits for the result received by the given channel.
+		result := func(ch <-chan error) error {
+			select {
+			case err := <-ch:
+				return err
+			case <-time.After(5 * time.Second):
+				return errors.New("no result")
+			}
+		}
+
+		Convey("receives nil once Raygun accepted the report", func() {
+			So(result(c.SubmitAsync(post("report 1"))), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("receives the error of a failed delivery", func() {
+			server.Fallback(rayguntest.Status(http.StatusServiceUnavailable))
+			var submitErr *SubmitError
+			So(errors.As(result(c.SubmitAsync(post("report 1"))), &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+		})
+
+		Convey("receives the result of the delivery in asynchronous mode", func() {
+			server.Script(rayguntest.Accepted, rayguntest.Status(http.StatusServiceUnavailable))
+			c.Asynchronous(true)
+			first, second := c.SubmitAsync(post("report 1")), c.SubmitAsync(post("report 2"))
+			So(result(first), ShouldBeNil)
+			So(result(second), ShouldNotBeNil)
+		})
+
+		Convey("receives the result of batched reports", func() {
+			c.Batch(10, time.Hour)
+			ch := c.SubmitAsync(post("report 1"))
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(result(ch), ShouldBeNil)
+		})
+
+		Convey("receives ErrClientClosed for reports Close gave up on", func() {
+			server.Script(rayguntest.Slow(time.Minute, rayguntest.Accepted))
+			c.Asynchronous(true).closeTimeout = 50 * time.Millisecond
+			ch := c.SubmitAsync(post("report 1"))
+			So(c.Close(), ShouldNotBeNil)
+			So(result(ch), ShouldEqual, ErrClientClosed)
+		})
+
+		Convey("receives the error of a panic", func() {
+			c.BeforeSend(func(*PostData) bool { panic("Test SubmitAsync") })
+			So(result(c.SubmitAsync(post("report 1"))), ShouldNotBeNil)
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("may be ignored", func() {
+			c.Asynchronous(true)
+			var results []<-chan error
+			for i := 0; i < 10; i++ {
+				results = append(results, c.SubmitAsync(post("report")))
+			}
+			So(c.Flush(context.Background()), ShouldBeNil)
+			for _, ch := range results {
+				So(len(ch), ShouldEqual, 1)
+				So(<-ch, ShouldBeNil)
+				So(len(ch), ShouldEqual, 0)
+			}
+		})
+
+		Convey("is nil-safe", func() {
+			var nilClient *Client
+			So(result(nilClient.SubmitAsync(PostData{})), ShouldEqual, ErrNoClient)
+		})
+	})
+}
@@ -0,0 +1,36 @@
+package raygun4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidPayload is returned by SubmitBytes for payloads that are not
+// valid JSON.
+var ErrInvalidPayload = errors.New("payload is not valid JSON")
+
+// SubmitBytes submits a report that is already encoded as JSON, e.g. one
+// persisted by the application, posting the given bytes as they are instead
+// of encoding a PostData. Silent mode, BufferOnly, batching, asynchronous
+// mode and the Transport apply as for Submit; the BeforeSend hooks and
+// deduplication do not, as they would have to change the payload. The
+// PostData passed to a Transport or captured by a TestingClient is decoded
+// from the payload on a best effort basis, and encodes to the payload again.
+// Payloads that are not valid JSON are rejected with ErrInvalidPayload.
+func (c *Client) SubmitBytes(payload []byte) error {
+	if c == nil {
+		return ErrNoClient
+	}
+	if c.switchedOff() {
+		return nil
+	}
+	if !json.Valid(payload) {
+		return ErrInvalidPayload
+	}
+
+	var post PostData
+	json.Unmarshal(payload, &post)
+	post.raw = append([]byte(nil), payload...)
+	return c.dispatch(context.Background(), post, true)
+}
@@ -0,0 +1,103 @@
+package raygun4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubmitBytes(t *testing.T) {
+	Convey("SubmitBytes", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+		post := c.createPost(errors.New("Test SubmitBytes"), StackTrace{})
+		post.Details.UserCustomData = map[string]interface{}{"amount": 12.50, "ids": []int64{1 << 60}, reportIDKey: "report-1"}
+		payload, _ := json.Marshal(post)
+
+		Convey("posts the payload byte for byte", func() {
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 1)
+			So(string(requests[0].Body), ShouldEqual, string(payload))
+			So(requests[0].Header.Get(idempotencyKeyHeader), ShouldEqual, "report-1")
+		})
+
+		Convey("keeps the formatting of the payload", func() {
+			indented, _ := json.MarshalIndent(post, "", "  ")
+			So(c.SubmitBytes(indented), ShouldBeNil)
+			So(string(server.Requests()[0].Body), ShouldEqual, string(indented))
+		})
+
+		Convey("does not keep the caller's slice", func() {
+			buf := append([]byte(nil), payload...)
+			c.Batch(10, time.Hour)
+			So(c.SubmitBytes(buf), ShouldBeNil)
+			buf[0] = 'x'
+			So(c.Flush(context.Background()), ShouldBeNil)
+			So(string(server.Requests()[0].Body), ShouldEqual, string(payload))
+		})
+
+		Convey("rejects invalid JSON", func() {
+			So(c.SubmitBytes([]byte(`{"occurredOn":`)), ShouldEqual, ErrInvalidPayload)
+			So(c.SubmitBytes(nil), ShouldEqual, ErrInvalidPayload)
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("submits asynchronously", func() {
+			c.Asynchronous(true)
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So(string(server.Requests()[0].Body), ShouldEqual, string(payload))
+		})
+
+		Convey("persists and resumes the payload", func() {
+			dir, _ := os.MkdirTemp("", "raygun4go")
+			Reset(func() { os.RemoveAll(dir) })
+			server.Script(rayguntest.TooManyRequests("60"))
+			c.Batch(10, time.Hour).PersistQueueOnClose(dir)
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+
+			files, _ := storedReportFiles(dir)
+			So(files, ShouldHaveLength, 1)
+			resumed, _ := New("app", "key")
+			resumed.Endpoint(server.URL).Asynchronous(true)
+			So(resumed.Resume(dir), ShouldBeNil)
+			So(resumed.Close(), ShouldBeNil)
+			requests := server.Requests()
+			So(string(requests[len(requests)-1].Body), ShouldEqual, string(payload))
+		})
+
+		Convey("passes a decoded post to transports", func() {
+			transport := NewRecordingTransport()
+			c.Transport(transport)
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			posts := transport.Posts()
+			So(posts, ShouldHaveLength, 1)
+			So(posts[0].Details.Error.Message, ShouldEqual, "Test SubmitBytes")
+			encoded, _ := json.Marshal(posts[0])
+			So(string(encoded), ShouldEqual, string(payload))
+		})
+
+		Convey("buffers the payload", func() {
+			c.BufferOnly(true)
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			So(c.DrainPayloads(), ShouldResemble, [][]byte{payload})
+		})
+
+		Convey("skips the BeforeSend hooks", func() {
+			c.BeforeSend(func(*PostData) bool { return false })
+			So(c.SubmitBytes(payload), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+	})
+}
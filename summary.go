@@ -0,0 +1,47 @@
+package raygun4go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// summaryFrames is the number of in-app frames included in a summary.
+const summaryFrames = 3
+
+// Summary returns a compact rendering of the report for local logging, e.g.
+// when it could not be sent. The first line holds the time, the id of the
+// report, the user and the error message, followed by the top 3 in-app
+// frames and the tags, one line each. The request and custom data are left
+// out and the redaction patterns of the client that created the post are
+// applied, so scrubbed values are never printed.
+func (p PostData) Summary() string {
+	var b strings.Builder
+	b.WriteString(p.OccuredOn)
+	if id := p.ReportID(); id != "" {
+		fmt.Fprintf(&b, " [%s]", id)
+	}
+	if user := p.Details.User.Identifier; user != "" {
+		fmt.Fprintf(&b, " user=%s", user)
+	}
+	fmt.Fprintf(&b, ": %s", p.Details.Error.Message)
+
+	frames := 0
+	for _, frame := range p.Details.Error.StackTrace {
+		if frames == summaryFrames {
+			break
+		}
+		if inAppFrame(frame) {
+			fmt.Fprintf(&b, "\n\tat %s", frame.location())
+			frames++
+		}
+	}
+	if len(p.Details.Tags) > 0 {
+		fmt.Fprintf(&b, "\n\ttags: %s", strings.Join(p.Details.Tags, ", "))
+	}
+
+	summary := b.String()
+	for _, re := range p.redactPatterns {
+		summary = re.ReplaceAllString(summary, redactedValue)
+	}
+	return summary
+}
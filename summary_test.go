@@ -0,0 +1,65 @@
+package raygun4go
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSummary(t *testing.T) {
+	Convey("Summary", t, func() {
+		c, _ := New("app", "key")
+		c.newReportID = func() string { return "report-1" }
+		c.clock = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+		c.User("bob").Tags([]string{"api", "token:sk_live_abc"}).CustomData(map[string]interface{}{"secret": "hidden"})
+		r := httptest.NewRequest("GET", "/path?password=hunter2", nil)
+		r.Header.Set("Authorization", "Bearer abc")
+		c.Request(r)
+		c.RedactPatterns(`sk_live_\w+`)
+
+		st := StackTrace{
+			{PackageName: "runtime", FileName: "panic.go", LineNumber: 1, MethodName: "gopanic"},
+			{PackageName: "main", FileName: "a.go", LineNumber: 10, MethodName: "a"},
+			collapsedFrames(2),
+			{PackageName: "main", FileName: "b.go", LineNumber: 20, MethodName: "b"},
+			{PackageName: "main", FileName: "c.go", LineNumber: 30, MethodName: "c"},
+			{PackageName: "main", FileName: "d.go", LineNumber: 40, MethodName: "d"},
+		}
+		post := c.createPost(errors.New("Failed with key sk_live_xyz"), st)
+		lines := strings.Split(post.Summary(), "\n")
+
+		Convey("starts with the time, id, user and message", func() {
+			So(lines[0], ShouldEqual, "2024-01-02T03:04:05Z [report-1] user=bob: Failed with key [REDACTED]")
+		})
+
+		Convey("lists the top 3 in-app frames", func() {
+			So(lines[1:4], ShouldResemble, []string{
+				"\tat main.a (a.go:10)",
+				"\tat main.b (b.go:20)",
+				"\tat main.c (c.go:30)",
+			})
+		})
+
+		Convey("ends with the tags", func() {
+			So(lines, ShouldHaveLength, 5)
+			So(lines[4], ShouldEqual, "\ttags: api, token:[REDACTED]")
+		})
+
+		Convey("leaves out redacted and unrendered values", func() {
+			summary := post.Summary()
+			So(summary, ShouldNotContainSubstring, "sk_live_")
+			So(summary, ShouldNotContainSubstring, "hunter2")
+			So(summary, ShouldNotContainSubstring, "Bearer")
+			So(summary, ShouldNotContainSubstring, "hidden")
+		})
+
+		Convey("renders posts without stack or tags on one line", func() {
+			post := PostData{OccuredOn: "2024-01-02T03:04:05Z", Details: DetailsData{Error: ErrorData{Message: "boom"}}}
+			So(post.Summary(), ShouldEqual, "2024-01-02T03:04:05Z: boom")
+		})
+	})
+}
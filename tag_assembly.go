@@ -0,0 +1,75 @@
+package raygun4go
+
+// defaultMaxTags is the number of tags sent with a report unless configured
+// otherwise by MaxTags.
+const defaultMaxTags = 64
+
+// omittedTagsKey is the custom data key the tags exceeding the maximum
+// number of tags are stored under.
+const omittedTagsKey = "omittedTags"
+
+// reportTags holds the tags of a report by their source. assembleTags merges
+// the sources in the order of the fields.
+type reportTags struct {
+	context     []string // set by Tags and TypedTags, the middleware and Go
+	report      []string // passed to the sending method with WithTags
+	headers     []string // mapped from request headers, see HeaderTagMapping
+	service     []string // the service tag, see ServiceInfo
+	breadcrumbs []string // added together with breadcrumbs, e.g. by database errors
+	runtime     []string // added by raygun4go for the error, e.g. incident, http-status or timeout
+}
+
+// WithTags adds the given tags to a single report, after the tags of the
+// context.
+func WithTags(tags ...string) ReportOption {
+	return func(o *reportOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// MaxTags is a chainable option-setting method to set the maximum number of
+// tags sent with a report, 64 by default. The tags of a report are assembled
+// in a fixed order: the tags of the context set by Tags, TypedTags, the
+// middleware and Go, then those passed with WithTags, the tags mapped from
+// request headers, the service tag, the tags added with breadcrumbs, and
+// finally the tags raygun4go adds for the error itself, like incident,
+// http-status, timeout or oom-suspect. Tags are normalized if NormalizeTags
+// is set, and only the first of equal tags is kept. Tags exceeding the
+// maximum are dropped and listed in the custom data under "omittedTags".
+// BeforeSend hooks receive the assembled tags and may still change them. A
+// non-positive maximum restores the default.
+func (c *Client) MaxTags(max int) *Client {
+	if c == nil {
+		return nil
+	}
+	if max <= 0 {
+		max = defaultMaxTags
+	}
+	c.maxTags = max
+	return c
+}
+
+// assembleTags merges the given tags in the order documented for MaxTags,
+// normalizing them if normalize is set and dropping duplicates. It returns
+// at most max tags, and the ones exceeding it. The result is nil if there
+// are no tags.
+func assembleTags(t reportTags, normalize bool, max int) (tags, omitted []string) {
+	seen := make(map[string]bool)
+	for _, source := range [][]string{t.context, t.report, t.headers, t.service, t.breadcrumbs, t.runtime} {
+		if normalize {
+			source = normalizeTagList(source)
+		}
+		for _, tag := range source {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if len(tags) < max {
+				tags = append(tags, tag)
+			} else {
+				omitted = append(omitted, tag)
+			}
+		}
+	}
+	return tags, omitted
+}
@@ -0,0 +1,130 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTagAssembly(t *testing.T) {
+	Convey("Tag assembly", t, func() {
+		defaultProbe := probeFileDescriptors
+		Reset(func() { probeFileDescriptors = defaultProbe })
+		probeFileDescriptors = func() (fileDescriptorSnapshot, bool) { return fileDescriptorSnapshot{}, false }
+
+		c, _ := New("app", "key")
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Tenant", "acme")
+		c.Request(r)
+
+		// The sources of tags in the documented order, each with the tag it
+		// contributes.
+		sources := []struct {
+			name  string
+			setup func(opts *reportOptions)
+			tag   string
+		}{
+			{"context", func(*reportOptions) { c.Tags([]string{"context"}) }, "context"},
+			{"report", func(opts *reportOptions) { WithTags("report")(opts) }, "report"},
+			{"headers", func(*reportOptions) { c.HeaderTagMapping(map[string]string{"X-Tenant": "tenant"}) }, "tenant:acme"},
+			{"service", func(*reportOptions) { c.ServiceInfo("checkout", "") }, "service:checkout"},
+			{"breadcrumbs", func(*reportOptions) { c.breadcrumbs.tag(databaseTag) }, "database"},
+			{"incident", func(*reportOptions) { c.SetIncidentReference("INC-1") }, "incident:INC-1"},
+			{"http-status", func(opts *reportOptions) { opts.httpStatus = 503 }, "http-status:503"},
+			{"timeout", nil, "timeout"},
+			{"resource-exhaustion", nil, "resource-exhaustion"},
+			{"runtime", func(opts *reportOptions) { opts.runtimeTags = append(opts.runtimeTags, oomSuspectTag) }, "oom-suspect"},
+		}
+		err := fmt.Errorf("open: too many open files: %w", context.DeadlineExceeded)
+
+		Convey("merges all sources in order", func() {
+			var opts reportOptions
+			var expected []string
+			for _, source := range sources {
+				if source.setup != nil {
+					source.setup(&opts)
+				}
+				expected = append(expected, source.tag)
+			}
+			post := c.createPostWithOptions(err, StackTrace{}, opts)
+			So(post.Details.Tags, ShouldResemble, expected)
+		})
+
+		Convey("takes each source into account", func() {
+			for _, source := range sources {
+				if source.setup == nil {
+					continue
+				}
+				c, _ = New("app", "key")
+				c.Request(r)
+				var opts reportOptions
+				source.setup(&opts)
+				post := c.createPostWithOptions(errors.New("Test TagAssembly"), StackTrace{}, opts)
+				So(post.Details.Tags, ShouldResemble, []string{source.tag})
+			}
+		})
+
+		Convey("keeps the first of equal tags, case-sensitively", func() {
+			c.Tags([]string{"a", "shared", "a"}).ServiceInfo("shared", "")
+			c.breadcrumbs.tag("A")
+			post := c.createPostWithOptions(errors.New("Test TagAssembly"), StackTrace{}, newReportOptions([]ReportOption{WithTags("b", "shared")}))
+			So(post.Details.Tags, ShouldResemble, []string{"a", "shared", "b", "service:shared", "A"})
+		})
+
+		Convey("deduplicates after normalization", func() {
+			c.NormalizeTags(true).Tags([]string{"Checkout", "checkout "})
+			post := c.createPostWithOptions(errors.New("Test TagAssembly"), StackTrace{}, newReportOptions([]ReportOption{WithTags("CHECKOUT", "Env:Prod")}))
+			So(post.Details.Tags, ShouldResemble, []string{"checkout", "env:Prod"})
+		})
+
+		Convey("caps the number of tags", func() {
+			var many []string
+			for i := 0; i < 70; i++ {
+				many = append(many, fmt.Sprintf("tag-%d", i))
+			}
+			c.Tags(many)
+			post := c.createPost(errors.New("Test TagAssembly"), StackTrace{})
+			So(post.Details.Tags, ShouldHaveLength, defaultMaxTags)
+			custom := post.Details.UserCustomData.(map[string]interface{})
+			So(custom[omittedTagsKey], ShouldResemble, many[defaultMaxTags:])
+
+			Convey("at a configurable maximum", func() {
+				c.MaxTags(2)
+				post := c.createPost(errors.New("Test TagAssembly"), StackTrace{})
+				So(post.Details.Tags, ShouldResemble, []string{"tag-0", "tag-1"})
+				So(post.Details.UserCustomData.(map[string]interface{})[omittedTagsKey], ShouldHaveLength, 68)
+
+				c.MaxTags(0)
+				So(c.maxTags, ShouldEqual, defaultMaxTags)
+			})
+		})
+
+		Convey("sends no tags if there are none", func() {
+			post := c.createPost(errors.New("Test TagAssembly"), StackTrace{})
+			So(post.Details.Tags, ShouldBeNil)
+			So(post.Details.UserCustomData, ShouldNotContainKey, omittedTagsKey)
+		})
+
+		Convey("hands the assembled tags to BeforeSend", func() {
+			server := rayguntest.New()
+			Reset(server.Close)
+			c.Endpoint(server.URL).Tags([]string{"context"}).ServiceInfo("checkout", "")
+			var seen []string
+			c.BeforeSend(func(post *PostData) bool {
+				seen = copyStrings(post.Details.Tags)
+				post.Details.Tags = append(post.Details.Tags, "final")
+				return true
+			})
+			So(c.SendError(errors.New("Test TagAssembly"), WithTags("report")), ShouldBeNil)
+			So(seen, ShouldResemble, []string{"context", "report", "service:checkout"})
+			var sent PostData
+			So(server.Requests()[0].Decode(&sent), ShouldBeNil)
+			So(sent.Details.Tags, ShouldResemble, []string{"context", "report", "service:checkout", "final"})
+		})
+	})
+}
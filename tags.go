@@ -0,0 +1,101 @@
+package raygun4go
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTagValueLength is the number of characters the value of a normalized
+// tag is truncated to.
+const maxTagValueLength = 100
+
+// Tag is a normalized tag. Tags are sent to Raygun as plain strings, the type
+// only guarantees they were built by one of the constructors below.
+type Tag string
+
+// NewTag returns the given name as a normalized tag: lowercased, with
+// surrounding whitespace removed and all characters but letters, digits and
+// "-_./" replaced by "_".
+func NewTag(name string) Tag {
+	return Tag(normalizeTagPart(strings.ToLower(name), false))
+}
+
+// KVTag returns a normalized "key:value" tag. The key is normalized as by
+// NewTag, the value keeps its case, may contain ":" and is truncated to 100
+// characters.
+func KVTag(key, value string) Tag {
+	key = normalizeTagPart(strings.ToLower(key), false)
+	value = normalizeTagPart(value, true)
+	if runes := []rune(value); len(runes) > maxTagValueLength {
+		value = string(runes[:maxTagValueLength])
+	}
+	return Tag(key + ":" + value)
+}
+
+// normalizeTag normalizes a plain string tag, treating everything after the
+// first ":" as its value.
+func normalizeTag(tag string) Tag {
+	if i := strings.Index(tag, ":"); i >= 0 {
+		return KVTag(tag[:i], tag[i+1:])
+	}
+	return NewTag(tag)
+}
+
+// normalizeTagPart trims the given key or value and replaces all invalid
+// characters.
+func normalizeTagPart(s string, isValue bool) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./", r) || (isValue && r == ':') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(s))
+}
+
+// normalizeTagList returns the normalized tags, dropping duplicates and empty
+// ones.
+func normalizeTagList(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		n := string(normalizeTag(tag))
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		normalized = append(normalized, n)
+	}
+	return normalized
+}
+
+// TypedTags is a chainable option-setting method to set the tags of the
+// context from normalized tags, see NewTag and KVTag. It replaces tags set by
+// Tags.
+func (c *Client) TypedTags(tags ...Tag) *Client {
+	if c == nil {
+		return nil
+	}
+	plain := make([]string, len(tags))
+	for i, tag := range tags {
+		plain[i] = string(tag)
+	}
+	c.contextMu.Lock()
+	c.context.Tags = plain
+	c.contextMu.Unlock()
+	return c
+}
+
+// NormalizeTags is a chainable option-setting method to normalize the plain
+// string tags of every report the way KVTag does for tags containing a ":"
+// and NewTag does for all others. Tags that are equal after normalization are
+// sent once.
+func (c *Client) NormalizeTags(normalize bool) *Client {
+	if c == nil {
+		return nil
+	}
+	c.normalizeTags = normalize
+	return c
+}
@@ -0,0 +1,59 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTags(t *testing.T) {
+	Convey("Tags", t, func() {
+		Convey("#NewTag lowercases and replaces invalid characters", func() {
+			So(NewTag(" DB "), ShouldEqual, Tag("db"))
+			So(NewTag("Payment Service!"), ShouldEqual, Tag("payment_service_"))
+			So(NewTag("api/v1.2-beta_x"), ShouldEqual, Tag("api/v1.2-beta_x"))
+			So(NewTag("a:b"), ShouldEqual, Tag("a_b"))
+		})
+
+		Convey("#KVTag", func() {
+			So(KVTag("Region", "EU-West"), ShouldEqual, Tag("region:EU-West"))
+			So(KVTag("Host Name", "db 1:5432"), ShouldEqual, Tag("host_name:db_1:5432"))
+
+			Convey("truncates the value", func() {
+				tag := KVTag("k", strings.Repeat("é", 150))
+				So(tag, ShouldEqual, Tag("k:"+strings.Repeat("é", 100)))
+			})
+		})
+
+		Convey("on the wire", func() {
+			c, _ := New("app", "key")
+			c.Silent(true)
+
+			Convey("typed tags are plain strings", func() {
+				c.TypedTags(NewTag("DB"), KVTag("Region", "eu"))
+				post := c.createPost(errors.New("Test TypedTags"), StackTrace{})
+
+				data, _ := json.Marshal(post.Details)
+				So(string(data), ShouldContainSubstring, `"tags":["db","region:eu"]`)
+			})
+
+			Convey("plain tags are sent as given by default", func() {
+				c.Tags([]string{"db", "DB"})
+				post := c.createPost(errors.New("Test Tags"), StackTrace{})
+				So(post.Details.Tags, ShouldResemble, []string{"db", "DB"})
+			})
+
+			Convey("#NormalizeTags normalizes plain tags and drops duplicates", func() {
+				tags := []string{"db", "DB", " db ", "Region:EU", "region:EU", ""}
+				c.Tags(tags).NormalizeTags(true)
+				post := c.createPost(errors.New("Test NormalizeTags"), StackTrace{})
+
+				So(post.Details.Tags, ShouldResemble, []string{"db", "region:EU"})
+				So(c.context.Tags, ShouldResemble, []string{"db", "DB", " db ", "Region:EU", "region:EU", ""})
+			})
+		})
+	})
+}
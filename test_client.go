@@ -0,0 +1,136 @@
+package raygun4go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// testClientTime is the time reports of a TestingClient occur at unless its
+// clock is replaced.
+var testClientTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testClientMachine is the machine name of reports of a TestingClient.
+const testClientMachine = "test-machine"
+
+// ErrTestClient is returned when trying to point a TestingClient at Raygun.
+var ErrTestClient = errors.New("raygun4go test client cannot send to Raygun")
+
+// TestingClient is a client for testing how an application reports errors. It
+// never touches the network and never prints, but captures the reports it
+// would have sent; see NewTestClient.
+type TestingClient struct {
+	*Client
+}
+
+// NewTestClient returns a client capturing reports instead of sending them,
+// for unit tests of error paths. Reports are submitted synchronously and
+// deterministically: they occur at 2000-01-01T00:00:00Z unless Clock is
+// used, carry the report ids "report-1", "report-2" and so on, and the
+// machine name "test-machine". The BeforeSend hooks, deduplication and other
+// options apply as usual, and clones capture into the same list.
+//
+// Options pointing the client at Raygun, i.e. Endpoint, Region, their Set
+// variants, SetAPIKey, HTTPClient, Proxy and RelaySocket, panic when called
+// on the embedded Client; the ones of TestingClient return ErrTestClient
+// instead.
+func NewTestClient() *TestingClient {
+	c, _ := New("test", "test-client")
+	c.context.identifier = "test-client"
+	c.clock = func() time.Time { return testClientTime }
+	c.occurrence = newOccurrenceClock()
+	c.bufferOnly = false
+	c.capture = &reportCapture{}
+	c.newReportID = c.capture.nextID
+	return &TestingClient{Client: c}
+}
+
+// Reports returns the reports captured so far, oldest first.
+func (t *TestingClient) Reports() []PostData {
+	return t.capture.snapshot()
+}
+
+// ClearReports removes all captured reports. Report ids start at
+// "report-1" again.
+func (t *TestingClient) ClearReports() {
+	t.capture.clear()
+}
+
+// Clock is a chainable method to set the function returning the time reports
+// occur at, instead of the fixed time.
+func (t *TestingClient) Clock(now func() time.Time) *TestingClient {
+	t.clock = now
+	return t
+}
+
+// Endpoint returns ErrTestClient, as a TestingClient never sends reports.
+func (t *TestingClient) Endpoint(string) error {
+	return ErrTestClient
+}
+
+// Region returns ErrTestClient, as a TestingClient never sends reports.
+func (t *TestingClient) Region(string) error {
+	return ErrTestClient
+}
+
+// SetEndpoint returns ErrTestClient, as a TestingClient never sends reports.
+func (t *TestingClient) SetEndpoint(string) error {
+	return ErrTestClient
+}
+
+// SetRegion returns ErrTestClient, as a TestingClient never sends reports.
+func (t *TestingClient) SetRegion(string) error {
+	return ErrTestClient
+}
+
+// SetAPIKey returns ErrTestClient, as a TestingClient never sends reports.
+func (t *TestingClient) SetAPIKey(string) error {
+	return ErrTestClient
+}
+
+// reportCapture holds the reports of a TestingClient.
+type reportCapture struct {
+	mu      sync.Mutex
+	reports []PostData
+	ids     int
+}
+
+// record adds the given report, fixing the machine name.
+func (r *reportCapture) record(post PostData) {
+	post.Details.MachineName = testClientMachine
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, post)
+}
+
+// snapshot returns a copy of the captured reports.
+func (r *reportCapture) snapshot() []PostData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]PostData(nil), r.reports...)
+}
+
+// clear removes all captured reports and restarts the report ids.
+func (r *reportCapture) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = nil
+	r.ids = 0
+}
+
+// nextID returns the next deterministic report id.
+func (r *reportCapture) nextID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids++
+	return fmt.Sprintf("report-%d", r.ids)
+}
+
+// refuseLiveMode panics if the client is a test client, which must never be
+// pointed at Raygun. The given option names the offending call.
+func (c *Client) refuseLiveMode(option string) {
+	if c.capture != nil {
+		panic(fmt.Sprintf("raygun4go: %s called on a test client, which must never send to Raygun", option))
+	}
+}
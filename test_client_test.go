@@ -0,0 +1,74 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewTestClient(t *testing.T) {
+	Convey("NewTestClient", t, func() {
+		server := newRecordingServer()
+		Reset(server.Close)
+		c := NewTestClient()
+
+		Convey("captures reports instead of sending them", func() {
+			c.User("alice").Tags([]string{"checkout"})
+			So(c.SendError(errors.New("Test NewTestClient")), ShouldBeNil)
+			So(c.Clone().CreateError("Test NewTestClient clone"), ShouldBeNil)
+			So(server.posts, ShouldBeEmpty)
+
+			reports := c.Reports()
+			So(reports, ShouldHaveLength, 2)
+			So(reports[0].Details.Error.Message, ShouldEqual, "Test NewTestClient")
+			So(reports[0].Details.User.Identifier, ShouldEqual, "alice")
+			So(reports[1].Details.Error.Message, ShouldEqual, "Test NewTestClient clone")
+		})
+
+		Convey("encodes reports deterministically", func() {
+			c.SendError(errors.New("Test NewTestClient"))
+			first, _ := json.Marshal(c.Reports()[0].Details.UserCustomData)
+			So(c.Reports()[0].OccuredOn, ShouldEqual, "2000-01-01T00:00:00Z")
+			So(c.Reports()[0].ReportID(), ShouldEqual, "report-1")
+			So(c.Reports()[0].Details.MachineName, ShouldEqual, "test-machine")
+			So(c.Reports()[0].Details.Context.Identifier, ShouldEqual, "test-client")
+
+			c.ClearReports()
+			c.SendError(errors.New("Test NewTestClient"))
+			second, _ := json.Marshal(c.Reports()[0].Details.UserCustomData)
+			So(string(second), ShouldEqual, string(first))
+		})
+
+		Convey("allows overriding the clock", func() {
+			c.Clock(func() time.Time { return time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC) })
+			c.SendError(errors.New("Test NewTestClient"))
+			So(c.Reports()[0].OccuredOn, ShouldEqual, "2024-05-06T07:08:09Z")
+		})
+
+		Convey("applies hooks", func() {
+			c.BeforeSend(func(post *PostData) bool { return false })
+			So(c.SendError(errors.New("Test NewTestClient")), ShouldEqual, ErrReportCancelled)
+			So(c.Reports(), ShouldBeEmpty)
+		})
+
+		Convey("refuses to be pointed at Raygun", func() {
+			So(c.Endpoint("https://api.raygun.com"), ShouldEqual, ErrTestClient)
+			So(c.SetAPIKey("real-key"), ShouldEqual, ErrTestClient)
+			So(c.SetEndpoint("https://api.raygun.com"), ShouldEqual, ErrTestClient)
+			So(c.SetRegion("eu"), ShouldEqual, ErrTestClient)
+			So(func() { c.Client.SetEndpoint("https://api.raygun.com") }, ShouldPanic)
+			So(func() { c.Client.SetRegion("eu") }, ShouldPanic)
+			So(c.Region("eu"), ShouldEqual, ErrTestClient)
+			So(func() { c.Client.Endpoint("https://api.raygun.com") }, ShouldPanic)
+			So(func() { c.Client.Region("eu") }, ShouldPanic)
+			So(func() { c.Clone().SetAPIKey("real-key") }, ShouldPanic)
+			So(func() { c.Clone().HTTPClient(&http.Client{}) }, ShouldPanic)
+			So(func() { c.Proxy("http://proxy:3128") }, ShouldPanic)
+			So(func() { c.RelaySocket("/var/run/raygun-relay.sock") }, ShouldPanic)
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// testReportTag is the tag of the reports sent by SendTestReport.
+	testReportTag = "raygun4go-test"
+
+	// testReportDataKey is the custom data key the sample data of test
+	// reports is stored under.
+	testReportDataKey = "testReport"
+
+	// maxTestReportFrames is the number of stack frames sent with test
+	// reports at most.
+	maxTestReportFrames = 5
+)
+
+// SendTestReport sends a synthetic report to check that the client is set up
+// correctly, e.g. when onboarding a service, and returns its id to look it
+// up in Raygun. The report is tagged "raygun4go-test", its message names the
+// host and the raygun4go version, and it carries the current stack and
+// sample custom data under "testReport" with values redacted by the
+// redaction rules of the client, showing how they apply. It is sent
+// synchronously even in asynchronous mode and is not subject to
+// deduplication, but the BeforeSend hooks run, silent mode prints it instead
+// and a TestingClient captures it.
+func (c *Client) SendTestReport(ctx context.Context) (SubmitResult, error) {
+	if c == nil {
+		return SubmitResult{}, ErrNoClient
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown host"
+	}
+	message := fmt.Sprintf("raygun4go test report from %s (raygun4go %s)", hostname, packageVersion)
+	st := currentStack()
+	if len(st) > maxTestReportFrames {
+		st = st[:maxTestReportFrames]
+	}
+
+	var opts reportOptions
+	WithTags(testReportTag)(&opts)
+	opts.addCustomData(testReportDataKey, c.testReportData())
+	post := c.createPostWithOptions(errors.New(message), st, opts)
+
+	result := SubmitResult{ReportID: post.ReportID()}
+	if ok, err := c.admit(&post, false); !ok {
+		return result, c.strictResult(post, err)
+	}
+	return result, c.strictResult(post, c.dispatch(ctx, post, false))
+}
+
+// testReportData returns the sample custom data of test reports, redacted
+// like request fields.
+func (c *Client) testReportData() map[string]string {
+	return c.redaction.redactMap(map[string]string{
+		"description":   "Sent by SendTestReport to check the Raygun setup, safe to ignore",
+		"password":      "not-a-real-password",
+		"authorization": "Bearer not-a-real-token",
+		"orderId":       "4711",
+	}, c.redaction.config.Fields)
+}
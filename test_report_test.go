@@ -0,0 +1,103 @@
+package raygun4go
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSendTestReport(t *testing.T) {
+	Convey("SendTestReport", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("sends a labelled synthetic report", func() {
+			result, err := c.SendTestReport(context.Background())
+			So(err, ShouldBeNil)
+			So(result.ReportID, ShouldNotBeEmpty)
+
+			requests := server.Requests()
+			So(requests, ShouldHaveLength, 1)
+			So(requests[0].Problems, ShouldBeEmpty)
+			var post struct {
+				Details struct {
+					Error struct {
+						Message    string
+						StackTrace []StackTraceElement
+					}
+					Tags           []string
+					UserCustomData map[string]interface{}
+				}
+			}
+			So(requests[0].Decode(&post), ShouldBeNil)
+
+			hostname, _ := os.Hostname()
+			So(post.Details.Error.Message, ShouldContainSubstring, hostname)
+			So(post.Details.Error.Message, ShouldContainSubstring, packageVersion)
+			So(post.Details.Error.StackTrace, ShouldNotBeEmpty)
+			So(len(post.Details.Error.StackTrace), ShouldBeLessThanOrEqualTo, maxTestReportFrames)
+			So(post.Details.Error.StackTrace[0].MethodName, ShouldStartWith, "TestSendTestReport")
+			So(post.Details.Tags, ShouldResemble, []string{testReportTag})
+			So(post.Details.UserCustomData[reportIDKey], ShouldEqual, result.ReportID)
+			So(post.Details.UserCustomData[testReportDataKey], ShouldResemble, map[string]interface{}{
+				"description":   "Sent by SendTestReport to check the Raygun setup, safe to ignore",
+				"password":      redactedValue,
+				"authorization": redactedValue,
+				"orderId":       "4711",
+			})
+		})
+
+		Convey("bypasses deduplication", func() {
+			c.dedup = newDeduplicator(time.Hour)
+			for i := 0; i < 3; i++ {
+				_, err := c.SendTestReport(context.Background())
+				So(err, ShouldBeNil)
+			}
+			So(server.Count(), ShouldEqual, 3)
+		})
+
+		Convey("is sent synchronously in asynchronous mode", func() {
+			c.Asynchronous(true)
+			Reset(func() { c.Close() })
+			_, err := c.SendTestReport(context.Background())
+			So(err, ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+		})
+
+		Convey("returns the error of the submission", func() {
+			server.Script(rayguntest.Unauthorized)
+			_, err := c.SendTestReport(context.Background())
+			So(IsInvalidAPIKey(err), ShouldBeTrue)
+		})
+
+		Convey("is not sent in silent mode", func() {
+			c.Silent(true)
+			_, err := c.SendTestReport(context.Background())
+			So(err, ShouldBeNil)
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("is captured by a TestingClient", func() {
+			tc := NewTestClient()
+			result, err := tc.SendTestReport(context.Background())
+			So(err, ShouldBeNil)
+			So(result.ReportID, ShouldEqual, "report-1")
+			So(tc.Reports(), ShouldHaveLength, 1)
+			So(tc.Reports()[0].Details.Tags, ShouldResemble, []string{testReportTag})
+		})
+
+		Convey("can be cancelled by a BeforeSend hook", func() {
+			c.BeforeSend(func(*PostData) bool { return false })
+			_, err := c.SendTestReport(context.Background())
+			So(err, ShouldEqual, ErrReportCancelled)
+			So(server.Count(), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+)
+
+// The tags of reported errors caused by a timeout or a cancellation.
+const (
+	timeoutTag  = "timeout"
+	canceledTag = "canceled"
+)
+
+// timeoutError is implemented by errors telling whether they are caused by
+// a timeout, like net.Error.
+type timeoutError interface {
+	Timeout() bool
+}
+
+// isTimeout reports whether err is caused by a timeout, i.e. it is or wraps
+// context.DeadlineExceeded or an error whose Timeout method returns true.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var t timeoutError
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// isCanceled reports whether err is caused by a cancellation, i.e. it is or
+// wraps context.Canceled.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// timeoutTags returns the tag "timeout" or "canceled" if err was caused by a
+// timeout or a cancellation.
+func timeoutTags(err error) []string {
+	switch {
+	case isTimeout(err):
+		return []string{timeoutTag}
+	case isCanceled(err):
+		return []string{canceledTag}
+	}
+	return nil
+}
+
+// newSubmitError returns the error of a failed submission request, telling
+// whether it timed out or was canceled.
+func (c *Client) newSubmitError(err error) *SubmitError {
+	return &SubmitError{
+		Err:      err,
+		Socket:   c.relaySocket,
+		Timeout:  isTimeout(err),
+		Canceled: isCanceled(err),
+	}
+}
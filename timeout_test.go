@@ -0,0 +1,83 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// netTimeout is a net.Error telling whether it is a timeout.
+type netTimeout struct{ timeout bool }
+
+func (e netTimeout) Error() string   { return "i/o timeout" }
+func (e netTimeout) Timeout() bool   { return e.timeout }
+func (e netTimeout) Temporary() bool { return false }
+
+var _ net.Error = netTimeout{}
+
+func TestTimeouts(t *testing.T) {
+	Convey("Timeouts and cancellations", t, func() {
+		c, _ := New("app", "key")
+		tags := func(err error) []string {
+			return c.createPost(err, StackTrace{}).Details.Tags
+		}
+
+		Convey("are tagged on reported errors", func() {
+			So(tags(netTimeout{timeout: true}), ShouldContain, "timeout")
+			So(tags(fmt.Errorf("reading: %w", netTimeout{timeout: true})), ShouldContain, "timeout")
+			So(tags(fmt.Errorf("query: %w", context.DeadlineExceeded)), ShouldContain, "timeout")
+			So(tags(fmt.Errorf("query: %w", context.Canceled)), ShouldResemble, []string{"canceled"})
+		})
+
+		Convey("are not tagged for other errors", func() {
+			So(tags(netTimeout{timeout: false}), ShouldBeEmpty)
+			So(tags(errors.New("Test Timeouts")), ShouldBeEmpty)
+		})
+
+		Convey("are told apart in submission errors", func() {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			Reset(func() {
+				close(release)
+				server.Close()
+			})
+			c.Endpoint(server.URL)
+			post := c.createPost(errors.New("Test Timeouts"), StackTrace{})
+			var submitErr *SubmitError
+
+			c.HTTPClient(&http.Client{Timeout: 50 * time.Millisecond})
+			err := c.Submit(post)
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeTrue)
+			So(submitErr.Canceled, ShouldBeFalse)
+
+			c.HTTPClient(nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err = c.submitCoreWithContext(ctx, post)
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeTrue)
+			So(submitErr.Canceled, ShouldBeFalse)
+
+			ctx, cancel = context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			err = c.submitCoreWithContext(ctx, post)
+			So(errors.As(err, &submitErr), ShouldBeTrue)
+			So(submitErr.Timeout, ShouldBeFalse)
+			So(submitErr.Canceled, ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package raygun4go
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// TLSConfig is a chainable option-setting method to set the TLS
+// configuration of the connection reports are posted over, e.g. to trust the
+// private CA of a TLS-terminating egress gateway by setting RootCAs. It only
+// affects the connection to Raygun and is combined with the proxy set by
+// Proxy. The configuration is copied, so changing it afterwards has no
+// effect. An HTTP client set with HTTPClient or a relay socket take
+// precedence. Passing nil restores the default.
+func (c *Client) TLSConfig(config *tls.Config) *Client {
+	if c == nil {
+		return nil
+	}
+	c.tlsConfig = nil
+	if config != nil {
+		c.tlsConfig = config.Clone()
+	}
+	c.updateTransport()
+	return c
+}
+
+// updateTransport sets up the client posting reports with the proxy, the
+// TLS configuration and the connection options, if any.
+func (c *Client) updateTransport() {
+	if c.proxyURL == nil && c.tlsConfig == nil && c.connections == nil {
+		c.transportClient = nil
+		return
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(c.proxyURL)
+	}
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig
+	}
+	c.applyConnectionOptions(transport)
+	c.transportClient = &http.Client{Transport: transport}
+}
@@ -0,0 +1,65 @@
+package raygun4go
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTLSConfig(t *testing.T) {
+	Convey("TLSConfig", t, func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		Reset(server.Close)
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL)
+
+		Convey("is needed to trust a private CA", func() {
+			var submitErr *SubmitError
+			So(errors.As(c.SendError(errors.New("Test TLSConfig")), &submitErr), ShouldBeTrue)
+		})
+
+		Convey("is used to post reports", func() {
+			c.TLSConfig(&tls.Config{RootCAs: pool})
+			So(c.SendError(errors.New("Test TLSConfig")), ShouldBeNil)
+
+			Convey("by clones", func() {
+				So(c.Clone().SendError(errors.New("Test TLSConfig")), ShouldBeNil)
+			})
+		})
+
+		Convey("is combined with the proxy", func() {
+			c.TLSConfig(&tls.Config{RootCAs: pool}).Proxy("http://proxy:3128")
+			transport := c.postClient().Transport.(*http.Transport)
+			So(transport.TLSClientConfig.RootCAs, ShouldEqual, pool)
+			So(transport.Proxy, ShouldNotBeNil)
+
+			Convey("which can be reset on its own", func() {
+				c.Proxy("")
+				So(c.SendError(errors.New("Test TLSConfig")), ShouldBeNil)
+			})
+		})
+
+		Convey("is copied", func() {
+			config := &tls.Config{}
+			c.TLSConfig(config)
+			config.RootCAs = pool
+			var submitErr *SubmitError
+			So(errors.As(c.SendError(errors.New("Test TLSConfig")), &submitErr), ShouldBeTrue)
+		})
+
+		Convey("can be reset", func() {
+			c.TLSConfig(&tls.Config{RootCAs: pool}).TLSConfig(nil)
+			So(c.postClient(), ShouldEqual, defaultHTTPClient)
+		})
+	})
+}
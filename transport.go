@@ -0,0 +1,120 @@
+package raygun4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Transport delivers reports, e.g. through a message bus instead of posting
+// them to Raygun. Send is called with every report the client submits,
+// synchronously or from the asynchronous queue, and with a context limited
+// by the client Timeout. Its error is returned by the sending methods.
+type Transport interface {
+	Send(ctx context.Context, post PostData) error
+}
+
+// Transport is a chainable option-setting method to deliver reports with the
+// given transport instead of posting them to Raygun. The options of the HTTP
+// delivery, like Retries, Compression, CircuitBreaker or the handling of
+// rate limits and invalid API keys, do not apply to other transports; the
+// BeforeSend hooks, deduplication and asynchronous mode do. Silent mode and
+// BufferOnly take precedence over the transport. Passing nil restores the
+// default of posting reports to Raygun.
+func (c *Client) Transport(t Transport) *Client {
+	if c == nil {
+		return nil
+	}
+	c.transport = t
+	return c
+}
+
+// httpTransport posts reports to Raygun with the configuration of a client.
+// It is the default transport.
+type httpTransport struct {
+	c *Client
+}
+
+// Send posts the given report to Raygun, and keeps it in the offline
+// storage if that failed because of an outage or the client is offline.
+func (t httpTransport) Send(ctx context.Context, post PostData) error {
+	if t.c.offline.isOffline() {
+		t.c.spill(post, ErrOffline)
+		return ErrOffline
+	}
+	err := t.c.postReport(ctx, post)
+	t.c.offline.observe(err)
+	t.c.spill(post, err)
+	return err
+}
+
+// consoleTransport prints reports to stdout as indented JSON, with map keys
+// sorted. It is the transport of silent mode.
+type consoleTransport struct{}
+
+// Send prints the given report.
+func (consoleTransport) Send(_ context.Context, post PostData) error {
+	if post.raw != nil {
+		var enc bytes.Buffer
+		json.Indent(&enc, post.raw, "", "\t")
+		fmt.Println(enc.String())
+		return nil
+	}
+	enc, _ := json.MarshalIndent(post, "", "\t")
+	fmt.Println(string(enc))
+	return nil
+}
+
+// deliveryTransport returns the transport delivering the reports of the
+// client.
+func (c *Client) deliveryTransport() Transport {
+	if c.transport != nil {
+		return c.transport
+	}
+	return httpTransport{c: c}
+}
+
+// RecordingTransport is a Transport keeping the reports in memory, for tests
+// of applications reporting errors. It is safe for concurrent use.
+type RecordingTransport struct {
+	mu    sync.Mutex
+	posts []PostData
+	err   error
+}
+
+// NewRecordingTransport returns an empty RecordingTransport.
+func NewRecordingTransport() *RecordingTransport {
+	return &RecordingTransport{}
+}
+
+// Send records the given report and returns the error set by Fail, if any.
+func (t *RecordingTransport) Send(_ context.Context, post PostData) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.posts = append(t.posts, post)
+	return t.err
+}
+
+// Posts returns the reports recorded so far, oldest first.
+func (t *RecordingTransport) Posts() []PostData {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]PostData(nil), t.posts...)
+}
+
+// Clear removes all recorded reports.
+func (t *RecordingTransport) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.posts = nil
+}
+
+// Fail makes Send return the given error after recording a report, to test
+// how failing deliveries are handled. Passing nil makes it succeed again.
+func (t *RecordingTransport) Fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+}
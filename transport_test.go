@@ -0,0 +1,123 @@
+package raygun4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MindscapeHQ/raygun4go/rayguntest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// contextTransport records the contexts it is called with.
+type contextTransport struct {
+	deadlines chan bool
+}
+
+func (t contextTransport) Send(ctx context.Context, post PostData) error {
+	_, ok := ctx.Deadline()
+	t.deadlines <- ok
+	return nil
+}
+
+func TestTransport(t *testing.T) {
+	Convey("Transport", t, func() {
+		server := rayguntest.New()
+		Reset(server.Close)
+
+		transport := NewRecordingTransport()
+		c, _ := New("app", "key")
+		c.Endpoint(server.URL).Transport(transport)
+
+		Convey("delivers reports instead of posting them", func() {
+			So(c.SendError(errors.New("Test Transport")), ShouldBeNil)
+			posts := transport.Posts()
+			So(posts, ShouldHaveLength, 1)
+			So(posts[0].Details.Error.Message, ShouldEqual, "Test Transport")
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("delivers reports from the asynchronous queue", func() {
+			c.Asynchronous(true)
+			So(c.SendError(errors.New("Test Transport")), ShouldBeNil)
+			So(c.Close(), ShouldBeNil)
+			So(transport.Posts(), ShouldHaveLength, 1)
+			So(server.Count(), ShouldEqual, 0)
+		})
+
+		Convey("returns the errors of the transport", func() {
+			failure := errors.New("bus unavailable")
+			transport.Fail(failure)
+			So(c.SendError(errors.New("Test Transport")), ShouldEqual, failure)
+			So(transport.Posts(), ShouldHaveLength, 1)
+		})
+
+		Convey("applies the BeforeSend hooks", func() {
+			c.BeforeSend(func(p *PostData) bool {
+				return p.Details.Error.Message != "skipped"
+			})
+			So(c.SendError(errors.New("skipped")), ShouldEqual, ErrReportCancelled)
+			So(c.SendError(errors.New("sent")), ShouldBeNil)
+			posts := transport.Posts()
+			So(posts, ShouldHaveLength, 1)
+			So(posts[0].Details.Error.Message, ShouldEqual, "sent")
+		})
+
+		Convey("is called with the client timeout", func() {
+			deadlines := make(chan bool, 1)
+			c.Transport(contextTransport{deadlines: deadlines}).Timeout(time.Second)
+			So(c.SendError(errors.New("Test Transport")), ShouldBeNil)
+			So(<-deadlines, ShouldBeTrue)
+		})
+
+		Convey("is shared with clones", func() {
+			So(c.Clone().SendError(errors.New("Test Transport")), ShouldBeNil)
+			So(transport.Posts(), ShouldHaveLength, 1)
+		})
+
+		Convey("is bypassed by silent mode", func() {
+			c.Silent(true)
+			So(c.SendError(errors.New("Test Transport")), ShouldBeNil)
+			So(transport.Posts(), ShouldBeEmpty)
+		})
+
+		Convey("does not apply the HTTP options", func() {
+			c.CircuitBreaker(1, time.Minute)
+			transport.Fail(errors.New("bus unavailable"))
+			So(c.SendError(errors.New("Test Transport")), ShouldNotEqual, ErrCircuitOpen)
+			So(c.SendError(errors.New("Test Transport")), ShouldNotEqual, ErrCircuitOpen)
+			So(transport.Posts(), ShouldHaveLength, 2)
+		})
+
+		Convey("posts to Raygun again once reset", func() {
+			c.Transport(nil)
+			So(c.SendError(errors.New("Test Transport")), ShouldBeNil)
+			So(server.Count(), ShouldEqual, 1)
+			So(transport.Posts(), ShouldBeEmpty)
+		})
+
+		Convey("HTTP errors are unchanged", func() {
+			server.Script(rayguntest.Status(http.StatusBadGateway))
+			c.Transport(nil).Retries(0, 0)
+			var submitErr *SubmitError
+			So(errors.As(c.SendError(errors.New("Test Transport")), &submitErr), ShouldBeTrue)
+			So(submitErr.StatusCode, ShouldEqual, http.StatusBadGateway)
+		})
+	})
+
+	Convey("RecordingTransport", t, func() {
+		transport := NewRecordingTransport()
+		So(transport.Posts(), ShouldBeEmpty)
+		So(transport.Send(context.Background(), PostData{OccuredOn: "1"}), ShouldBeNil)
+		So(transport.Send(context.Background(), PostData{OccuredOn: "2"}), ShouldBeNil)
+		posts := transport.Posts()
+		So(posts, ShouldHaveLength, 2)
+		So(posts[1].OccuredOn, ShouldEqual, "2")
+
+		transport.Clear()
+		So(transport.Posts(), ShouldBeEmpty)
+		So(posts, ShouldHaveLength, 2)
+	})
+}
@@ -2,6 +2,7 @@ package raygun4go
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -18,3 +19,69 @@ func arrayMapToStringMap(arrayMap map[string][]string) map[string]string {
 	}
 	return entries
 }
+
+// copyStrings returns a copy of the given slice, or nil if it is nil.
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// copyStringMap returns a copy of the given map, or nil if it is nil.
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// trimArguments removes the argument list that stack traces append to method
+// names, e.g. "(*scope).visit(0x208326090)" becomes "(*scope).visit".
+func trimArguments(methodName string) string {
+	if !strings.HasSuffix(methodName, ")") {
+		return methodName
+	}
+	if i := strings.LastIndex(methodName, "("); i > 0 {
+		return methodName[:i]
+	}
+	return methodName
+}
+
+// mergeCustomData adds the given entries to the user's custom data. Maps with
+// string keys are copied and extended, entries already set by the user take
+// precedence. Any other custom data is kept under the key "value".
+func mergeCustomData(data interface{}, extra map[string]interface{}) interface{} {
+	if len(extra) == 0 {
+		return data
+	}
+
+	merged := make(map[string]interface{}, len(extra))
+	for k, v := range extra {
+		merged[k] = v
+	}
+	if data == nil {
+		return merged
+	}
+
+	if isStringKeyedMap(data) {
+		iter := reflect.ValueOf(data).MapRange()
+		for iter.Next() {
+			merged[iter.Key().String()] = iter.Value().Interface()
+		}
+		return merged
+	}
+
+	merged["value"] = data
+	return merged
+}
+
+// isStringKeyedMap reports whether data is a map with string keys.
+func isStringKeyedMap(data interface{}) bool {
+	t := reflect.TypeOf(data)
+	return t != nil && t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
@@ -0,0 +1,120 @@
+package raygun4go
+
+import "encoding/json"
+
+// The wire formats a report can be encoded in, see WireFormat.
+const (
+	// WireFormatV1 is the JSON sent by all earlier releases. It is the
+	// default.
+	WireFormatV1 = 1
+
+	// WireFormatV2 differs from WireFormatV1 in four ways only:
+	//
+	//   - occurredOn includes milliseconds, e.g. 2006-01-02T15:04:05.000Z
+	//   - empty fields are left out, including the whole request and user
+	//     sections if they are empty
+	//   - request.queryString holds arrays of all values of each parameter
+	//     instead of a single, possibly bracketed string
+	//   - request.cookies holds the cookies sent with the request, subject to
+	//     the same redaction as the Cookie header
+	WireFormatV2 = 2
+)
+
+// WireFormat is a chainable option-setting method to choose the shape of the
+// JSON sent to Raygun, see WireFormatV1 and WireFormatV2. Future changes to
+// the payload are only made in new versions, so consumers comparing payloads
+// against snapshots opt into them deliberately. Unknown versions are ignored.
+func (c *Client) WireFormat(version int) *Client {
+	if c == nil {
+		return nil
+	}
+	if version != WireFormatV1 && version != WireFormatV2 {
+		c.errorf("Ignoring unknown wire format %d", version)
+		return c
+	}
+	c.wireFormat = version
+	return c
+}
+
+// MarshalJSON encodes the post in its wire format.
+func (p PostData) MarshalJSON() ([]byte, error) {
+	if p.raw != nil {
+		return p.raw, nil
+	}
+	if p.wireFormat == WireFormatV2 {
+		return json.Marshal(newPostDataV2(p))
+	}
+	type post PostData
+	return json.Marshal(post(p))
+}
+
+// postDataV2 is the shape of PostData in wire format v2.
+type postDataV2 struct {
+	OccuredOn string        `json:"occurredOn"`
+	Details   detailsDataV2 `json:"details"`
+}
+
+// detailsDataV2 is the shape of DetailsData in wire format v2.
+type detailsDataV2 struct {
+	MachineName    string         `json:"machineName,omitempty"`
+	Version        string         `json:"version,omitempty"`
+	Error          ErrorData      `json:"error"`
+	Tags           []string       `json:"tags,omitempty"`
+	UserCustomData UserCustomData `json:"userCustomData,omitempty"`
+	Request        *requestDataV2 `json:"request,omitempty"`
+	User           *User          `json:"user,omitempty"`
+	Context        Context        `json:"context"`
+	Client         ClientData     `json:"client"`
+	GroupingKey    *string        `json:"groupingKey,omitempty"`
+	Breadcrumbs    []Breadcrumb   `json:"breadcrumbs,omitempty"`
+	Response       *ResponseData  `json:"response,omitempty"`
+}
+
+// requestDataV2 is the shape of RequestData in wire format v2.
+type requestDataV2 struct {
+	HostName    string              `json:"hostName,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	HTTPMethod  string              `json:"httpMethod,omitempty"`
+	IPAddress   string              `json:"ipAddress,omitempty"`
+	QueryString map[string][]string `json:"queryString,omitempty"`
+	Form        map[string]string   `json:"form,omitempty"`
+	Headers     map[string]string   `json:"headers,omitempty"`
+	Cookies     map[string]string   `json:"cookies,omitempty"`
+}
+
+// newPostDataV2 converts the given post to wire format v2.
+func newPostDataV2(p PostData) postDataV2 {
+	d := p.Details
+	v2 := postDataV2{
+		OccuredOn: p.OccuredOn,
+		Details: detailsDataV2{
+			MachineName:    d.MachineName,
+			Version:        d.Version,
+			Error:          d.Error,
+			Tags:           d.Tags,
+			UserCustomData: d.UserCustomData,
+			Context:        d.Context,
+			Client:         d.Client,
+			GroupingKey:    d.GroupingKey,
+			Breadcrumbs:    d.Breadcrumbs,
+			Response:       d.Response,
+		},
+	}
+	if !d.omitRequest && !d.Request.empty() {
+		r := d.Request
+		v2.Details.Request = &requestDataV2{
+			HostName:    r.HostName,
+			URL:         r.URL,
+			HTTPMethod:  r.HTTPMethod,
+			IPAddress:   r.IPAddress,
+			QueryString: r.queryValues,
+			Form:        r.Form,
+			Headers:     r.Headers,
+			Cookies:     r.cookies,
+		}
+	}
+	if d.User.Identifier != "" {
+		v2.Details.User = &d.User
+	}
+	return v2
+}
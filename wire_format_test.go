@@ -0,0 +1,94 @@
+package raygun4go
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWireFormat(t *testing.T) {
+	Convey("WireFormat", t, func() {
+		c, _ := New("app", "key")
+		c.clock = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC) }
+		c.occurrence.elapsed = func() time.Duration { return 0 }
+		c.newReportID = func() string { return "report-1" }
+
+		r := httptest.NewRequest("GET", "http://www.example.com/path?fizz=buzz&fizz=buzz2&foo=bar", nil)
+		r.Header.Set("Cookie", "session=abc; theme=dark")
+		c.Request(r)
+
+		encode := func(version int) map[string]interface{} {
+			c.WireFormat(version)
+			post := c.createPost(errors.New("Test WireFormat"), StackTrace{})
+			data, err := json.Marshal(post)
+			So(err, ShouldBeNil)
+
+			var decoded map[string]interface{}
+			json.Unmarshal(data, &decoded)
+			return decoded
+		}
+
+		Convey("defaults to v1", func() {
+			So(c.wireFormat, ShouldEqual, WireFormatV1)
+			c.WireFormat(3)
+			So(c.wireFormat, ShouldEqual, WireFormatV1)
+		})
+
+		Convey("v2 differs from v1 as documented only", func() {
+			v1 := encode(WireFormatV1)
+			v2 := encode(WireFormatV2)
+
+			So(v1["occurredOn"], ShouldEqual, "2024-01-02T03:04:05Z")
+			v1["occurredOn"] = "2024-01-02T03:04:05.678Z"
+
+			details := v1["details"].(map[string]interface{})
+			So(details["version"], ShouldEqual, "")
+			So(details["tags"], ShouldBeNil)
+			So(details["userCustomData"], ShouldResemble, map[string]interface{}{"reportId": "report-1"})
+			So(details["user"], ShouldResemble, map[string]interface{}{"identifier": ""})
+			So(details["groupingKey"], ShouldBeNil)
+			for _, key := range []string{"version", "tags", "user", "groupingKey"} {
+				delete(details, key)
+			}
+
+			request := details["request"].(map[string]interface{})
+			So(request["form"], ShouldResemble, map[string]interface{}{})
+			delete(request, "form")
+			So(request["queryString"], ShouldResemble, map[string]interface{}{"fizz": "[buzz; buzz2]", "foo": "bar"})
+			request["queryString"] = map[string]interface{}{
+				"fizz": []interface{}{"buzz", "buzz2"},
+				"foo":  []interface{}{"bar"},
+			}
+			request["cookies"] = map[string]interface{}{"session": "[REDACTED]", "theme": "[REDACTED]"}
+
+			So(v2, ShouldResemble, v1)
+		})
+
+		Convey("v2 redacts cookies by field name once the Cookie header is no longer redacted", func() {
+			c.ClearRedaction().RedactFields("session")
+			request := encode(WireFormatV2)["details"].(map[string]interface{})["request"].(map[string]interface{})
+			So(request["cookies"], ShouldResemble, map[string]interface{}{"session": "[REDACTED]", "theme": "dark"})
+		})
+
+		Convey("v2 leaves out an empty request section", func() {
+			c.Request(nil)
+			So(encode(WireFormatV2)["details"], ShouldNotContainKey, "request")
+		})
+
+		Convey("stored reports keep their wire format", func() {
+			c.WireFormat(WireFormatV2)
+			post := c.createPost(errors.New("Test WireFormat"), StackTrace{})
+			data, _ := encodeStoredReport(storedReport{Post: post})
+			stored, err := decodeStoredReport(data)
+			So(err, ShouldBeNil)
+
+			expected, _ := json.Marshal(post)
+			resumed, _ := json.Marshal(stored.Post)
+			So(string(resumed), ShouldEqual, string(expected))
+		})
+	})
+}